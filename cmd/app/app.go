@@ -85,7 +85,9 @@ func Run(ctx context.Context) {
 			switch signal {
 			case syscall.SIGHUP:
 				log.Info("Received 'SIGHUP' signal. Reloading configurations...")
-				App.Load()
+				if err := App.Reload(); err != nil {
+					log.Errorf("Keeping current configurations. Error: %v", err)
+				}
 
 			case syscall.SIGINT: // CTRL+C
 				log.Info("***** Received 'SIGINT' signal. Exiting... *****")
@@ -0,0 +1,31 @@
+// docker-credential-secretservice is a docker credential helper keeping
+// credentials of docker, podman and buildah in secret service. Enable
+// it by '"credsStore": "secretservice"' in '~/.docker/config.json'.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/dockercredential"
+)
+
+func main() {
+
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: docker-credential-secretservice store|get|erase|list|version")
+		os.Exit(1)
+	}
+
+	// errors go to stdout as docker expects
+	helper, err := dockercredential.NewHelper()
+
+	if err == nil {
+		err = dockercredential.Serve(helper, os.Args[1], os.Stdin, os.Stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stdout, err)
+		os.Exit(1)
+	}
+}
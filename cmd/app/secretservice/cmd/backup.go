@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/internal"
+	"github.com/yousefvand/secret-service/pkg/service"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCreateCmd.Flags().Bool("plain", false, "do not encrypt backup by master password (needs 'allowDbExport')")
+
+	backupCmd.AddCommand(backupListCmd)
+
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	backupCmd.AddCommand(backupPruneCmd)
+	backupPruneCmd.Flags().Int("keep-last", 0, "number of newest backups to keep (default 'backupKeepLast' config)")
+	backupPruneCmd.Flags().Int("keep-daily", 0, "number of days to keep the newest backup of (default 'backupKeepDaily' config)")
+	backupPruneCmd.Flags().Int("keep-weekly", 0, "number of weeks to keep the newest backup of (default 'backupKeepWeekly' config)")
+	backupPruneCmd.Flags().Bool("dry-run", false, "print backups to remove without removing them")
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create, list, restore and prune backups",
+	Long: `Backups are json files with a manifest (format version, creation time,
+number of collections and items and checksum) and all collections and items.
+Backups are encrypted by MASTERPASSWORD of secretserviced (--plain backups
+need 'allowDbExport' config) and written to 'backupDirectory' config
+(default ~/.secret-service/secretserviced/backups). secretserviced also takes
+scheduled backups every 'backupInterval' hours and prunes old ones.`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create [--plain]",
+	Short: "Create a backup of all collections and items",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		ssClient := newClient()
+
+		backupFile, err := ssClient.CreateBackup(plain)
+
+		if err != nil {
+			fail(exitCode(err), "cannot create backup: %v", err)
+		}
+
+		printStatus(fmt.Sprintf("backup created: %s", backupFile))
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		backups, err := service.ListBackups(backupDirectory())
+
+		if err != nil {
+			fail(exitIO, "%v", err)
+		}
+
+		outputs := []backupOutput{}
+
+		for _, backup := range backups {
+			outputs = append(outputs, newBackupOutput(backup))
+		}
+
+		printValue(outputs, func() {
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "FILE\tCREATED\tENCRYPTED\tCOLLECTIONS\tITEMS\tSIZE")
+			for _, b := range outputs {
+				fmt.Fprintf(writer, "%s\t%s\t%t\t%d\t%d\t%d\n", filepath.Base(b.File),
+					formatEpoch(b.Created), b.Encrypted, b.Collections, b.Items, b.Size)
+			}
+			writer.Flush()
+		})
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore file",
+	Short: "Replace all collections and items with a backup",
+	Long: `Validate a backup (file path or file name in backup directory) and replace
+all collections and items of running secretserviced with the ones in backup.
+Current collections and items are backed up first. Encrypted backups are
+decrypted by MASTERPASSWORD of secretserviced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		backupFile := args[0]
+
+		if exist, _ := fileOrFolderExists(backupFile); !exist && !strings.Contains(backupFile, "/") {
+			backupFile = filepath.Join(backupDirectory(), backupFile)
+		}
+
+		if exist, _ := fileOrFolderExists(backupFile); !exist {
+			fail(exitNotFound, "'%s' does not exist", backupFile)
+		}
+
+		// daemon may run in another directory
+		backupFile, _ = filepath.Abs(backupFile)
+
+		if _, err := service.ReadBackupManifest(backupFile); err != nil {
+			fail(exitUsage, "%v", err)
+		}
+
+		ssClient := newClient()
+
+		if err := ssClient.RestoreBackup(backupFile); err != nil {
+			fail(exitCode(err), "cannot restore backup: %v", err)
+		}
+
+		printStatus(fmt.Sprintf("backup '%s' is restored", backupFile))
+	},
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune [--keep-last N] [--keep-daily N] [--keep-weekly N] [--dry-run]",
+	Short: "Remove old backups",
+	Long: `Remove backups except the newest --keep-last backups and the newest backup
+of each of the latest --keep-daily days and --keep-weekly weeks. Missing flags
+default to 'backupKeepLast', 'backupKeepDaily' and 'backupKeepWeekly' config.
+At least the newest backup is kept.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		retention := backupRetention()
+
+		if cmd.Flags().Changed("keep-last") {
+			retention.KeepLast, _ = cmd.Flags().GetInt("keep-last")
+		}
+
+		if cmd.Flags().Changed("keep-daily") {
+			retention.KeepDaily, _ = cmd.Flags().GetInt("keep-daily")
+		}
+
+		if cmd.Flags().Changed("keep-weekly") {
+			retention.KeepWeekly, _ = cmd.Flags().GetInt("keep-weekly")
+		}
+
+		if retention.KeepLast < 1 || retention.KeepDaily < 0 || retention.KeepWeekly < 0 {
+			fail(exitUsage, "--keep-last must be at least 1, --keep-daily and --keep-weekly at least 0")
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		directory := backupDirectory()
+		var removed []string
+
+		if dryRun {
+
+			backups, err := service.ListBackups(directory)
+
+			if err != nil {
+				fail(exitIO, "%v", err)
+			}
+
+			for _, backup := range service.ExpiredBackups(backups, retention) {
+				removed = append(removed, backup.Path)
+			}
+
+		} else {
+
+			var err error
+			removed, err = service.PruneBackups(directory, retention)
+
+			if err != nil {
+				fail(exitIO, "%v", err)
+			}
+		}
+
+		action := "removed"
+
+		if dryRun {
+			action = "would be removed"
+		}
+
+		printStatus(fmt.Sprintf("%d backup(s) %s: %s", len(removed), action, strings.Join(removed, ", ")))
+	},
+}
+
+// backupConfig returns secretserviced config, default config if config file
+// is missing or malformed
+func backupConfig() *internal.Config {
+
+	config := internal.NewConfig()
+	data, err := ioutil.ReadFile(serviceConfigFile())
+
+	if err == nil {
+		yaml.Unmarshal(data, config)
+	}
+
+	return config
+}
+
+// backupDirectory returns backup directory of secretserviced
+func backupDirectory() string {
+
+	if directory := backupConfig().BackupDirectory; filepath.IsAbs(directory) {
+		return directory
+	}
+
+	home, err := serviceHome()
+
+	if err != nil {
+		fail(exitIO, "cannot find user home: %v", err)
+	}
+
+	return filepath.Join(home, "backups")
+}
+
+// backupRetention returns backup retention of secretserviced config
+func backupRetention() service.BackupRetention {
+
+	config := backupConfig()
+	retention := service.BackupRetention{
+		KeepLast:   config.BackupKeepLast,
+		KeepDaily:  config.BackupKeepDaily,
+		KeepWeekly: config.BackupKeepWeekly,
+	}
+
+	if retention.KeepLast < 1 {
+		retention.KeepLast = 1
+	}
+
+	if retention.KeepDaily < 0 {
+		retention.KeepDaily = 0
+	}
+
+	if retention.KeepWeekly < 0 {
+		retention.KeepWeekly = 0
+	}
+
+	return retention
+}
+
+// newBackupOutput converts a backup to its output schema
+func newBackupOutput(backup service.BackupFile) backupOutput {
+	return backupOutput{
+		File:           backup.Path,
+		Size:           backup.Size,
+		Version:        backup.Manifest.Version,
+		ServiceVersion: backup.Manifest.ServiceVersion,
+		Created:        backup.Manifest.Created,
+		Encrypted:      backup.Manifest.Encrypted,
+		Collections:    backup.Manifest.Collections,
+		Items:          backup.Manifest.Items,
+		Checksum:       backup.Manifest.Checksum,
+	}
+}
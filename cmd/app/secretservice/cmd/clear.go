@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(clearCmd)
+}
+
+var clearCmd = &cobra.Command{
+	Use:   "clear attribute value...",
+	Short: "Remove secrets",
+	Long: `Remove all unlocked items matching given lookup attributes. Example:
+
+  secretservice clear service github user joe`,
+	Args: attributeArgs,
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+		unlocked, locked := searchItems(ssClient, attributes(args))
+
+		if len(unlocked) == 0 {
+			if len(locked) > 0 {
+				fail(exitLocked, "matching items are locked")
+			}
+			fail(exitNotFound, "no matching item")
+		}
+
+		removed := []itemOutput{}
+
+		for _, itemPath := range unlocked {
+			item := loadItem(ssClient, itemPath)
+			if _, err := item.Delete(); err != nil {
+				fail(exitCode(err), "cannot remove '%s': %v", itemPath, err)
+			}
+			removed = append(removed, newItemOutput(item, nil))
+		}
+
+		// like 'secret-tool clear', text output is empty
+		printValue(removed, func() {})
+	},
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(collectionCmd)
+
+	collectionCmd.AddCommand(collectionListCmd)
+
+	collectionCmd.AddCommand(collectionCreateCmd)
+	collectionCreateCmd.Flags().StringP("alias", "a", "", "collection alias")
+
+	collectionCmd.AddCommand(collectionDeleteCmd)
+	collectionCmd.AddCommand(collectionRenameCmd)
+
+	collectionCmd.AddCommand(collectionAliasCmd)
+	collectionAliasCmd.Flags().Bool("remove", false, "remove given alias")
+
+	collectionCmd.AddCommand(collectionLockCmd)
+	collectionCmd.AddCommand(collectionUnlockCmd)
+}
+
+var collectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Manage collections",
+	Long: `Manage collections. Collections are addressed by alias (i.e. default)
+or by dbus object path (i.e. /org/freedesktop/secrets/collection/abc)`,
+}
+
+var collectionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List collections",
+	Long:  `List collections with their path, label, aliases, lock state, item count and times`,
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		ssClient := newClient()
+		collectionPaths, err := ssClient.PropertyGetCollections()
+
+		if err != nil {
+			fail(exitDbus, "cannot read collections: %v", err)
+		}
+
+		aliases, err := ssClient.ListAliases()
+
+		if err != nil {
+			fail(exitDbus, "cannot read aliases: %v", err)
+		}
+
+		sort.Strings(collectionPaths)
+		collections := []collectionOutput{}
+
+		for _, collectionPath := range collectionPaths {
+
+			collection, err := ssClient.LoadCollection(dbus.ObjectPath(collectionPath))
+
+			if err != nil {
+				fail(exitDbus, "cannot read collection: %v", err)
+			}
+
+			collections = append(collections, newCollectionOutput(collection, aliases))
+		}
+
+		printValue(collections, func() {
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "PATH\tLABEL\tALIASES\tLOCKED\tITEMS\tCREATED\tMODIFIED")
+			for _, c := range collections {
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%d\t%s\t%s\n", c.Path, c.Label,
+					strings.Join(c.Aliases, ","), c.Locked, c.Items,
+					formatEpoch(c.Created), formatEpoch(c.Modified))
+			}
+			writer.Flush()
+		})
+	},
+}
+
+var collectionCreateCmd = &cobra.Command{
+	Use:   "create LABEL",
+	Short: "Create a collection",
+	Long: `Create a collection with given label and optional alias.
+If a collection with the same alias exists, that collection is returned`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		alias, _ := cmd.Flags().GetString("alias")
+
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant(args[0]),
+		}
+
+		ssClient := newClient()
+		collection, _, err := ssClient.CreateCollection(properties, alias)
+
+		if err != nil {
+			fail(exitCode(err), "cannot create collection: %v", err)
+		}
+
+		aliases, err := ssClient.ListAliases()
+
+		if err != nil {
+			fail(exitDbus, "cannot read aliases: %v", err)
+		}
+
+		printValue(newCollectionOutput(collection, aliases), func() {
+			fmt.Println(collection.ObjectPath)
+		})
+	},
+}
+
+var collectionDeleteCmd = &cobra.Command{
+	Use:   "delete COLLECTION",
+	Short: "Delete a collection",
+	Long:  `Delete a collection and all its items. Default collection cannot be deleted`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		collection := resolveCollection(newClient(), args[0])
+
+		if _, err := collection.Delete(); err != nil {
+			fail(exitCode(err), "cannot delete collection '%s': %v", args[0], err)
+		}
+
+		printStatus("collection deleted: " + string(collection.ObjectPath))
+	},
+}
+
+var collectionRenameCmd = &cobra.Command{
+	Use:   "rename COLLECTION LABEL",
+	Short: "Change label of a collection",
+	Long:  `Change label of a collection. Collection path doesn't change`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+
+		collection := resolveCollection(newClient(), args[0])
+
+		if err := collection.PropertySetLabel(args[1]); err != nil {
+			fail(exitCode(err), "cannot rename collection '%s': %v", args[0], err)
+		}
+
+		printStatus("collection renamed: " + string(collection.ObjectPath))
+	},
+}
+
+var collectionAliasCmd = &cobra.Command{
+	Use:   "alias COLLECTION ALIAS | alias --remove ALIAS",
+	Short: "Set or remove an alias",
+	Long: `Set an alias for a collection (replacing its current alias) or remove an alias.
+Alias of default collection cannot be changed`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		remove, _ := cmd.Flags().GetBool("remove")
+		ssClient := newClient()
+
+		if remove {
+			if len(args) != 1 {
+				fail(exitUsage, "expected only the alias to remove")
+			}
+			resolveCollection(ssClient, args[0]) // make sure alias exists
+			if err := ssClient.SetAlias(args[0], "/"); err != nil {
+				fail(exitCode(err), "cannot remove alias '%s': %v", args[0], err)
+			}
+			printStatus("alias removed: " + args[0])
+			return
+		}
+
+		if len(args) != 2 {
+			fail(exitUsage, "expected collection and alias")
+		}
+
+		collection := resolveCollection(ssClient, args[0])
+
+		if err := ssClient.SetAlias(args[1], collection.ObjectPath); err != nil {
+			fail(exitCode(err), "cannot set alias '%s': %v", args[1], err)
+		}
+
+		printStatus("alias set: " + args[1])
+	},
+}
+
+var collectionLockCmd = &cobra.Command{
+	Use:   "lock COLLECTION...",
+	Short: "Lock collections",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+
+		if _, _, err := ssClient.Lock(resolveCollectionPaths(ssClient, args)); err != nil {
+			fail(exitCode(err), "cannot lock collections: %v", err)
+		}
+
+		printStatus("collections locked")
+	},
+}
+
+var collectionUnlockCmd = &cobra.Command{
+	Use:   "unlock COLLECTION...",
+	Short: "Unlock collections",
+	Long: `Unlock collections. Password protected collections are not
+unlocked and cause exit code 4 (locked)`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+		collectionPaths := resolveCollectionPaths(ssClient, args)
+		unlocked, _, err := ssClient.Unlock(collectionPaths)
+
+		if err != nil {
+			fail(exitCode(err), "cannot unlock collections: %v", err)
+		}
+
+		for _, collectionPath := range collectionPaths {
+			collection, err := ssClient.LoadCollection(collectionPath)
+			if err == nil && collection.Locked && !containsPath(unlocked, collectionPath) {
+				fail(exitLocked, "collection remains locked: %s", collectionPath)
+			}
+		}
+
+		printStatus("collections unlocked")
+	},
+}
+
+// newCollectionOutput converts a collection to its output schema
+func newCollectionOutput(collection *client.Collection,
+	aliases map[string]dbus.ObjectPath) collectionOutput {
+
+	items, err := collection.PropertyGetItems()
+
+	if err != nil {
+		fail(exitDbus, "cannot read items of '%s': %v", collection.ObjectPath, err)
+	}
+
+	return collectionOutput{
+		Path:     string(collection.ObjectPath),
+		Label:    collection.Label,
+		Aliases:  collectionAliases(aliases, collection),
+		Locked:   collection.Locked,
+		Items:    len(items),
+		Created:  collection.Created,
+		Modified: collection.Modified,
+	}
+}
+
+// resolveCollectionPaths returns paths of given collection aliases or paths or exits
+func resolveCollectionPaths(ssClient *client.Client, names []string) []dbus.ObjectPath {
+	var paths []dbus.ObjectPath
+	for _, name := range names {
+		paths = append(paths, resolveCollection(ssClient, name).ObjectPath)
+	}
+	return paths
+}
+
+// collectionAliases returns sorted aliases of given collection
+func collectionAliases(aliases map[string]dbus.ObjectPath, collection *client.Collection) []string {
+	result := []string{}
+	for alias, collectionPath := range aliases {
+		if collectionPath == collection.ObjectPath {
+			result = append(result, alias)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// containsPath returns true if paths contains given path
+func containsPath(paths []dbus.ObjectPath, path dbus.ObjectPath) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/internal"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd, configGetCmd, configSetCmd, configValidateCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show, change and validate secretserviced config",
+	Long: `Show, change and validate secretserviced config file
+(~/.secret-service/secretserviced/config.yaml). Changes are validated,
+written atomically and the running daemon reloads them.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print all settings",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		_, config := readConfig()
+
+		printValue(config, func() {
+			document, err := yaml.Marshal(config)
+			if err != nil {
+				fail(exitUsage, "cannot encode output: %v", err)
+			}
+			fmt.Print(string(document))
+		})
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get key",
+	Short: "Print a setting",
+	Long: `Print value of a setting.
+Example:
+
+  secretservice config get logLevel`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		_, config := readConfig()
+		value, err := config.Get(args[0])
+
+		if err != nil {
+			fail(exitNotFound, "%v", err)
+		}
+
+		printValue(map[string]interface{}{args[0]: value}, func() {
+			if _, ok := value.([]internal.SchemaConfig); ok {
+				document, _ := yaml.Marshal(value)
+				fmt.Print(string(document))
+				return
+			}
+			fmt.Println(value)
+		})
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set key value",
+	Short: "Change a setting",
+	Long: `Change a setting in config file and make secretserviced reload it.
+Value is checked against the type and allowed values of the setting.
+Comments and layout of config file are kept.
+Example:
+
+  secretservice config set logLevel 5`,
+	Args: cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+
+		configFile, _ := readConfig()
+		data, err := ioutil.ReadFile(configFile)
+
+		if err != nil {
+			fail(exitIO, "cannot read config file: %v", err)
+		}
+
+		changed, err := internal.SetConfigValue(data, args[0], args[1])
+
+		if err != nil {
+			fail(exitUsage, "%v", err)
+		}
+
+		if err := writePrivateFile(configFile, changed); err != nil {
+			fail(exitIO, "cannot write '%s': %v", configFile, err)
+		}
+
+		message := fmt.Sprintf("'%s' is set to '%s'", args[0], args[1])
+
+		switch reloadConfig() {
+		case "ok":
+			message += ", secretserviced reloaded config"
+		case "":
+			message += ", secretserviced is not running and uses it on next start"
+		default:
+			message += ", restart secretserviced to apply it"
+		}
+
+		// these settings are applied on start only
+		if args[0] == "portal" || args[0] == "kwallet" {
+			message += " (needs restart of secretserviced)"
+		}
+
+		printStatus(message)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a config file",
+	Long: `Check config file (or given file) for malformed yaml, unknown settings
+and wrong values. Exit code is 6 if there is a problem.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		var configFile string
+
+		if len(args) > 0 {
+			configFile = args[0]
+		} else {
+			configFile = serviceConfigFile()
+		}
+
+		data, err := ioutil.ReadFile(configFile)
+
+		if err != nil {
+			fail(exitIO, "cannot read config file: %v", err)
+		}
+
+		problems := internal.ValidateConfig(data)
+		output := configValidationOutput{
+			File:     configFile,
+			Valid:    len(problems) == 0,
+			Problems: problems,
+		}
+
+		if output.Problems == nil {
+			output.Problems = []string{}
+		}
+
+		printValue(output, func() {
+			if output.Valid {
+				fmt.Printf("%s is valid\n", configFile)
+				return
+			}
+			fmt.Printf("%s has %d problem(s):\n", configFile, len(problems))
+			for _, problem := range problems {
+				fmt.Println("  " + problem)
+			}
+		})
+
+		if !output.Valid {
+			os.Exit(exitUnhealthy)
+		}
+	},
+}
+
+// serviceHome returns secretserviced home directory
+func serviceHome() (string, error) {
+
+	userHome, err := os.UserHomeDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userHome, ".secret-service", "secretserviced"), nil
+}
+
+// serviceConfigFile returns path of secretserviced config file or exits
+func serviceConfigFile() string {
+
+	home, err := serviceHome()
+
+	if err != nil {
+		fail(exitIO, "cannot find user home: %v", err)
+	}
+
+	return filepath.Join(home, "config.yaml")
+}
+
+// readConfig returns path and settings of secretserviced config file or exits
+func readConfig() (string, *internal.Config) {
+
+	configFile := serviceConfigFile()
+	data, err := ioutil.ReadFile(configFile)
+
+	if os.IsNotExist(err) {
+		fail(exitNotFound, "'%s' does not exist, it is created on first start of secretserviced", configFile)
+	}
+
+	if err != nil {
+		fail(exitIO, "cannot read config file: %v", err)
+	}
+
+	config, err := internal.ParseConfig(data)
+
+	if err != nil {
+		fail(exitUsage, "'%s' is malformed: %v", configFile, err)
+	}
+
+	return configFile, config
+}
+
+// reloadConfig asks secretserviced to reload config file and returns
+// its response, empty if secretserviced is not running
+func reloadConfig() string {
+
+	ssClient, err := client.New()
+
+	if err != nil {
+		return ""
+	}
+
+	response, err := ssClient.SecretServiceCommand("reload config", "")
+
+	if err != nil {
+		if strings.Contains(err.Error(), "org.freedesktop.secrets") {
+			return ""
+		}
+		fail(exitDbus, "cannot reload secretserviced config: %v", err)
+	}
+
+	if response == "failed" {
+		fail(exitDbus, "secretserviced cannot reload config, check its log file")
+	}
+
+	return response
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// csvMapping says which csv columns are label, secret and lookup attributes
+type csvMapping struct {
+	// label column
+	Label string `yaml:"label"`
+	// secret column
+	Secret string `yaml:"secret"`
+	// lookup attribute name to column
+	Attributes map[string]string `yaml:"attributes"`
+	// TRUE if other columns are lookup attributes (import) and other lookup
+	// attributes are columns (export)
+	Extra bool `yaml:"extra"`
+	// all columns of exported csv in order (default label, secret and
+	// attribute columns)
+	Columns []string `yaml:"columns"`
+	// fixed values of exported columns
+	Values map[string]string `yaml:"values"`
+}
+
+// csvPresets are mappings of common browser and password manager layouts
+var csvPresets = map[string]csvMapping{
+	"generic": {
+		Label:   "label",
+		Secret:  "secret",
+		Extra:   true,
+		Columns: []string{"label", "secret"},
+	},
+	"chrome": {
+		Label:      "name",
+		Secret:     "password",
+		Attributes: map[string]string{"url": "url", "username": "username"},
+		Columns:    []string{"name", "url", "username", "password", "note"},
+	},
+	"firefox": {
+		Label:      "url",
+		Secret:     "password",
+		Attributes: map[string]string{"url": "url", "username": "username"},
+		Columns: []string{"url", "username", "password", "httpRealm", "formActionOrigin",
+			"guid", "timeCreated", "timeLastUsed", "timePasswordChanged"},
+	},
+	"bitwarden": {
+		Label:      "name",
+		Secret:     "login_password",
+		Attributes: map[string]string{"url": "login_uri", "username": "login_username"},
+		Columns: []string{"folder", "favorite", "type", "name", "notes", "fields",
+			"reprompt", "login_uri", "login_username", "login_password", "login_totp"},
+		Values: map[string]string{"type": "login", "reprompt": "0"},
+	},
+	"keepassxc": {
+		Label:      "Title",
+		Secret:     "Password",
+		Attributes: map[string]string{"url": "URL", "username": "Username"},
+		Columns: []string{"Group", "Title", "Username", "Password", "URL", "Notes",
+			"TOTP", "Icon", "Last Modified", "Created"},
+	},
+	"lastpass": {
+		Label:      "name",
+		Secret:     "password",
+		Attributes: map[string]string{"url": "url", "username": "username"},
+		Columns:    []string{"url", "username", "password", "totp", "extra", "name", "grouping", "fav"},
+	},
+	"1password": {
+		Label:      "Title",
+		Secret:     "Password",
+		Attributes: map[string]string{"url": "Url", "username": "Username"},
+		Columns:    []string{"Title", "Url", "Username", "Password", "OTPAuth", "Favorite", "Archived", "Tags", "Notes"},
+	},
+	"safari": {
+		Label:      "Title",
+		Secret:     "Password",
+		Attributes: map[string]string{"url": "URL", "username": "Username"},
+		Columns:    []string{"Title", "URL", "Username", "Password", "Notes", "OTPAuth"},
+	},
+}
+
+// csvPresetNames returns sorted names of csv presets
+func csvPresetNames() []string {
+	names := make([]string, 0, len(csvPresets))
+	for name := range csvPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addCsvMappingFlags adds flags of csv mapping to cmd
+func addCsvMappingFlags(cmd *cobra.Command) {
+	cmd.Flags().String("preset", "", "column layout: "+strings.Join(csvPresetNames(), ", ")+" (default generic)")
+	cmd.Flags().String("mapping", "", "yaml mapping file (label, secret, attributes, extra, columns, values)")
+	cmd.Flags().String("label", "", "label column (overrides preset or mapping)")
+	cmd.Flags().String("secret", "", "secret column (overrides preset or mapping)")
+	cmd.Flags().StringArray("attribute", nil, "lookup attribute column as name=column (repeatable)")
+}
+
+// csvMappingFlags returns csv mapping of cmd flags or exits
+func csvMappingFlags(cmd *cobra.Command) csvMapping {
+
+	preset, _ := cmd.Flags().GetString("preset")
+	mappingFile, _ := cmd.Flags().GetString("mapping")
+	label, _ := cmd.Flags().GetString("label")
+	secret, _ := cmd.Flags().GetString("secret")
+	attributeFlags, _ := cmd.Flags().GetStringArray("attribute")
+
+	var mapping csvMapping
+
+	switch {
+
+	case preset != "" && mappingFile != "":
+		fail(exitUsage, "--preset and --mapping cannot be used together")
+
+	case mappingFile != "":
+		data, err := ioutil.ReadFile(mappingFile)
+		if err != nil {
+			fail(exitNotFound, "cannot read mapping file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &mapping); err != nil {
+			fail(exitUsage, "'%s' is malformed: %v", mappingFile, err)
+		}
+
+	default:
+		if preset == "" {
+			preset = "generic"
+		}
+		presetMapping, ok := csvPresets[preset]
+		if !ok {
+			fail(exitUsage, "unknown preset '%s', valid presets: %s", preset,
+				strings.Join(csvPresetNames(), ", "))
+		}
+		mapping = presetMapping
+	}
+
+	// presets are shared, flags must not change them
+	attributes := make(map[string]string)
+	for name, column := range mapping.Attributes {
+		attributes[name] = column
+	}
+	mapping.Attributes = attributes
+
+	if label != "" {
+		mapping.Label = label
+	}
+
+	if secret != "" {
+		mapping.Secret = secret
+	}
+
+	for _, attributeFlag := range attributeFlags {
+		parts := strings.SplitN(attributeFlag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			fail(exitUsage, "--attribute must be name=column, got '%s'", attributeFlag)
+		}
+		mapping.Attributes[parts[0]] = parts[1]
+	}
+
+	if mapping.Label == "" || mapping.Secret == "" {
+		fail(exitUsage, "label and secret columns are required")
+	}
+
+	return mapping
+}
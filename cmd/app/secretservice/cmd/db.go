@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.PersistentFlags().StringP("file", "f", "", "database file (default ~/.secret-service/secretserviced/db.json)")
+
+	dbCmd.AddCommand(dbListCmd)
+	dbListCmd.Flags().Bool("collections", false, "list collections instead of items")
+
+	dbCmd.AddCommand(dbShowCmd)
+
+	dbCmd.AddCommand(dbEditCmd)
+	dbEditCmd.Flags().StringP("label", "l", "", "new label")
+	dbEditCmd.Flags().StringArray("set", nil, "set lookup attribute (attribute=value)")
+	dbEditCmd.Flags().StringArray("unset", nil, "remove lookup attribute")
+	dbEditCmd.Flags().Bool("secret", false, "read new secret from standard input")
+
+	dbCmd.AddCommand(dbDeleteCmd)
+
+	dbCmd.AddCommand(dbReencryptCmd)
+	dbReencryptCmd.Flags().Bool("plain", false, "store secrets without encryption")
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Edit database offline (without secretserviced)",
+	Long: `List, inspect, edit, delete and re-encrypt entries of secretserviced database
+directly, i.e. when secretserviced does not start. secretserviced must be stopped,
+database is locked while these commands run. Master password of an encrypted
+database is read from MASTERPASSWORD environment variable or asked on terminal.
+Items and collections are addressed by their object path (see 'db list').`,
+}
+
+var dbListCmd = &cobra.Command{
+	Use:   "list [attribute value...]",
+	Short: "List items (or collections) of database",
+	Long: `List items of database matching given lookup attributes (all items if
+none is given) without their secrets, or collections with --collections.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return nil
+		}
+		return attributeArgs(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+
+		listCollections, _ := cmd.Flags().GetBool("collections")
+		database := openDatabase(cmd)
+		lookupAttributes := attributes(args)
+
+		if listCollections {
+
+			collections := []collectionOutput{}
+
+			for _, collection := range database.db.Collections {
+				collections = append(collections, newDbCollectionOutput(collection))
+			}
+
+			printValue(collections, func() {
+				writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(writer, "PATH\tLABEL\tALIASES\tLOCKED\tITEMS\tCREATED\tMODIFIED")
+				for _, c := range collections {
+					fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%d\t%s\t%s\n", c.Path, c.Label,
+						strings.Join(c.Aliases, ","), c.Locked, c.Items,
+						formatEpoch(c.Created), formatEpoch(c.Modified))
+				}
+				writer.Flush()
+			})
+			return
+		}
+
+		items := []itemOutput{}
+
+		for _, collection := range database.db.Collections {
+			for _, item := range collection.Items {
+				if hasAttributes(item.LookupAttributes, lookupAttributes) {
+					items = append(items, newDbItemOutput(item, nil))
+				}
+			}
+		}
+
+		printItems(items)
+	},
+}
+
+var dbShowCmd = &cobra.Command{
+	Use:   "show item",
+	Short: "Print an item with its secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		database := openDatabase(cmd)
+		collection, index := database.item(args[0])
+		item := database.db.Collections[collection].Items[index]
+
+		printItems([]itemOutput{newDbItemOutput(item, &item.Secret.SecretText)})
+	},
+}
+
+var dbEditCmd = &cobra.Command{
+	Use:   "edit item [--label L] [--set attribute=value]... [--unset attribute]... [--secret]",
+	Short: "Change label, lookup attributes or secret of an item",
+	Long: `Change label, lookup attributes or secret (read from standard input with
+--secret) of an item. Example:
+
+  secretservice db edit /org/freedesktop/secrets/aliases/default/abc --set user=joe --unset old
+  echo -n 'P@ssw0rd' | secretservice db edit /org/freedesktop/secrets/aliases/default/abc --secret`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setAttributes, _ := cmd.Flags().GetStringArray("set")
+		unsetAttributes, _ := cmd.Flags().GetStringArray("unset")
+		changeSecret, _ := cmd.Flags().GetBool("secret")
+
+		if !cmd.Flags().Changed("label") && len(setAttributes) == 0 &&
+			len(unsetAttributes) == 0 && !changeSecret {
+			fail(exitUsage, "nothing to change, use --label, --set, --unset or --secret")
+		}
+
+		database := openDatabase(cmd)
+		collection, index := database.item(args[0])
+		item := &database.db.Collections[collection].Items[index]
+
+		if cmd.Flags().Changed("label") {
+			label, _ := cmd.Flags().GetString("label")
+			item.Label = label
+			if item.Properties == nil {
+				item.Properties = make(map[string]string)
+			}
+			item.Properties["Label"] = label
+		}
+
+		if item.LookupAttributes == nil {
+			item.LookupAttributes = make(map[string]string)
+		}
+
+		for _, attribute := range unsetAttributes {
+			delete(item.LookupAttributes, attribute)
+		}
+
+		for _, attribute := range setAttributes {
+			separator := strings.Index(attribute, "=")
+			if separator < 1 {
+				fail(exitUsage, "expected attribute=value, got '%s'", attribute)
+			}
+			item.LookupAttributes[attribute[:separator]] = attribute[separator+1:]
+		}
+
+		if changeSecret {
+			item.Secret.SecretText = readStdin("secret: ")
+		}
+
+		item.Modified = uint64(time.Now().Unix())
+		database.save()
+
+		printStatus(fmt.Sprintf("item '%s' is changed", item.ObjectPath))
+	},
+}
+
+var dbDeleteCmd = &cobra.Command{
+	Use:   "delete item|collection",
+	Short: "Delete an item or a collection",
+	Long: `Delete an item or a collection (with its items) by object path.
+Default collection cannot be deleted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		database := openDatabase(cmd)
+		collections := database.db.Collections
+
+		for i, collection := range collections {
+
+			if string(collection.ObjectPath) != args[0] {
+				continue
+			}
+
+			if collection.Alias == "default" {
+				fail(exitUsage, "default collection cannot be deleted")
+			}
+
+			database.db.Collections = append(collections[:i:i], collections[i+1:]...)
+			database.save()
+
+			printStatus(fmt.Sprintf("collection '%s' and its %d item(s) are deleted",
+				args[0], len(collection.Items)))
+			return
+		}
+
+		collection, index := database.item(args[0])
+		items := collections[collection].Items
+		collections[collection].Items = append(items[:index:index], items[index+1:]...)
+		database.save()
+
+		printStatus(fmt.Sprintf("item '%s' is deleted", args[0]))
+	},
+}
+
+var dbReencryptCmd = &cobra.Command{
+	Use:   "reencrypt [--plain]",
+	Short: "Encrypt secrets with a new master password",
+	Long: `Encrypt all secrets of database with a new 32 character master password
+read from standard input (or remove encryption with --plain). Then set the
+same MASTERPASSWORD (and 'encryptDatabase' config) for secretserviced.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		database := openDatabase(cmd)
+
+		if plain {
+			database.password = ""
+			database.save()
+			printStatus("database secrets are not encrypted anymore, set 'encryptDatabase: false' for secretserviced")
+			return
+		}
+
+		password := readStdin("new master password: ")
+
+		if len(password) != 32 {
+			fail(exitUsage, "master password should be exactly 32 characters, got %d", len(password))
+		}
+
+		database.password = password
+		database.save()
+
+		printStatus("database is encrypted with new master password, set it as MASTERPASSWORD of secretserviced")
+	},
+}
+
+// offlineDatabase is a locked database file with decrypted secrets
+type offlineDatabase struct {
+	// database file
+	file string
+	// database lock, held until exit
+	lock *os.File
+	// database with decrypted secrets
+	db *service.Database
+	// master password to encrypt secrets on save, empty means no encryption
+	password string
+}
+
+// openDatabase locks and reads database of '--file' flag and decrypts its
+// secrets or exits
+func openDatabase(cmd *cobra.Command) *offlineDatabase {
+
+	file, _ := cmd.Flags().GetString("file")
+
+	if file == "" {
+		home, err := serviceHome()
+		if err != nil {
+			fail(exitIO, "cannot find user home: %v", err)
+		}
+		file = filepath.Join(home, "db.json")
+	}
+
+	lock, err := service.LockDatabase(filepath.Dir(file))
+
+	if err == service.ErrDatabaseInUse {
+		fail(exitLocked, "database is in use by secretserviced, stop it first"+
+			" (i.e. systemctl --user stop secretserviced)")
+	}
+
+	if err != nil {
+		fail(exitIO, "%v", err)
+	}
+
+	if exist, _ := fileOrFolderExists(file); !exist {
+		fail(exitNotFound, "'%s' does not exist", file)
+	}
+
+	db, err := service.ReadDatabase(file)
+
+	if err != nil {
+		fail(exitIO, "%v", err)
+	}
+
+	database := &offlineDatabase{file: file, lock: lock, db: db}
+
+	if db.Encrypted {
+
+		database.password = os.Getenv("MASTERPASSWORD")
+
+		if database.password == "" {
+			database.password = readStdin("master password: ")
+		}
+
+		if err := db.DecryptSecrets(database.password); err != nil {
+			fail(exitUsage, "cannot decrypt database (wrong master password?): %v", err)
+		}
+	}
+
+	return database
+}
+
+// item returns indexes of collection and item at given path or exits
+func (database *offlineDatabase) item(itemPath string) (int, int) {
+
+	for i, collection := range database.db.Collections {
+		for j, item := range collection.Items {
+			if string(item.ObjectPath) == itemPath {
+				return i, j
+			}
+		}
+	}
+
+	fail(exitNotFound, "no such item: %s", itemPath)
+	return 0, 0
+}
+
+// save encrypts secrets (if there is a password) and writes database or exits
+func (database *offlineDatabase) save() {
+
+	if database.password != "" {
+		if err := database.db.EncryptSecrets(database.password); err != nil {
+			fail(exitUsage, "cannot encrypt database: %v", err)
+		}
+	}
+
+	if err := service.WriteDatabase(database.db, database.file); err != nil {
+		fail(exitIO, "%v", err)
+	}
+}
+
+// newDbItemOutput returns output schema of a database item
+func newDbItemOutput(item service.DbItem, secret *string) itemOutput {
+
+	attributes := map[string]string{}
+	for k, v := range item.LookupAttributes {
+		attributes[k] = v
+	}
+
+	return itemOutput{
+		Path:       string(item.ObjectPath),
+		Collection: string(item.Parent),
+		Label:      dbLabel(item.Properties, item.Label),
+		Type:       item.Type,
+		Locked:     item.Locked,
+		Created:    item.Created,
+		Modified:   item.Modified,
+		Attributes: attributes,
+		Secret:     secret,
+	}
+}
+
+// newDbCollectionOutput returns output schema of a database collection
+func newDbCollectionOutput(collection service.DbCollection) collectionOutput {
+
+	aliases := []string{}
+
+	if collection.Alias != "" {
+		aliases = append(aliases, collection.Alias)
+	}
+
+	return collectionOutput{
+		Path:     string(collection.ObjectPath),
+		Label:    dbLabel(collection.Properties, collection.Label),
+		Aliases:  aliases,
+		Locked:   collection.Locked,
+		Items:    len(collection.Items),
+		Created:  collection.Created,
+		Modified: collection.Modified,
+	}
+}
+
+// hasAttributes returns true if lookup attributes include all given attributes
+func hasAttributes(lookupAttributes map[string]string, attributes map[string]string) bool {
+	for k, v := range attributes {
+		if value, ok := lookupAttributes[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// dbLabel returns label of a database entry, secretserviced keeps it
+// in 'Label' property
+func dbLabel(properties map[string]string, label string) string {
+	if value := properties["Label"]; value != "" {
+		return value
+	}
+	return label
+}
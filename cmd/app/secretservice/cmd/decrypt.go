@@ -1,13 +1,10 @@
 package cmd
 
 import (
-	"bufio"
-	"io/ioutil"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
 )
 
 func init() {
@@ -15,7 +12,7 @@ func init() {
 	decryptCmd.Flags().StringP("password", "p", "", "database password")
 
 	decryptCmd.Flags().StringP("input", "i", "", "input file")
-	decryptCmd.Flags().StringP("output", "o", "", "output file")
+	decryptCmd.Flags().StringP("out", "o", "", "output file")
 }
 
 var decryptCmd = &cobra.Command{
@@ -26,57 +23,36 @@ var decryptCmd = &cobra.Command{
 
 		password, _ := cmd.Flags().GetString("password")
 		input, _ := cmd.Flags().GetString("input")
-		output, _ := cmd.Flags().GetString("output")
+		output, _ := cmd.Flags().GetString("out")
 
 		if len(password) != 32 {
-			panic("Wrong password length. Password should be exactly 32 characters.")
+			fail(exitUsage, "Wrong password length. Password should be exactly 32 characters.")
 		}
 
 		if exist, _ := fileOrFolderExists(input); !exist {
-			panic("Input file doesn't exist")
+			fail(exitNotFound, "Input file doesn't exist: %s", input)
 		}
 
-		file, err := os.Open(input)
+		db, err := service.ReadDatabase(input)
 
 		if err != nil {
-			panic("failed to open input file")
+			fail(exitUsage, "%v", err)
 		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		scanner.Split(bufio.ScanLines)
-		var i int = 0
-		var signature bool = false
-		var decrypted []string
-
-		for scanner.Scan() {
-			i++
-			line := scanner.Text()
-			if line == " \"encrypted\": true," {
-				signature = true
-			}
-			if i > 2 && !signature {
-				panic("Wrong type of file. This file is not marked as \"encrypted\"!")
-			}
-			if index := strings.Index(line, "secretText"); index > 0 {
-				cipher := line[21 : len(line)-1]
-				decrypt, err := crypto.DecryptAESCBC256(password, cipher)
-				if err != nil {
-					panic("Decryption failed: " + err.Error())
-				}
-				newLine := line[:21] + decrypt + "\""
-				decrypted = append(decrypted, newLine)
-			} else {
-				decrypted = append(decrypted, line)
-			}
+
+		if !db.Encrypted {
+			fail(exitUsage, "Wrong type of file. This file is not marked as \"encrypted\"!")
 		}
 
-		fileContent := strings.Join(decrypted, "\n")
-		err = ioutil.WriteFile(output, []byte(fileContent), 0755)
-		if err != nil {
-			panic("Writing to output file failed: " + output)
+		if err := db.DecryptSecrets(password); err != nil {
+			fail(exitIO, "Decryption failed: %v", err)
 		}
 
+		if err := service.WriteDatabase(db, output); err != nil {
+			fail(exitIO, "Writing to output file failed: %v", err)
+		}
+
+		printStatus("database decrypted: " + output)
+
 	},
 }
 
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/dockercredential"
+)
+
+func init() {
+	rootCmd.AddCommand(dockerCredentialCmd)
+}
+
+var dockerCredentialCmd = &cobra.Command{
+	Use:   "docker-credential store|get|erase|list|version",
+	Short: "Docker credential helper",
+	Long: `Docker credential helper (JSON protocol of docker, podman and buildah).
+Credentials are kept in a dedicated collection (alias 'docker') with 'server'
+and 'username' lookup attributes. Like other credential helpers, errors are
+printed to standard output with exit code 1. Docker needs a binary in PATH,
+install 'docker-credential-secretservice' and set in ~/.docker/config.json:
+
+  { "credsStore": "secretservice" }`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+		helper := &dockercredential.Helper{
+			Client:  ssClient,
+			Session: openSession(ssClient),
+		}
+
+		if err := dockercredential.Serve(helper, args[0], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stdout, err)
+			os.Exit(1)
+		}
+	},
+}
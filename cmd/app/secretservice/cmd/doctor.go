@@ -0,0 +1,591 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/internal"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose secretserviced setup problems",
+	Long: `Check session bus address, owner of 'org.freedesktop.secrets' name and its
+process, secretserviced reachability, home directory permissions, config file,
+MASTERPASSWORD, database readability and decryption, log file and systemd unit
+and print a fix for each problem. Exit code is 6 if any check fails.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		d := &doctor{}
+
+		d.checkBusAddress()
+		connection := d.checkBusConnection()
+		pid, executable := d.checkNameOwner(connection)
+		status := d.checkDaemon(connection, pid, executable)
+
+		home := d.checkHome()
+		config := d.checkConfig(home)
+		masterPassword := d.checkMasterPassword(status, config)
+		d.checkDatabase(home, status, masterPassword)
+		d.checkLogFile(home, config)
+		d.checkSystemdUnit()
+
+		if connection != nil {
+			connection.Close()
+		}
+
+		printValue(d.checks, func() {
+			for _, check := range d.checks {
+				fmt.Printf("%-7s %s: %s\n", "["+check.Status+"]", check.Name, check.Message)
+				if check.Fix != "" {
+					fmt.Printf("%-7s fix: %s\n", "", check.Fix)
+				}
+			}
+		})
+
+		for _, check := range d.checks {
+			if check.Status == checkError {
+				os.Exit(exitUnhealthy)
+			}
+		}
+	},
+}
+
+// check results
+const (
+	checkOK      = "ok"
+	checkWarning = "warn"
+	checkError   = "error"
+	checkSkipped = "skip"
+)
+
+// doctor collects results of diagnostic checks
+type doctor struct {
+	checks []checkOutput
+}
+
+// report adds result of a check
+func (d *doctor) report(name string, status string, message string, fix string) {
+	d.checks = append(d.checks, checkOutput{
+		Name:    name,
+		Status:  status,
+		Message: message,
+		Fix:     fix,
+	})
+}
+
+// checkBusAddress checks session bus address points to an existing socket
+func (d *doctor) checkBusAddress() {
+
+	const name = "session bus address"
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+
+	if address == "" {
+		d.report(name, checkWarning, "DBUS_SESSION_BUS_ADDRESS is not set, dbus falls back to autolaunch",
+			"run inside a desktop session or export DBUS_SESSION_BUS_ADDRESS=unix:path=$XDG_RUNTIME_DIR/bus")
+		return
+	}
+
+	for _, option := range strings.Split(strings.SplitN(address, ";", 2)[0], ",") {
+
+		socket := strings.TrimPrefix(option, "unix:")
+		if !strings.HasPrefix(socket, "path=") {
+			continue
+		}
+		socket = strings.TrimPrefix(socket, "path=")
+
+		if info, err := os.Stat(socket); err != nil || info.Mode()&os.ModeSocket == 0 {
+			d.report(name, checkError, fmt.Sprintf("%s: socket '%s' does not exist", address, socket),
+				"start a dbus session (i.e. 'dbus-launch') and export its address")
+			return
+		}
+	}
+
+	d.report(name, checkOK, address, "")
+}
+
+// checkBusConnection connects to session bus, returns nil on failure
+func (d *doctor) checkBusConnection() *dbus.Conn {
+
+	const name = "session bus"
+	connection, err := dbus.ConnectSessionBus()
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("cannot connect: %v", err),
+			"make sure dbus-daemon (or dbus-broker) session bus is running for this user")
+		return nil
+	}
+
+	d.report(name, checkOK, "connected", "")
+	return connection
+}
+
+// checkNameOwner checks 'org.freedesktop.secrets' is owned and returns
+// pid (0 if not known) and executable of its owner
+func (d *doctor) checkNameOwner(connection *dbus.Conn) (uint32, string) {
+
+	const name = "name owner"
+
+	if connection == nil {
+		d.report(name, checkSkipped, "no session bus", "")
+		return 0, ""
+	}
+
+	var owner string
+	err := connection.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0,
+		"org.freedesktop.secrets").Store(&owner)
+
+	if err != nil {
+		d.report(name, checkError, "'org.freedesktop.secrets' has no owner, secretserviced is not running",
+			"start it with 'systemctl --user start secretserviced' or run 'secretserviced'")
+		return 0, ""
+	}
+
+	var pid uint32
+	err = connection.BusObject().Call("org.freedesktop.DBus.GetConnectionUnixProcessID", 0,
+		owner).Store(&pid)
+
+	if err != nil {
+		d.report(name, checkOK, fmt.Sprintf("owned by %s (unknown process)", owner), "")
+		return 0, "unknown executable"
+	}
+
+	executable, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+
+	if err != nil {
+		executable = "unknown executable"
+	}
+
+	d.report(name, checkOK, fmt.Sprintf("owned by %s (pid %d, %s)", owner, pid, executable), "")
+	return pid, executable
+}
+
+// checkDaemon checks owner of 'org.freedesktop.secrets' is a responsive
+// secretserviced and returns its status (nil if it is not or status is not
+// supported)
+func (d *doctor) checkDaemon(connection *dbus.Conn, pid uint32, executable string) *client.ServiceStatus {
+
+	const name = "secretserviced"
+
+	if executable == "" {
+		d.report(name, checkSkipped, "owner of 'org.freedesktop.secrets' is not known", "")
+		return nil
+	}
+
+	var response string
+	err := connection.Object("org.freedesktop.secrets", "/secretservice").Call(
+		"ir.remisa.SecretService.Command", 0, "ping", "").Store(&response)
+
+	// other secret services don't implement 'ir.remisa.SecretService'
+	if dbusError, ok := err.(dbus.Error); ok &&
+		strings.HasPrefix(dbusError.Name, "org.freedesktop.DBus.Error.Unknown") {
+		d.report(name, checkError, fmt.Sprintf("'org.freedesktop.secrets' is owned by '%s' (pid %d), "+
+			"secretserviced cannot acquire the name and exits with code 5", executable, pid),
+			fmt.Sprintf("stop and disable '%s' (i.e. gnome-keyring-daemon secrets component or kwalletd)",
+				filepath.Base(executable)))
+		return nil
+	}
+
+	if err == nil && response != "pong" {
+		err = fmt.Errorf("unexpected ping response '%s'", response)
+	}
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("not responsive: %v", err),
+			"restart it with 'systemctl --user restart secretserviced' and check its log file")
+		return nil
+	}
+
+	var status *client.ServiceStatus
+	ssClient, err := client.New()
+
+	if err == nil {
+		status, err = ssClient.Status()
+	}
+
+	if err != nil {
+		d.report(name, checkOK, "responsive (status is not supported by this version)", "")
+		return nil
+	}
+
+	if status.LastSaveError != "" {
+		d.report(name, checkError, "last database save failed: "+status.LastSaveError,
+			"check MASTERPASSWORD and database permissions, then restart secretserviced")
+		return status
+	}
+
+	d.report(name, checkOK, fmt.Sprintf("version %s, %d collections, %d items",
+		status.Version, status.Collections, status.Items), "")
+	return status
+}
+
+// checkHome checks secretserviced home directory is private and returns its path
+func (d *doctor) checkHome() string {
+
+	const name = "home directory"
+	home, err := serviceHome()
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("cannot find user home: %v", err), "set HOME environment variable")
+		return ""
+	}
+
+	for _, directory := range []string{filepath.Dir(home), home} {
+
+		info, err := os.Stat(directory)
+
+		if os.IsNotExist(err) {
+			d.report(name, checkWarning, fmt.Sprintf("'%s' does not exist", directory),
+				"it is created on first start of secretserviced")
+			return home
+		}
+
+		if err != nil {
+			d.report(name, checkError, fmt.Sprintf("cannot access '%s': %v", directory, err),
+				"fix permissions of '"+directory+"'")
+			return home
+		}
+
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+			d.report(name, checkError, fmt.Sprintf("'%s' is owned by uid %d", directory, stat.Uid),
+				fmt.Sprintf("chown -R %d '%s'", os.Getuid(), directory))
+			return home
+		}
+
+		if info.Mode().Perm()&0022 != 0 {
+			d.report(name, checkError, fmt.Sprintf("'%s' is writable by others (%s)", directory, info.Mode().Perm()),
+				"chmod 700 '"+directory+"'")
+			return home
+		}
+	}
+
+	d.report(name, checkOK, home, "")
+	return home
+}
+
+// checkConfig checks config file and returns it (nil if missing or malformed)
+func (d *doctor) checkConfig(home string) *internal.Config {
+
+	const name = "config"
+
+	if home == "" {
+		d.report(name, checkSkipped, "no home directory", "")
+		return nil
+	}
+
+	configFile := filepath.Join(home, "config.yaml")
+	data, err := ioutil.ReadFile(configFile)
+
+	if os.IsNotExist(err) {
+		d.report(name, checkWarning, fmt.Sprintf("'%s' does not exist", configFile),
+			"default config is created on start of secretserviced")
+		return nil
+	}
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("cannot read '%s': %v", configFile, err),
+			"chmod 600 '"+configFile+"'")
+		return nil
+	}
+
+	config := internal.NewConfig()
+
+	if problems := internal.ValidateConfig(data); len(problems) > 0 {
+		d.report(name, checkWarning, configFile+": "+strings.Join(problems, "; "),
+			"fix them with 'secretservice config set' or edit '"+configFile+"'")
+		if yaml.Unmarshal(data, config) != nil {
+			return nil
+		}
+		return config
+	}
+
+	yaml.Unmarshal(data, config)
+	d.report(name, checkOK, configFile, "")
+	return config
+}
+
+// checkMasterPassword checks MASTERPASSWORD of secretserviced as reported
+// by its status (or of this shell if daemon is not running) and returns
+// MASTERPASSWORD of this shell if it has a valid length
+func (d *doctor) checkMasterPassword(status *client.ServiceStatus, config *internal.Config) string {
+
+	const name = "master password"
+
+	masterPassword := os.Getenv("MASTERPASSWORD")
+	encryption := config == nil || config.Encryption
+
+	if status != nil {
+		switch status.MasterPassword {
+		case "missing":
+			if encryption {
+				d.report(name, checkError, "MASTERPASSWORD is not set for secretserviced"+
+					" but database encryption is enabled, database cannot be saved",
+					"set a 32 character MASTERPASSWORD in secretserviced.service (Environment=)")
+			} else {
+				d.report(name, checkOK, "not set (database encryption is disabled)", "")
+			}
+		case "invalid":
+			d.report(name, checkError, "MASTERPASSWORD of secretserviced is not 32 characters",
+				"set a 32 character MASTERPASSWORD in secretserviced.service (Environment=)")
+		default:
+			d.report(name, checkOK, "32 characters (reported by secretserviced)", "")
+		}
+		return ""
+	}
+
+	source := "this shell"
+
+	switch {
+	case masterPassword == "":
+		if encryption {
+			d.report(name, checkError, "MASTERPASSWORD is not set in "+source+
+				" but database encryption is enabled, database cannot be saved",
+				"set a 32 character MASTERPASSWORD in secretserviced.service (Environment=)")
+			return ""
+		}
+		d.report(name, checkOK, "not set (database encryption is disabled)", "")
+		return ""
+	case len(masterPassword) != 32:
+		d.report(name, checkError, fmt.Sprintf("MASTERPASSWORD in %s has %d characters, expected 32. "+
+			"secretserviced panics loading an encrypted database", source, len(masterPassword)),
+			"set a 32 character MASTERPASSWORD in secretserviced.service (Environment=)")
+		return ""
+	}
+
+	d.report(name, checkOK, "32 characters (from "+source+")", "")
+	return masterPassword
+}
+
+// checkDatabase checks database can be read and decrypted, decryption is
+// checked by secretserviced if its status is given
+func (d *doctor) checkDatabase(home string, status *client.ServiceStatus, masterPassword string) {
+
+	const name = "database"
+
+	if home == "" {
+		d.report(name, checkSkipped, "no home directory", "")
+		return
+	}
+
+	dbFile := filepath.Join(home, "db.json")
+	info, err := os.Stat(dbFile)
+
+	if os.IsNotExist(err) {
+		d.report(name, checkOK, fmt.Sprintf("'%s' does not exist yet", dbFile), "")
+		return
+	}
+
+	data, err := ioutil.ReadFile(dbFile)
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("cannot read '%s': %v", dbFile, err),
+			"chmod 600 '"+dbFile+"'")
+		return
+	}
+
+	var db service.Database
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		d.report(name, checkError, fmt.Sprintf("'%s' is corrupted: %v", dbFile, err),
+			"restore it from a backup (secretserviced cannot load it)")
+		return
+	}
+
+	if status != nil && status.DbError != "" {
+		d.report(name, checkError, fmt.Sprintf("secretserviced cannot read '%s': %s", dbFile, status.DbError),
+			"set the MASTERPASSWORD the database was encrypted with")
+		return
+	}
+
+	items := 0
+
+	for _, collection := range db.Collections {
+		for _, item := range collection.Items {
+
+			items++
+
+			if !db.Encrypted || status != nil {
+				continue
+			}
+
+			if masterPassword == "" {
+				d.report(name, checkError, fmt.Sprintf("'%s' is encrypted but no valid MASTERPASSWORD is found", dbFile),
+					"set the MASTERPASSWORD the database was encrypted with")
+				return
+			}
+
+			if _, err := crypto.DecryptAESCBC256(masterPassword, item.Secret.SecretText); err != nil {
+				d.report(name, checkError, fmt.Sprintf("cannot decrypt '%s' with MASTERPASSWORD", dbFile),
+					"set the MASTERPASSWORD the database was encrypted with")
+				return
+			}
+		}
+	}
+
+	encryption := "not encrypted"
+	if db.Encrypted {
+		encryption = "encrypted"
+	}
+
+	message := fmt.Sprintf("%s: %d collections, %d items, %s", dbFile, len(db.Collections), items, encryption)
+
+	if info.Mode().Perm()&0077 != 0 {
+		d.report(name, checkWarning, fmt.Sprintf("%s, readable by others (%s)", message, info.Mode().Perm()),
+			"chmod 600 '"+dbFile+"'")
+		return
+	}
+
+	d.report(name, checkOK, message, "")
+}
+
+// checkLogFile checks secretserviced log file is writable
+func (d *doctor) checkLogFile(home string, config *internal.Config) {
+
+	const name = "log file"
+
+	if config != nil && !config.Logging {
+		d.report(name, checkSkipped, "logging is disabled", "")
+		return
+	}
+
+	if home == "" {
+		d.report(name, checkSkipped, "no home directory", "")
+		return
+	}
+
+	// same fallback as secretserviced
+	logFile := filepath.Join(home, "logs", "secretserviced.log")
+
+	if config != nil && config.LogFile != "" {
+		if _, err := os.Stat(filepath.Dir(config.LogFile)); err == nil {
+			logFile = config.LogFile
+		}
+	}
+
+	if _, err := os.Stat(logFile); err == nil {
+		file, err := os.OpenFile(logFile, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			d.report(name, checkError, fmt.Sprintf("'%s' is not writable: %v", logFile, err),
+				"chmod 600 '"+logFile+"'")
+			return
+		}
+		file.Close()
+	} else if err := syscall.Access(filepath.Dir(logFile), 2); err != nil { // W_OK
+		d.report(name, checkError, fmt.Sprintf("'%s' cannot be created: %v", logFile, err),
+			"chmod 700 '"+filepath.Dir(logFile)+"'")
+		return
+	}
+
+	d.report(name, checkOK, logFile, "")
+}
+
+// checkSystemdUnit checks secretserviced systemd user unit
+func (d *doctor) checkSystemdUnit() {
+
+	const name = "systemd unit"
+	const unitName = "secretserviced.service"
+	// MASTERPASSWORD of the sample unit file
+	const samplePassword = "01234567890123456789012345678912"
+
+	var directories []string
+
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		directories = append(directories, filepath.Join(configHome, "systemd", "user"))
+	}
+
+	if userHome, err := os.UserHomeDir(); err == nil {
+		directories = append(directories, filepath.Join(userHome, ".config", "systemd", "user"))
+	}
+
+	directories = append(directories, "/etc/systemd/user", "/usr/lib/systemd/user", "/lib/systemd/user")
+
+	unitFile := ""
+
+	for _, directory := range directories {
+		if _, err := os.Stat(filepath.Join(directory, unitName)); err == nil {
+			unitFile = filepath.Join(directory, unitName)
+			break
+		}
+	}
+
+	if unitFile == "" {
+		d.report(name, checkWarning, unitName+" is not installed",
+			"copy assets/"+unitName+" to ~/.config/systemd/user/ and run 'systemctl --user enable --now secretserviced'")
+		return
+	}
+
+	file, err := os.Open(unitFile)
+
+	if err != nil {
+		d.report(name, checkError, fmt.Sprintf("cannot read '%s': %v", unitFile, err), "")
+		return
+	}
+
+	defer file.Close()
+
+	var problems []string
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "Environment=") {
+			for _, variable := range strings.Fields(strings.TrimPrefix(line, "Environment=")) {
+				variable = strings.Trim(variable, `"'`)
+				if !strings.HasPrefix(variable, "MASTERPASSWORD=") {
+					continue
+				}
+				masterPassword := strings.TrimPrefix(variable, "MASTERPASSWORD=")
+				if len(masterPassword) != 32 {
+					problems = append(problems, fmt.Sprintf("MASTERPASSWORD has %d characters, expected 32",
+						len(masterPassword)))
+				} else if masterPassword == samplePassword {
+					problems = append(problems, "MASTERPASSWORD is the sample one")
+				}
+			}
+		}
+
+		if strings.HasPrefix(line, "ExecStart=") {
+			executable := strings.Fields(strings.TrimPrefix(line, "ExecStart="))
+			if len(executable) > 0 {
+				if _, err := os.Stat(strings.TrimLeft(executable[0], "-@:+!")); err != nil {
+					problems = append(problems, fmt.Sprintf("ExecStart '%s' does not exist", executable[0]))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		d.report(name, checkWarning, unitFile+": "+strings.Join(problems, "; "),
+			"edit the unit ('systemctl --user edit --full secretserviced') and restart it")
+		return
+	}
+
+	message := unitFile
+
+	if _, err := exec.LookPath("systemctl"); err == nil {
+		state, _ := exec.Command("systemctl", "--user", "is-active", "secretserviced").Output()
+		message += " (" + strings.TrimSpace(string(state)) + ")"
+	}
+
+	d.report(name, checkOK, message, "")
+}
@@ -1,13 +1,8 @@
 package cmd
 
 import (
-	"bufio"
-	"io/ioutil"
-	"os"
-	"strings"
-
 	"github.com/spf13/cobra"
-	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
 )
 
 func init() {
@@ -15,7 +10,7 @@ func init() {
 	encryptCmd.Flags().StringP("password", "p", "", "database password")
 
 	encryptCmd.Flags().StringP("input", "i", "", "input file")
-	encryptCmd.Flags().StringP("output", "o", "", "output file")
+	encryptCmd.Flags().StringP("out", "o", "", "output file")
 }
 
 var encryptCmd = &cobra.Command{
@@ -26,56 +21,35 @@ var encryptCmd = &cobra.Command{
 
 		password, _ := cmd.Flags().GetString("password")
 		input, _ := cmd.Flags().GetString("input")
-		output, _ := cmd.Flags().GetString("output")
+		output, _ := cmd.Flags().GetString("out")
 
 		if len(password) != 32 {
-			panic("Wrong password length. Password should be exactly 32 characters.")
+			fail(exitUsage, "Wrong password length. Password should be exactly 32 characters.")
 		}
 
 		if exist, _ := fileOrFolderExists(input); !exist {
-			panic("Input file doesn't exist")
+			fail(exitNotFound, "Input file doesn't exist: %s", input)
 		}
 
-		file, err := os.Open(input)
+		db, err := service.ReadDatabase(input)
 
 		if err != nil {
-			panic("failed to open input file")
+			fail(exitUsage, "%v", err)
 		}
-		defer file.Close()
 
-		scanner := bufio.NewScanner(file)
-		scanner.Split(bufio.ScanLines)
-		var i int = 0
-		var signature bool = false
-		var encrypted []string
+		if db.Encrypted {
+			fail(exitUsage, "Wrong type of file. This file is not marked as non \"encrypted\"!")
+		}
 
-		for scanner.Scan() {
-			i++
-			line := scanner.Text()
-			if line == " \"encrypted\": false," {
-				signature = true
-			}
-			if i > 2 && !signature {
-				panic("Wrong type of file. This file is not marked as non \"encrypted\"!")
-			}
-			if index := strings.Index(line, "secretText"); index > 0 {
-				text := line[21 : len(line)-1]
-				cipher, err := crypto.EncryptAESCBC256(password, text)
-				if err != nil {
-					panic("Encryption failed: " + err.Error())
-				}
-				newLine := line[:21] + cipher + "\""
-				encrypted = append(encrypted, newLine)
-			} else {
-				encrypted = append(encrypted, line)
-			}
+		if err := db.EncryptSecrets(password); err != nil {
+			fail(exitIO, "Encryption failed: %v", err)
 		}
 
-		fileContent := strings.Join(encrypted, "\n")
-		err = ioutil.WriteFile(output, []byte(fileContent), 0755)
-		if err != nil {
-			panic("Writing to output file failed: " + output)
+		if err := service.WriteDatabase(db, output); err != nil {
+			fail(exitIO, "Writing to output file failed: %v", err)
 		}
 
+		printStatus("database encrypted: " + output)
+
 	},
 }
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().StringArrayP("env", "e", nil,
+		"NAME=attribute=value,attribute=value (repeatable)")
+	// flags after command belong to command
+	execCmd.Flags().SetInterspersed(false)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec --env NAME=attribute=value,... -- command [argument...]",
+	Short: "Run a command with secrets in its environment",
+	Long: `Run a command with environment variables set to secrets. Each '--env'
+maps a variable name to the lookup attributes of an item. Secrets are only
+passed to the command environment and never written to disk. Signals are
+forwarded to the command and its exit code is passed through.
+Example:
+
+  secretservice exec --env DB_PASSWORD=service=postgres,user=app -- ./server`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		mappings, _ := cmd.Flags().GetStringArray("env")
+		variables := make(map[string]map[string]string)
+		var names []string
+
+		for _, mapping := range mappings {
+
+			name, lookupAttributes, err := parseEnvMapping(mapping)
+
+			if err != nil {
+				fail(exitUsage, "invalid --env '%s': %v", mapping, err)
+			}
+
+			if _, ok := variables[name]; !ok {
+				names = append(names, name)
+			}
+
+			variables[name] = lookupAttributes
+		}
+
+		environment := os.Environ()
+
+		if len(names) > 0 {
+
+			ssClient := newClient()
+			session := openSession(ssClient)
+
+			for _, name := range names {
+
+				unlocked, locked := searchItems(ssClient, variables[name])
+
+				if len(unlocked) == 0 {
+					if len(locked) > 0 {
+						fail(exitLocked, "item of '%s' is locked: %s", name, locked[0])
+					}
+					fail(exitNotFound, "no matching item for '%s'", name)
+				}
+
+				secret := readSecret(session, loadItem(ssClient, unlocked[0]))
+				environment = setEnv(environment, name, secret)
+			}
+		}
+
+		os.Exit(runCommand(args, environment))
+	},
+}
+
+// parseEnvMapping parses 'NAME=attribute=value,attribute=value'
+func parseEnvMapping(mapping string) (string, map[string]string, error) {
+
+	index := strings.Index(mapping, "=")
+
+	if index < 1 {
+		return "", nil, errors.New("expected NAME=attribute=value,...")
+	}
+
+	name := mapping[:index]
+	lookupAttributes := make(map[string]string)
+
+	for _, pair := range strings.Split(mapping[index+1:], ",") {
+
+		separator := strings.Index(pair, "=")
+
+		if separator < 1 {
+			return "", nil, fmt.Errorf("expected attribute=value, got '%s'", pair)
+		}
+
+		lookupAttributes[pair[:separator]] = pair[separator+1:]
+	}
+
+	return name, lookupAttributes, nil
+}
+
+// setEnv sets variable in given environment replacing former values
+func setEnv(environment []string, name string, value string) []string {
+
+	result := make([]string, 0, len(environment)+1)
+
+	for _, variable := range environment {
+		if !strings.HasPrefix(variable, name+"=") {
+			result = append(result, variable)
+		}
+	}
+
+	return append(result, name+"="+value)
+}
+
+// runCommand runs given command with given environment
+// forwarding signals to it and returns its exit code
+func runCommand(args []string, environment []string) int {
+
+	command := exec.Command(args[0], args[1:]...)
+	command.Env = environment
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP,
+		syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+
+	if err := command.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			fail(exitCommandNotFound, "cannot run '%s': %v", args[0], err)
+		}
+		fail(exitCannotExecute, "cannot run '%s': %v", args[0], err)
+	}
+
+	go func() {
+		for received := range signals {
+			command.Process.Signal(received)
+		}
+	}()
+
+	err := command.Wait()
+
+	if exitError, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitError.ExitCode()
+	}
+
+	if err != nil {
+		fail(exitCannotExecute, "cannot run '%s': %v", args[0], err)
+	}
+
+	return exitOK
+}
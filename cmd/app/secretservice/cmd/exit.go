@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exit codes of secretservice commands
+const (
+	exitOK        = 0 // success
+	exitNotFound  = 1 // no matching item or collection
+	exitUsage     = 2 // wrong flags or arguments
+	exitDbus      = 3 // dbus call or secretserviced failure
+	exitLocked    = 4 // item, collection or service is locked
+	exitIO        = 5 // reading or writing a file failed
+	exitUnhealthy = 6 // 'doctor' or 'config validate' found a problem
+
+	// 'exec' exits with command exit code or these (same as shells)
+	exitCannotExecute   = 126 // command cannot be executed
+	exitCommandNotFound = 127 // command is not found
+)
+
+// fail prints given message to stderr (in json or yaml format if
+// '--output' is set so) and exits with given code
+func fail(code int, format string, a ...interface{}) {
+
+	message := fmt.Sprintf(format, a...)
+
+	switch outputFormat {
+	case outputJSON:
+		output, _ := json.Marshal(errorOutput{Error: message, Code: code})
+		fmt.Fprintln(os.Stderr, string(output))
+	case outputYAML:
+		output, _ := yaml.Marshal(errorOutput{Error: message, Code: code})
+		fmt.Fprint(os.Stderr, string(output))
+	default:
+		fmt.Fprintln(os.Stderr, message)
+	}
+
+	os.Exit(code)
+}
+
+// exitCode returns the exit code matching given dbus call error
+func exitCode(err error) int {
+	// client wraps dbus errors as text, secretserviced reports
+	// 'IsLocked' as 'AccessDenied' with the standard message
+	if strings.Contains(err.Error(), "must be unlocked") ||
+		strings.Contains(err.Error(), "org.freedesktop.Secret.Error.IsLocked") {
+		return exitLocked
+	}
+	return exitDbus
+}
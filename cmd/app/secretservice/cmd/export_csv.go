@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func init() {
+	exportCmd.AddCommand(exportCsvCmd)
+	exportCsvCmd.Flags().StringP("out", "o", "", "csv file to write (required)")
+	exportCsvCmd.Flags().StringSliceP("collection", "c", nil, "collection alias or path to export (default all)")
+	exportCsvCmd.Flags().Bool("plaintext", false, "acknowledge that secrets are written unencrypted")
+	exportCsvCmd.Flags().Bool("force", false, "overwrite existing file")
+	addCsvMappingFlags(exportCsvCmd)
+	exportCsvCmd.MarkFlagRequired("out")
+}
+
+var exportCsvCmd = &cobra.Command{
+	Use:   "csv --out F --plaintext [--preset P | --mapping M]",
+	Short: "Export collections to a plaintext csv file",
+	Long: `Export items to a csv file with label, secret and lookup attribute
+columns set by a preset, a yaml mapping file and/or flags (see 'import csv').
+Secrets are written unencrypted so --plaintext is required and export must be
+allowed by 'allowDbExport' config of secretserviced. Locked collections are
+skipped.
+Example:
+
+  secretservice export csv --out passwords.csv --preset chrome --plaintext`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		out, _ := cmd.Flags().GetString("out")
+		collectionNames, _ := cmd.Flags().GetStringSlice("collection")
+		plaintext, _ := cmd.Flags().GetBool("plaintext")
+		force, _ := cmd.Flags().GetBool("force")
+		mapping := csvMappingFlags(cmd)
+
+		if !plaintext {
+			fail(exitUsage, "csv files are not encrypted, use --plaintext to export anyway (or 'export kdbx')")
+		}
+
+		if exists, _ := fileOrFolderExists(out); exists && !force {
+			fail(exitUsage, "'%s' exists, use --force to overwrite it", out)
+		}
+
+		ssClient := newClient()
+		status, err := ssClient.Status()
+
+		if err != nil {
+			fail(exitCode(err), "cannot read secretserviced status: %v", err)
+		}
+
+		if !status.AllowDbExport {
+			fail(exitUsage, "export is not allowed by secretserviced config ('allowDbExport')")
+		}
+
+		session := openSession(ssClient)
+		var items []*client.Item
+
+		for _, collection := range exportCollections(ssClient, collectionNames) {
+
+			itemPaths, err := collection.PropertyGetItems()
+
+			if err != nil {
+				fail(exitDbus, "cannot read items of '%s': %v", collection.ObjectPath, err)
+			}
+
+			sort.Strings(itemPaths)
+
+			for _, itemPath := range itemPaths {
+				items = append(items, loadItem(ssClient, dbus.ObjectPath(itemPath)))
+			}
+		}
+
+		columns := csvColumns(mapping, items)
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		writer.Write(columns)
+
+		for _, item := range items {
+			writer.Write(csvItemRecord(mapping, columns, item, readSecret(session, item)))
+		}
+
+		writer.Flush()
+
+		if err := writer.Error(); err != nil {
+			fail(exitIO, "cannot write csv: %v", err)
+		}
+
+		if err := writePrivateFile(out, buffer.Bytes()); err != nil {
+			fail(exitIO, "cannot write '%s': %v", out, err)
+		}
+
+		printStatus(fmt.Sprintf("exported %d item(s) to %s", len(items), out))
+	},
+}
+
+// exportCollections returns unlocked collections of given names (all if
+// there is none) or exits, locked collections are reported on stderr
+func exportCollections(ssClient *client.Client, names []string) []*client.Collection {
+
+	var collectionPaths []dbus.ObjectPath
+
+	if len(names) > 0 {
+		collectionPaths = resolveCollectionPaths(ssClient, names)
+	} else {
+		paths, err := ssClient.PropertyGetCollections()
+		if err != nil {
+			fail(exitDbus, "cannot read collections: %v", err)
+		}
+		for _, path := range paths {
+			collectionPaths = append(collectionPaths, dbus.ObjectPath(path))
+		}
+		sort.Slice(collectionPaths, func(i, j int) bool { return collectionPaths[i] < collectionPaths[j] })
+	}
+
+	var collections []*client.Collection
+
+	for _, collectionPath := range collectionPaths {
+
+		collection, err := ssClient.LoadCollection(collectionPath)
+
+		if err != nil {
+			fail(exitDbus, "cannot read collection '%s': %v", collectionPath, err)
+		}
+
+		if collection.Locked {
+			fmt.Fprintf(os.Stderr, "warning: '%s' is locked and not exported\n", collection.Label)
+			continue
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections
+}
+
+// csvColumns returns header of exported csv: mapping columns (or label and
+// secret), mapped attribute columns and other attributes if mapping is extra
+func csvColumns(mapping csvMapping, items []*client.Item) []string {
+
+	columns := append([]string{}, mapping.Columns...)
+
+	if len(columns) == 0 {
+		columns = []string{mapping.Label, mapping.Secret}
+	}
+
+	has := make(map[string]bool)
+
+	for _, column := range columns {
+		has[strings.ToLower(column)] = true
+	}
+
+	var added []string
+
+	add := func(column string) {
+		if !has[strings.ToLower(column)] {
+			has[strings.ToLower(column)] = true
+			added = append(added, column)
+		}
+	}
+
+	add(mapping.Label)
+	add(mapping.Secret)
+
+	for _, column := range mapping.Attributes {
+		add(column)
+	}
+
+	if mapping.Extra {
+		for _, item := range items {
+			for name := range item.LookupAttributes {
+				if _, ok := mapping.Attributes[name]; !ok {
+					add(name)
+				}
+			}
+		}
+	}
+
+	sort.Strings(added)
+
+	return append(columns, added...)
+}
+
+// csvItemRecord returns csv record of an item
+func csvItemRecord(mapping csvMapping, columns []string, item *client.Item, secret string) []string {
+
+	record := make([]string, len(columns))
+
+	for index, column := range columns {
+
+		record[index] = mapping.Values[column]
+
+		switch {
+		case strings.EqualFold(column, mapping.Label):
+			record[index] = item.Label
+			continue
+		case strings.EqualFold(column, mapping.Secret):
+			record[index] = secret
+			continue
+		}
+
+		for name, attributeColumn := range mapping.Attributes {
+			if strings.EqualFold(column, attributeColumn) {
+				record[index] = itemAttribute(item, name)
+			}
+		}
+
+		if value, ok := item.LookupAttributes[column]; ok && mapping.Extra {
+			if _, mapped := mapping.Attributes[column]; !mapped {
+				record[index] = value
+			}
+		}
+	}
+
+	return record
+}
+
+// itemAttribute returns value of a lookup attribute of item, matched
+// case-insensitively if there is no exact match (i.e. 'UserName' for
+// 'username')
+func itemAttribute(item *client.Item, name string) string {
+
+	if value, ok := item.LookupAttributes[name]; ok {
+		return value
+	}
+
+	for attribute, value := range item.LookupAttributes {
+		if strings.EqualFold(attribute, name) {
+			return value
+		}
+	}
+
+	return ""
+}
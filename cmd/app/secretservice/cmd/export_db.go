@@ -1,29 +1,34 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
-	"github.com/yousefvand/secret-service/pkg/client"
 )
 
 func init() {
-	rootCmd.AddCommand(exportDbCmd)
+	exportCmd.AddCommand(exportDbCmd)
 }
 
 var exportDbCmd = &cobra.Command{
-	Use:   "export db",
+	Use:   "db",
 	Short: "export db exports an unencrypted version of db",
 	Long:  `export db exports an unencrypted version of credentials database`,
 	Run: func(_ *cobra.Command, _ []string) {
 
-		ssClient, _ := client.New()
-		response, _ := ssClient.SecretServiceCommand("export database", "")
+		response, err := newClient().SecretServiceCommand("export database", "")
+
+		if err != nil {
+			fail(exitDbus, "database export failed: %v", err)
+		}
 
-		if response == "ok" {
-			fmt.Println("database export completed successfully")
-		} else {
-			fmt.Println("database export failed!")
+		switch response {
+		case "ok":
+			printStatus("database export completed successfully")
+		case "verboten":
+			fail(exitDbus, "database export failed: export is not allowed by secretserviced config")
+		case "locked":
+			fail(exitLocked, "database export failed: service is locked")
+		default:
+			fail(exitDbus, "database export failed: %s", response)
 		}
 	},
 }
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/kdbx"
+)
+
+func init() {
+	exportCmd.AddCommand(exportKdbxCmd)
+	exportKdbxCmd.Flags().StringP("out", "o", "", "KeePass database file to write (required)")
+	exportKdbxCmd.Flags().StringSliceP("collection", "c", nil, "collection alias or path to export (default all)")
+	exportKdbxCmd.Flags().Bool("force", false, "overwrite existing file")
+	exportKdbxCmd.MarkFlagRequired("out")
+}
+
+// lookup attributes (lower case) exported as standard fields of KeePass
+// entries, the first matching attribute of an item wins
+var kdbxStandardFields = map[string]string{
+	"username": "UserName",
+	"user":     "UserName",
+	"url":      "URL",
+}
+
+var exportKdbxCmd = &cobra.Command{
+	Use:   "kdbx --out F",
+	Short: "Export collections to a KeePass (KDBX 4) database",
+	Long: `Export collections to a KeePass database in KDBX 4 format protected by
+a passphrase read from standard input (Argon2id key derivation and AES-256
+encryption). Each collection becomes a group and each item an entry titled by
+item label with the secret as password. 'UserName' and 'URL' attributes (or
+'username', 'user' and 'url') become standard fields, other attributes become
+custom fields. Creation and modification times are preserved. Locked
+collections are skipped.
+Example:
+
+  echo -n 'passphrase' | secretservice export kdbx --out vault.kdbx`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		out, _ := cmd.Flags().GetString("out")
+		collectionNames, _ := cmd.Flags().GetStringSlice("collection")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if exists, _ := fileOrFolderExists(out); exists && !force {
+			fail(exitUsage, "'%s' exists, use --force to overwrite it", out)
+		}
+
+		passphrase := readStdinTwice("passphrase: ", "repeat passphrase: ")
+
+		if passphrase == "" {
+			fail(exitUsage, "passphrase is empty")
+		}
+
+		key, err := kdbx.NewKey(passphrase, nil)
+
+		if err != nil {
+			fail(exitUsage, "%v", err)
+		}
+
+		ssClient := newClient()
+		session := openSession(ssClient)
+
+		db := &kdbx.Database{Name: "secretservice", Root: &kdbx.Group{Name: "secretservice"}}
+		items := 0
+
+		for _, collection := range exportCollections(ssClient, collectionNames) {
+			group := kdbxGroup(session, collection)
+			db.Root.Groups = append(db.Root.Groups, group)
+			items += len(group.Entries)
+		}
+
+		var buffer bytes.Buffer
+
+		if err := kdbx.Write(&buffer, db, key); err != nil {
+			fail(exitIO, "cannot encrypt database: %v", err)
+		}
+
+		if err := writePrivateFile(out, buffer.Bytes()); err != nil {
+			fail(exitIO, "cannot write '%s': %v", out, err)
+		}
+
+		printStatus(fmt.Sprintf("exported %d item(s) of %d collection(s) to %s",
+			items, len(db.Root.Groups), out))
+	},
+}
+
+// kdbxGroup returns a collection with its items as a KeePass group or exits
+func kdbxGroup(session *client.Session, collection *client.Collection) *kdbx.Group {
+
+	itemPaths, err := collection.PropertyGetItems()
+
+	if err != nil {
+		fail(exitDbus, "cannot read items of '%s': %v", collection.ObjectPath, err)
+	}
+
+	sort.Strings(itemPaths)
+
+	group := &kdbx.Group{
+		Name:     collection.Label,
+		Created:  kdbxTime(collection.Created),
+		Modified: kdbxTime(collection.Modified),
+	}
+
+	for _, itemPath := range itemPaths {
+		item := loadItem(collection.Parent, dbus.ObjectPath(itemPath))
+		group.Entries = append(group.Entries, kdbxEntry(item, readSecret(session, item)))
+	}
+
+	return group
+}
+
+// kdbxEntry returns an item as a KeePass entry
+func kdbxEntry(item *client.Item, secret string) *kdbx.Entry {
+
+	standard := map[string]string{}
+	var custom []kdbx.Field
+
+	names := make([]string, 0, len(item.LookupAttributes))
+
+	for name := range item.LookupAttributes {
+		names = append(names, name)
+	}
+
+	// exact field names first so 'UserName' wins over 'user'
+	sort.Slice(names, func(i, j int) bool {
+		iStandard := kdbxStandardFields[strings.ToLower(names[i])] == names[i]
+		jStandard := kdbxStandardFields[strings.ToLower(names[j])] == names[j]
+		if iStandard != jStandard {
+			return iStandard
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		value := item.LookupAttributes[name]
+		field, ok := kdbxStandardFields[strings.ToLower(name)]
+		switch {
+		// title is the item label (see 'import kdbx')
+		case name == "Title" && value == item.Label:
+		case ok && standard[field] == "":
+			standard[field] = value
+		case isKdbxField(name):
+			// field names must be unique
+			custom = append(custom, kdbx.Field{Key: name + " (attribute)", Value: value})
+		default:
+			custom = append(custom, kdbx.Field{Key: name, Value: value})
+		}
+	}
+
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Key < custom[j].Key })
+
+	fields := []kdbx.Field{
+		{Key: "Title", Value: item.Label},
+		{Key: "UserName", Value: standard["UserName"]},
+		{Key: "Password", Value: secret, Protected: true},
+		{Key: "URL", Value: standard["URL"]},
+		{Key: "Notes"},
+	}
+
+	return &kdbx.Entry{
+		Fields:   append(fields, custom...),
+		Created:  kdbxTime(item.Created),
+		Modified: kdbxTime(item.Modified),
+	}
+}
+
+// isKdbxField returns true if name is a standard field of KeePass entries
+func isKdbxField(name string) bool {
+	switch name {
+	case "Title", "UserName", "Password", "URL", "Notes":
+		return true
+	}
+	return false
+}
+
+// kdbxTime converts a unix epoch to time, zero epoch is zero time
+func kdbxTime(epoch uint64) time.Time {
+	if epoch == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(epoch), 0).UTC()
+}
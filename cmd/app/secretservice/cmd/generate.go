@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/generator"
+)
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.Flags().StringP("label", "l", "", "item label (required)")
+	generateCmd.Flags().StringP("collection", "c", "default", "collection alias or path")
+	generateCmd.Flags().IntP("length", "n", 32, "password length")
+	generateCmd.Flags().String("charset", generator.DefaultCharset,
+		"charset names separated by ',' ("+strings.Join(generator.CharsetNames(), ", ")+") or literal characters")
+	generateCmd.Flags().IntP("words", "w", 0, "generate a diceware passphrase of given number of words")
+	generateCmd.Flags().String("separator", "-", "separator of passphrase words")
+	generateCmd.Flags().BoolP("print", "p", false, "print generated secret")
+	generateCmd.MarkFlagRequired("label")
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate --label L attribute value...",
+	Short: "Generate and store a password or passphrase",
+	Long: `Generate a random password (or a diceware passphrase with --words) and
+store it with given label and lookup attributes in one step, so the secret
+never shows up in command line arguments or shell history. An item with
+exactly the same attributes in the collection is replaced. The secret is
+printed only if --print is set.
+Example:
+
+  secretservice generate --label 'db' --length 24 --charset alnum service db user admin
+  secretservice generate --label 'disk' --words 6 --print service luks`,
+	Args: attributeArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		label, _ := cmd.Flags().GetString("label")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		length, _ := cmd.Flags().GetInt("length")
+		charsetSpec, _ := cmd.Flags().GetString("charset")
+		count, _ := cmd.Flags().GetInt("words")
+		separator, _ := cmd.Flags().GetString("separator")
+		reveal, _ := cmd.Flags().GetBool("print")
+		lookupAttributes := attributes(args)
+
+		if cmd.Flags().Changed("words") &&
+			(cmd.Flags().Changed("length") || cmd.Flags().Changed("charset")) {
+			fail(exitUsage, "--words cannot be used with --length or --charset")
+		}
+
+		var secret string
+		var entropy float64
+		var err error
+
+		if cmd.Flags().Changed("words") {
+			secret, err = generator.Passphrase(count, separator)
+			entropy = generator.Entropy(count, generator.Words())
+		} else {
+			var charset []rune
+			charset, err = generator.Charset(charsetSpec)
+			if err == nil {
+				secret, err = generator.Password(length, charset)
+				entropy = generator.Entropy(length, len(charset))
+			}
+		}
+
+		if err != nil {
+			fail(exitUsage, "cannot generate secret: %v", err)
+		}
+
+		if entropy < 64 {
+			fmt.Fprintf(os.Stderr, "warning: generated secret has only %.0f bits of entropy\n", entropy)
+		}
+
+		ssClient := newClient()
+		session := openSession(ssClient)
+		collection := resolveCollection(ssClient, collectionName)
+
+		secretApi, err := session.EncryptSecret([]byte(secret), "text/plain")
+
+		if err != nil {
+			fail(exitDbus, "cannot encrypt secret: %v", err)
+		}
+
+		var printed *string
+
+		if reveal {
+			printed = &secret
+		}
+
+		if item := replaceableItem(ssClient, collection, lookupAttributes); item != nil {
+
+			if err := item.SetSecret(secretApi); err != nil {
+				fail(exitCode(err), "cannot replace secret of '%s': %v", item.ObjectPath, err)
+			}
+
+			if err := item.PropertySetLabel(label); err != nil {
+				fail(exitCode(err), "cannot set label of '%s': %v", item.ObjectPath, err)
+			}
+
+			printValue(newItemOutput(item, printed), func() { printSecret(printed) })
+			return
+		}
+
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(label),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(lookupAttributes),
+		}
+
+		item, _, err := collection.CreateItem(properties, secretApi, true)
+
+		if err != nil {
+			fail(exitCode(err), "cannot store secret: %v", err)
+		}
+
+		printValue(newItemOutput(item, printed), func() { printSecret(printed) })
+	},
+}
+
+// printSecret prints secret in text output if it is given
+func printSecret(secret *string) {
+	if secret != nil {
+		fmt.Println(*secret)
+	}
+}
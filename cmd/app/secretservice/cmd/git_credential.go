@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/gitcredential"
+)
+
+func init() {
+	rootCmd.AddCommand(gitCredentialCmd)
+}
+
+var gitCredentialCmd = &cobra.Command{
+	Use:   "git-credential get|store|erase",
+	Short: "Git credential helper",
+	Long: `Git credential helper (git-credential(1) protocol on standard input and
+output). Credentials are stored with the same lookup attributes as
+'git-credential-libsecret' so already stored credentials keep working.
+Unknown actions are ignored as git expects. Setup:
+
+  git config --global credential.helper '!secretservice git-credential'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+
+		action := args[0]
+
+		if action != "get" && action != "store" && action != "erase" {
+			return
+		}
+
+		credential, err := gitcredential.Read(os.Stdin)
+
+		if err != nil {
+			fail(exitUsage, "cannot read credential: %v", err)
+		}
+
+		ssClient := newClient()
+		helper := &gitcredential.Helper{
+			Client:  ssClient,
+			Session: openSession(ssClient),
+		}
+
+		switch action {
+		case "get":
+			var result *gitcredential.Credential
+			result, err = helper.Get(credential)
+			if err == nil && result != nil {
+				err = result.Write(os.Stdout)
+			}
+		case "store":
+			helper.Collection = resolveCollection(ssClient, "default")
+			err = helper.Store(credential)
+		case "erase":
+			err = helper.Erase(credential)
+		}
+
+		if err == gitcredential.ErrIncomplete {
+			fail(exitUsage, "%v", err)
+		}
+
+		if err != nil {
+			fail(exitCode(err), "git-credential %s failed: %v", action, err)
+		}
+	},
+}
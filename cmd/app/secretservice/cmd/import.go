@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import secrets from other password managers",
+}
+
+// duplicate handling of imported items
+const (
+	duplicateSkip    = "skip"
+	duplicateReplace = "replace"
+	duplicateRename  = "rename"
+)
+
+// importer stores imported items in collections of secretserviced
+type importer struct {
+	client  *client.Client
+	session *client.Session
+	// what to do with an item having exactly the same lookup attributes
+	// as an existing item of the collection: skip, replace or rename
+	onDuplicate string
+	// collections by label
+	collections map[string]*client.Collection
+	// number of created, replaced, renamed and skipped items
+	created, replaced, renamed, skipped int
+}
+
+// newImporter connects to secretserviced or exits
+func newImporter(onDuplicate string) *importer {
+
+	switch onDuplicate {
+	case duplicateSkip, duplicateReplace, duplicateRename:
+	default:
+		fail(exitUsage, "--on-duplicate must be skip, replace or rename, got '%s'", onDuplicate)
+	}
+
+	ssClient := newClient()
+
+	return &importer{
+		client:      ssClient,
+		session:     openSession(ssClient),
+		onDuplicate: onDuplicate,
+		collections: make(map[string]*client.Collection),
+	}
+}
+
+// collection returns the collection with given label, it is created if
+// there is none, or exits
+func (imp *importer) collection(label string) *client.Collection {
+
+	if collection, ok := imp.collections[label]; ok {
+		return collection
+	}
+
+	collectionPaths, err := imp.client.PropertyGetCollections()
+
+	if err != nil {
+		fail(exitDbus, "cannot read collections: %v", err)
+	}
+
+	for _, collectionPath := range collectionPaths {
+
+		collection, err := imp.client.LoadCollection(dbus.ObjectPath(collectionPath))
+
+		if err == nil && collection.Label == label {
+			imp.collections[label] = collection
+			return collection
+		}
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant(label),
+	}
+
+	collection, _, err := imp.client.CreateCollection(properties, "")
+
+	if err != nil {
+		fail(exitCode(err), "cannot create collection '%s': %v", label, err)
+	}
+
+	imp.collections[label] = collection
+
+	return collection
+}
+
+// store stores a secret in collection with given label, lookup attributes
+// and item type (schema name, optional) handling duplicates, or exits. It
+// returns the created item, nil if the secret is skipped or replaced.
+func (imp *importer) store(collection *client.Collection, label string,
+	secret string, lookupAttributes map[string]string, itemType string) *client.Item {
+
+	secretApi, err := imp.session.EncryptSecret([]byte(secret), "text/plain")
+
+	if err != nil {
+		fail(exitDbus, "cannot encrypt secret: %v", err)
+	}
+
+	duplicates := imp.duplicates(collection, lookupAttributes)
+	renamed := false
+
+	if len(duplicates) > 0 {
+
+		switch imp.onDuplicate {
+
+		case duplicateSkip:
+			imp.skipped++
+			return nil
+
+		case duplicateReplace:
+			item := duplicates[0]
+			if err := item.SetSecret(secretApi); err != nil {
+				fail(exitCode(err), "cannot replace secret of '%s': %v", item.ObjectPath, err)
+			}
+			if err := item.PropertySetLabel(label); err != nil {
+				fail(exitCode(err), "cannot set label of '%s': %v", item.ObjectPath, err)
+			}
+			imp.replaced++
+			return nil
+
+		case duplicateRename:
+			label = uniqueLabel(label, duplicates)
+			renamed = true
+		}
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(label),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(lookupAttributes),
+	}
+
+	if itemType != "" {
+		properties["org.freedesktop.Secret.Item.Type"] = dbus.MakeVariant(itemType)
+	}
+
+	item, _, err := collection.CreateItem(properties, secretApi, false)
+
+	if err != nil {
+		fail(exitCode(err), "cannot store '%s': %v", label, err)
+	}
+
+	if renamed {
+		imp.renamed++
+	} else {
+		imp.created++
+	}
+
+	return item
+}
+
+// duplicates returns unlocked items of collection having exactly given
+// lookup attributes
+func (imp *importer) duplicates(collection *client.Collection,
+	lookupAttributes map[string]string) []*client.Item {
+
+	var items []*client.Item
+	unlocked, _ := searchItems(imp.client, lookupAttributes)
+
+	for _, itemPath := range unlocked {
+		item := loadItem(imp.client, itemPath)
+		if item.Parent.ObjectPath == collection.ObjectPath &&
+			reflect.DeepEqual(item.LookupAttributes, lookupAttributes) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// uniqueLabel returns label with the first ' (n)' suffix no item has
+func uniqueLabel(label string, items []*client.Item) string {
+
+	labels := make(map[string]bool)
+
+	for _, item := range items {
+		labels[item.Label] = true
+	}
+
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s (%d)", label, n); !labels[candidate] {
+			return candidate
+		}
+	}
+}
+
+// summary prints the result of import
+func (imp *importer) summary(warnings []string) {
+
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	printStatus(fmt.Sprintf("imported %d item(s): %d created, %d replaced, %d renamed, %d skipped",
+		imp.created+imp.replaced+imp.renamed, imp.created, imp.replaced, imp.renamed, imp.skipped))
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	importCmd.AddCommand(importCsvCmd)
+	importCsvCmd.Flags().StringP("file", "f", "", "csv file (required)")
+	importCsvCmd.Flags().StringP("collection", "c", "default", "collection alias or path")
+	importCsvCmd.Flags().String("on-duplicate", duplicateSkip, "item with the same attributes: skip, replace or rename")
+	importCsvCmd.Flags().Bool("dry-run", false, "print rows to import without importing them")
+	addCsvMappingFlags(importCsvCmd)
+	importCsvCmd.MarkFlagRequired("file")
+}
+
+var importCsvCmd = &cobra.Command{
+	Use:   "csv --file F [--preset P | --mapping M]",
+	Short: "Import a csv file",
+	Long: `Import rows of a csv file with a header row. Label, secret and lookup
+attribute columns (matched case-insensitively) are set by a preset, a yaml
+mapping file and/or flags. Rows with an empty label or secret and malformed
+rows are reported and not imported. With --dry-run rows are printed (without
+secrets) and nothing is imported.
+Example:
+
+  secretservice import csv --file passwords.csv --preset chrome --dry-run
+  secretservice import csv --file logins.csv --label site --secret pass --attribute user=login`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		file, _ := cmd.Flags().GetString("file")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		mapping := csvMappingFlags(cmd)
+
+		csvFile, err := os.Open(file)
+
+		if err != nil {
+			fail(exitNotFound, "cannot open csv file: %v", err)
+		}
+
+		defer csvFile.Close()
+
+		rows, secrets := readCsvRows(csv.NewReader(csvFile), mapping)
+		failed := 0
+
+		for _, row := range rows {
+			if row.Error != "" {
+				fmt.Fprintf(os.Stderr, "row %d: %s\n", row.Row, row.Error)
+				failed++
+			}
+		}
+
+		if dryRun {
+			printValue(rows, func() {
+				for _, row := range rows {
+					if row.Error == "" {
+						fmt.Printf("row %d: %s %s\n", row.Row, row.Label, formatAttributes(row.Attributes))
+					}
+				}
+			})
+		} else {
+			imp := newImporter(onDuplicate)
+			collection := resolveCollection(imp.client, collectionName)
+			for i, row := range rows {
+				if row.Error == "" {
+					imp.store(collection, row.Label, secrets[i], row.Attributes, "")
+				}
+			}
+			imp.summary(nil)
+		}
+
+		if failed > 0 {
+			fail(exitUsage, "%d row(s) not imported", failed)
+		}
+	},
+}
+
+// readCsvRows reads rows of csv data by mapping and returns them with their
+// secrets, malformed rows have an error. It exits if header is malformed.
+func readCsvRows(reader *csv.Reader, mapping csvMapping) ([]csvRowOutput, []string) {
+
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+
+	if err != nil {
+		fail(exitUsage, "cannot read csv header: %v", err)
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns := make(map[string]int)
+
+	for index, name := range header {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			columns[strings.ToLower(name)] = index
+		}
+	}
+
+	column := func(name string) int {
+		index, ok := columns[strings.ToLower(name)]
+		if !ok {
+			fail(exitUsage, "column '%s' is not in csv header (%s)", name, strings.Join(header, ", "))
+		}
+		return index
+	}
+
+	labelColumn := column(mapping.Label)
+	secretColumn := column(mapping.Secret)
+	attributeColumns := make(map[string]int)
+	used := map[int]bool{labelColumn: true, secretColumn: true}
+
+	for name, columnName := range mapping.Attributes {
+		attributeColumns[name] = column(columnName)
+		used[attributeColumns[name]] = true
+	}
+
+	if mapping.Extra {
+		for index, name := range header {
+			if !used[index] && name != "" {
+				attributeColumns[name] = index
+			}
+		}
+	}
+
+	var rows []csvRowOutput
+	var secrets []string
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		row := csvRowOutput{Attributes: map[string]string{}}
+
+		if parseError, ok := err.(*csv.ParseError); ok {
+			row.Row = parseError.StartLine
+			row.Error = parseError.Err.Error()
+		} else if err != nil {
+			fail(exitIO, "cannot read csv file: %v", err)
+		} else {
+			row.Row, _ = reader.FieldPos(0)
+			row.Label, row.Attributes, row.Error = csvRecord(record, header,
+				labelColumn, secretColumn, attributeColumns)
+		}
+
+		var secret string
+		if row.Error == "" {
+			secret = record[secretColumn]
+		}
+
+		rows = append(rows, row)
+		secrets = append(secrets, secret)
+	}
+
+	return rows, secrets
+}
+
+// csvRecord returns label and lookup attributes of a csv record or an error
+func csvRecord(record []string, header []string, labelColumn int, secretColumn int,
+	attributeColumns map[string]int) (string, map[string]string, string) {
+
+	attributes := make(map[string]string)
+
+	if len(record) != len(header) {
+		return "", attributes, fmt.Sprintf("expected %d columns, got %d", len(header), len(record))
+	}
+
+	if record[labelColumn] == "" {
+		return "", attributes, "empty label"
+	}
+
+	if record[secretColumn] == "" {
+		return record[labelColumn], attributes, "empty secret"
+	}
+
+	for name, index := range attributeColumns {
+		if record[index] != "" {
+			attributes[name] = record[index]
+		}
+	}
+
+	return record[labelColumn], attributes, ""
+}
+
+// formatAttributes returns sorted name=value pairs of attributes
+func formatAttributes(attributes map[string]string) string {
+
+	pairs := make([]string, 0, len(attributes))
+
+	for name, value := range attributes {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/kdbx"
+)
+
+func init() {
+	importCmd.AddCommand(importKdbxCmd)
+	importKdbxCmd.Flags().StringP("file", "f", "", "KeePass database file (required)")
+	importKdbxCmd.Flags().StringP("key-file", "k", "", "KeePass key file")
+	importKdbxCmd.Flags().StringP("collection", "c", "default", "collection alias or path of root group entries")
+	importKdbxCmd.Flags().String("on-duplicate", duplicateSkip, "item with the same attributes: skip, replace or rename")
+	importKdbxCmd.MarkFlagRequired("file")
+}
+
+// standard fields of KeePass entries stored as lookup attributes
+var kdbxAttributes = []string{"Title", "UserName", "URL"}
+
+var importKdbxCmd = &cobra.Command{
+	Use:   "kdbx --file F [--key-file K]",
+	Short: "Import a KeePass (KDBX 4) database",
+	Long: `Import entries of a KeePass database in KDBX 4 format (KeePass 2.x,
+KeePassXC...). Database password is read from standard input, it can be empty
+if a key file is used. Entries of root group are stored in --collection, every
+other group becomes a collection labeled by its path (i.e. 'Email / Work').
+Recycle bin is not imported. Each entry becomes an item labeled by its title
+with password as secret and title, username, URL and custom fields as lookup
+attributes. Notes and protected custom fields are not imported.
+Example:
+
+  echo -n 'P@ssw0rd' | secretservice import kdbx --file vault.kdbx`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		file, _ := cmd.Flags().GetString("file")
+		keyFile, _ := cmd.Flags().GetString("key-file")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+
+		db := readKdbx(file, keyFile, readStdin("database password: "))
+
+		imp := newImporter(onDuplicate)
+		var warnings []string
+
+		var importGroup func(group *kdbx.Group, path []string)
+		importGroup = func(group *kdbx.Group, path []string) {
+
+			if group.RecycleBin {
+				return
+			}
+
+			if len(group.Entries) > 0 {
+				var collection *client.Collection
+				if len(path) == 0 {
+					collection = resolveCollection(imp.client, collectionName)
+				} else {
+					collection = imp.collection(strings.Join(path, " / "))
+				}
+				for _, entry := range group.Entries {
+					warnings = append(warnings, importKdbxEntry(imp, collection, entry)...)
+				}
+			}
+
+			for _, child := range group.Groups {
+				importGroup(child, append(append([]string{}, path...), child.Name))
+			}
+		}
+
+		importGroup(db.Root, nil)
+		imp.summary(warnings)
+	},
+}
+
+// readKdbx decrypts a KeePass database or exits
+func readKdbx(file string, keyFile string, password string) *kdbx.Database {
+
+	var keyFileContent []byte
+
+	if keyFile != "" {
+		content, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			fail(exitNotFound, "cannot read key file: %v", err)
+		}
+		keyFileContent = content
+	}
+
+	key, err := kdbx.NewKey(password, keyFileContent)
+
+	if err != nil {
+		fail(exitUsage, "%v", err)
+	}
+
+	dbFile, err := os.Open(file)
+
+	if err != nil {
+		fail(exitNotFound, "cannot open database: %v", err)
+	}
+
+	defer dbFile.Close()
+
+	db, err := kdbx.Read(dbFile, key)
+
+	if err != nil {
+		if errors.Is(err, kdbx.ErrInvalidKey) || errors.Is(err, kdbx.ErrNotKdbx) {
+			fail(exitUsage, "cannot read '%s': %v", file, err)
+		}
+		fail(exitIO, "cannot read '%s': %v", file, err)
+	}
+
+	return db
+}
+
+// importKdbxEntry stores a KeePass entry in collection and returns warnings
+// about fields which are not imported
+func importKdbxEntry(imp *importer, collection *client.Collection, entry *kdbx.Entry) []string {
+
+	label := entry.Get("Title")
+
+	if label == "" {
+		label = "Untitled"
+	}
+
+	lookupAttributes := make(map[string]string)
+	var warnings []string
+
+	for _, field := range entry.Fields {
+		switch {
+		case field.Key == "Password":
+		case field.Key == "Notes":
+			if field.Value != "" {
+				warnings = append(warnings, fmt.Sprintf("'%s': notes are not imported", label))
+			}
+		case field.Value == "":
+		case field.Protected && !isKdbxAttribute(field.Key):
+			warnings = append(warnings, fmt.Sprintf("'%s': protected field '%s' is not imported", label, field.Key))
+		default:
+			lookupAttributes[field.Key] = field.Value
+		}
+	}
+
+	imp.store(collection, label, entry.Get("Password"), lookupAttributes, "")
+
+	return warnings
+}
+
+// isKdbxAttribute returns true if key is a standard field stored as
+// lookup attribute
+func isKdbxAttribute(key string) bool {
+	for _, attribute := range kdbxAttributes {
+		if attribute == key {
+			return true
+		}
+	}
+	return false
+}
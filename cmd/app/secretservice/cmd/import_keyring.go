@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/keyring"
+)
+
+func init() {
+	importCmd.AddCommand(importKeyringCmd)
+	importKeyringCmd.Flags().Bool("login-default", false, "import 'login.keyring' to 'default' collection")
+	importKeyringCmd.Flags().String("on-duplicate", duplicateSkip, "item with the same attributes: skip, replace or rename")
+}
+
+var importKeyringCmd = &cobra.Command{
+	Use:   "keyring [file...]",
+	Short: "Import gnome-keyring keyrings",
+	Long: `Import gnome-keyring keyring files (default ~/.local/share/keyrings/*.keyring),
+both encrypted with the keyring password and unencrypted. Each keyring becomes
+a collection labeled by keyring name with its items, labels, lookup attributes
+and creation and modification times. With --login-default 'login.keyring' is
+imported to 'default' collection. Passwords of encrypted keyrings are asked for
+each keyring on a terminal, otherwise one password read from standard input is
+used for all of them.
+Example:
+
+  secretservice import keyring --login-default
+  echo -n 'P@ssw0rd' | secretservice import keyring ~/.local/share/keyrings/login.keyring`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		loginDefault, _ := cmd.Flags().GetBool("login-default")
+		onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+
+		files := args
+
+		if len(files) == 0 {
+			files = defaultKeyringFiles()
+		}
+
+		keyrings := readKeyrings(files)
+		imp := newImporter(onDuplicate)
+
+		for i, file := range files {
+
+			var collection *client.Collection
+
+			if loginDefault && filepath.Base(file) == "login.keyring" {
+				collection = resolveCollection(imp.client, "default")
+			} else {
+				label := keyrings[i].Name
+				if label == "" {
+					label = strings.TrimSuffix(filepath.Base(file), ".keyring")
+				}
+				collection = imp.collection(label)
+			}
+
+			for _, item := range keyrings[i].Items {
+				importKeyringItem(imp, collection, item)
+			}
+		}
+
+		imp.summary(nil)
+	},
+}
+
+// defaultKeyringFiles returns keyring files of gnome-keyring or exits
+func defaultKeyringFiles() []string {
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			fail(exitIO, "cannot find user home: %v", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dataHome, "keyrings", "*.keyring"))
+
+	if len(files) == 0 {
+		fail(exitNotFound, "no keyring in '%s'", filepath.Join(dataHome, "keyrings"))
+	}
+
+	return files
+}
+
+// readKeyrings reads keyring files asking passwords of encrypted ones, or exits
+func readKeyrings(files []string) []*keyring.Keyring {
+
+	var keyrings []*keyring.Keyring
+	var password *string
+
+	for _, file := range files {
+
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			fail(exitNotFound, "cannot read keyring: %v", err)
+		}
+
+		var keyringPassword string
+
+		if keyring.IsEncrypted(data) {
+			if password == nil || isStdinTerminal() {
+				input := readStdin(fmt.Sprintf("password of '%s': ", filepath.Base(file)))
+				password = &input
+			}
+			keyringPassword = *password
+		}
+
+		result, err := keyring.Read(bytes.NewReader(data), keyringPassword)
+
+		if err != nil {
+			if err == keyring.ErrInvalidPassword || err == keyring.ErrNotKeyring {
+				fail(exitUsage, "cannot read '%s': %v", file, err)
+			}
+			fail(exitIO, "cannot read '%s': %v", file, err)
+		}
+
+		keyrings = append(keyrings, result)
+	}
+
+	return keyrings
+}
+
+// importKeyringItem stores a keyring item in collection keeping its times
+func importKeyringItem(imp *importer, collection *client.Collection, item *keyring.Item) {
+
+	label := item.Label
+
+	if label == "" {
+		label = "Untitled"
+	}
+
+	// libsecret items have their schema as attribute, older ones only a type
+	itemType := ""
+
+	if _, ok := item.Attributes["xdg:schema"]; !ok && item.Type != "org.freedesktop.Secret.Generic" {
+		itemType = item.Type
+	}
+
+	created := imp.store(collection, label, string(item.Secret), item.Attributes, itemType)
+
+	if created == nil || item.Created.IsZero() {
+		return
+	}
+
+	modified := item.Modified
+
+	if modified.Before(item.Created) {
+		modified = item.Created
+	}
+
+	if err := imp.client.SetItemTimes(created.ObjectPath, uint64(item.Created.Unix()),
+		uint64(modified.Unix())); err != nil {
+		fail(exitCode(err), "cannot set times of '%s': %v", created.ObjectPath, err)
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock secretserviced",
+	Long: `Lock all collections at once and seal all secrets with master password.
+Secrets cannot be retrieved until service is unlocked by 'unlock' command`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if err := newClient().ServiceLock(); err != nil {
+			fail(exitCode(err), "locking service failed: %v", err)
+		}
+
+		printStatus("secretserviced is locked")
+	},
+}
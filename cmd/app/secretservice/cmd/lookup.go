@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lookupCmd)
+}
+
+var lookupCmd = &cobra.Command{
+	Use:   "lookup attribute value...",
+	Short: "Print a secret",
+	Long: `Print the secret of the first unlocked item matching given lookup attributes.
+Example:
+
+  secretservice lookup service github user joe`,
+	Args: attributeArgs,
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+		unlocked, locked := searchItems(ssClient, attributes(args))
+
+		if len(unlocked) == 0 {
+			if len(locked) > 0 {
+				fail(exitLocked, "matching item is locked: %s", locked[0])
+			}
+			fail(exitNotFound, "no matching item")
+		}
+
+		session := openSession(ssClient)
+		item := loadItem(ssClient, unlocked[0])
+		secret := readSecret(session, item)
+
+		// text output is the bare secret (newline only on terminal)
+		printValue(newItemOutput(item, &secret), func() {
+			if isTerminal() {
+				fmt.Println(secret)
+			} else {
+				fmt.Print(secret)
+			}
+		})
+	},
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+}
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Print secret service events as they happen",
+	Long: `Print a timestamped stream of collection, item and property changes
+(org.freedesktop.Secret.Service, org.freedesktop.Secret.Collection and
+ir.remisa.SecretService signals and PropertiesChanged) until interrupted.
+With '--output json' each event is printed as a JSON line. Secrets are
+never printed since they are not part of any signal or property.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		events, cancel, err := newClient().Subscribe()
+
+		if err != nil {
+			fail(exitDbus, "cannot watch secretserviced signals: %v", err)
+		}
+
+		defer cancel()
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					fail(exitDbus, "connection to session dbus is lost")
+				}
+				printEvent(newEventOutput(event))
+			case <-interrupt:
+				return
+			}
+		}
+	},
+}
+
+// eventOutput is the stable (json/yaml) schema of a monitored event
+type eventOutput struct {
+	Time        string                 `json:"time" yaml:"time"`
+	Interface   string                 `json:"interface" yaml:"interface"`
+	Signal      string                 `json:"signal" yaml:"signal"`
+	Path        string                 `json:"path" yaml:"path"`
+	Object      string                 `json:"object,omitempty" yaml:"object,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Invalidated []string               `json:"invalidated,omitempty" yaml:"invalidated,omitempty"`
+}
+
+// newEventOutput converts an event to its output schema
+func newEventOutput(event client.Event) eventOutput {
+
+	index := strings.LastIndex(event.Signal, ".")
+
+	output := eventOutput{
+		Time:        event.Time.Format(time.RFC3339Nano),
+		Interface:   event.Signal[:index],
+		Signal:      event.Signal[index+1:],
+		Path:        string(event.Path),
+		Object:      string(event.Object),
+		Invalidated: event.Invalidated,
+	}
+
+	// PropertiesChanged is emitted by 'org.freedesktop.DBus.Properties'
+	// interface but it is about properties of another interface
+	if event.Interface != "" {
+		output.Interface = event.Interface
+	}
+
+	if len(event.Changed) > 0 {
+		output.Properties = make(map[string]interface{})
+		for name, value := range event.Changed {
+			output.Properties[name] = value.Value()
+		}
+	}
+
+	return output
+}
+
+// printEvent prints an event as a text line, a JSON line or a yaml document
+func printEvent(event eventOutput) {
+
+	switch outputFormat {
+	case outputJSON:
+		line, err := json.Marshal(event)
+		if err != nil {
+			fail(exitUsage, "cannot encode output: %v", err)
+		}
+		fmt.Println(string(line))
+	case outputYAML:
+		document, err := yaml.Marshal(event)
+		if err != nil {
+			fail(exitUsage, "cannot encode output: %v", err)
+		}
+		fmt.Print("---\n" + string(document))
+	default:
+		line := event.Time + " " + event.Signal + " " + event.Object
+
+		if event.Signal == "PropertiesChanged" {
+			names := make([]string, 0, len(event.Properties))
+			for name := range event.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				line += fmt.Sprintf(" %s=%v", name, event.Properties[name])
+			}
+
+			for _, name := range event.Invalidated {
+				line += " " + name + "=?"
+			}
+		}
+
+		if event.Object == "" {
+			line += event.Path
+		}
+
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(otpCmd)
+}
+
+var otpCmd = &cobra.Command{
+	Use:   "otp attribute value...",
+	Short: "Print current TOTP code of an otp item",
+	Long: `Print current one-time password (RFC 6238) of the first unlocked item
+matching given lookup attributes whose secret is an 'otpauth://totp/...' URI.
+The code is computed by secretserviced so the seed never leaves the daemon.
+Remaining validity of the code is printed to standard error.
+Store otp items with 'store', i.e.:
+
+  echo -n 'otpauth://totp/GitHub:joe?secret=JBSWY3DPEHPK3PXP&issuer=GitHub' |
+    secretservice store --label 'GitHub 2FA' service github user joe type otp
+  secretservice otp service github type otp`,
+	Args: attributeArgs,
+	Run: func(_ *cobra.Command, args []string) {
+
+		ssClient := newClient()
+		unlocked, locked := searchItems(ssClient, attributes(args))
+
+		if len(unlocked) == 0 {
+			if len(locked) > 0 {
+				fail(exitLocked, "matching item is locked: %s", locked[0])
+			}
+			fail(exitNotFound, "no matching item")
+		}
+
+		// first matching item which is an otp item
+		for _, itemPath := range unlocked {
+
+			code, remaining, err := ssClient.OtpCode(itemPath)
+
+			if err != nil {
+				continue
+			}
+
+			output := otpOutput{Path: string(itemPath), Code: code, Remaining: remaining}
+
+			printValue(output, func() {
+				fmt.Println(code)
+				if isTerminal() {
+					fmt.Fprintf(os.Stderr, "valid for %ds\n", remaining)
+				}
+			})
+			return
+		}
+
+		fail(exitNotFound, "no matching item has an otpauth:// secret")
+	},
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/yousefvand/secret-service/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats of '--output' flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputFormat is the value of '--output' flag
+var outputFormat string = outputText
+
+// itemOutput is the stable (json/yaml) schema of an item
+type itemOutput struct {
+	Path       string            `json:"path" yaml:"path"`
+	Collection string            `json:"collection" yaml:"collection"`
+	Label      string            `json:"label" yaml:"label"`
+	Type       string            `json:"type" yaml:"type"`
+	Locked     bool              `json:"locked" yaml:"locked"`
+	Created    uint64            `json:"created" yaml:"created"`
+	Modified   uint64            `json:"modified" yaml:"modified"`
+	Attributes map[string]string `json:"attributes" yaml:"attributes"`
+	Secret     *string           `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// collectionOutput is the stable (json/yaml) schema of a collection
+type collectionOutput struct {
+	Path     string   `json:"path" yaml:"path"`
+	Label    string   `json:"label" yaml:"label"`
+	Aliases  []string `json:"aliases" yaml:"aliases"`
+	Locked   bool     `json:"locked" yaml:"locked"`
+	Items    int      `json:"items" yaml:"items"`
+	Created  uint64   `json:"created" yaml:"created"`
+	Modified uint64   `json:"modified" yaml:"modified"`
+}
+
+// statusOutput is the stable (json/yaml) schema of a command result
+type statusOutput struct {
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// daemonStatusOutput is the stable (json/yaml) schema of daemon status
+type daemonStatusOutput struct {
+	Version     string         `json:"version" yaml:"version"`
+	Started     uint64         `json:"started" yaml:"started"`
+	Uptime      uint64         `json:"uptime" yaml:"uptime"`
+	Locked      bool           `json:"locked" yaml:"locked"`
+	Config      configOutput   `json:"config" yaml:"config"`
+	Database    databaseOutput `json:"database" yaml:"database"`
+	Collections uint32         `json:"collections" yaml:"collections"`
+	Items       uint32         `json:"items" yaml:"items"`
+	Sessions    uint32         `json:"sessions" yaml:"sessions"`
+	MemoryMB    uint64         `json:"memoryMB" yaml:"memoryMB"`
+	Goroutines  uint32         `json:"goroutines" yaml:"goroutines"`
+}
+
+// configOutput is the stable (json/yaml) schema of daemon config summary
+type configOutput struct {
+	AllowDbExport bool   `json:"allowDbExport" yaml:"allowDbExport"`
+	Portal        bool   `json:"portal" yaml:"portal"`
+	KWallet       bool   `json:"kwallet" yaml:"kwallet"`
+	Schemas       uint32 `json:"schemas" yaml:"schemas"`
+}
+
+// databaseOutput is the stable (json/yaml) schema of daemon database status
+type databaseOutput struct {
+	Path          string `json:"path" yaml:"path"`
+	Size          uint64 `json:"size" yaml:"size"`
+	Encryption    string `json:"encryption" yaml:"encryption"`
+	LastSave      uint64 `json:"lastSave" yaml:"lastSave"`
+	LastSaveError string `json:"lastSaveError" yaml:"lastSaveError"`
+}
+
+// checkOutput is the stable (json/yaml) schema of a 'doctor' check
+type checkOutput struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+	Fix     string `json:"fix,omitempty" yaml:"fix,omitempty"`
+}
+
+// configValidationOutput is the stable (json/yaml) schema of a config validation
+type configValidationOutput struct {
+	File     string   `json:"file" yaml:"file"`
+	Valid    bool     `json:"valid" yaml:"valid"`
+	Problems []string `json:"problems" yaml:"problems"`
+}
+
+// otpOutput is the stable (json/yaml) schema of a one-time password
+type otpOutput struct {
+	Path      string `json:"path" yaml:"path"`
+	Code      string `json:"code" yaml:"code"`
+	Remaining uint32 `json:"remaining" yaml:"remaining"`
+}
+
+// backupOutput is the stable (json/yaml) schema of a backup
+type backupOutput struct {
+	File           string `json:"file" yaml:"file"`
+	Size           int64  `json:"size" yaml:"size"`
+	Version        string `json:"version" yaml:"version"`
+	ServiceVersion string `json:"serviceVersion" yaml:"serviceVersion"`
+	Created        uint64 `json:"created" yaml:"created"`
+	Encrypted      bool   `json:"encrypted" yaml:"encrypted"`
+	Collections    int    `json:"collections" yaml:"collections"`
+	Items          int    `json:"items" yaml:"items"`
+	Checksum       string `json:"checksum" yaml:"checksum"`
+}
+
+// csvRowOutput is the stable (json/yaml) schema of a csv row preview
+// (secret is never printed)
+type csvRowOutput struct {
+	Row        int               `json:"row" yaml:"row"`
+	Label      string            `json:"label" yaml:"label"`
+	Attributes map[string]string `json:"attributes" yaml:"attributes"`
+	Error      string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// errorOutput is the stable (json/yaml) schema of an error (written to stderr)
+type errorOutput struct {
+	Error string `json:"error" yaml:"error"`
+	Code  int    `json:"code" yaml:"code"`
+}
+
+// validateOutput makes sure '--output' flag has a supported value
+func validateOutput() error {
+	switch outputFormat {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format '%s' (text, json or yaml)", outputFormat)
+	}
+}
+
+// newItemOutput converts an item to its output schema. Nil secret means not shown
+func newItemOutput(item *client.Item, secret *string) itemOutput {
+
+	attributes := map[string]string{}
+	for k, v := range item.LookupAttributes {
+		attributes[k] = v
+	}
+
+	return itemOutput{
+		Path:       string(item.ObjectPath),
+		Collection: string(item.Parent.ObjectPath),
+		Label:      item.Label,
+		Type:       item.Type,
+		Locked:     item.Locked,
+		Created:    item.Created,
+		Modified:   item.Modified,
+		Attributes: attributes,
+		Secret:     secret,
+	}
+}
+
+// printValue prints value in json or yaml format, text format is printed by text function
+func printValue(value interface{}, text func()) {
+
+	switch outputFormat {
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			fail(exitUsage, "cannot encode output: %v", err)
+		}
+	case outputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			fail(exitUsage, "cannot encode output: %v", err)
+		}
+		encoder.Close()
+	default:
+		text()
+	}
+}
+
+// printStatus prints a successful command result
+func printStatus(message string) {
+	printValue(statusOutput{Status: "ok", Message: message}, func() {
+		fmt.Println(message)
+	})
+}
+
+// printItems prints items (the way 'secret-tool search' does in text format)
+func printItems(items []itemOutput) {
+	printValue(items, func() {
+		for _, item := range items {
+			printItemText(item)
+		}
+	})
+}
+
+// printItemText prints an item the way 'secret-tool search' does
+func printItemText(item itemOutput) {
+
+	fmt.Printf("[%s]\n", item.Path)
+	fmt.Printf("label = %s\n", item.Label)
+
+	if item.Secret != nil {
+		fmt.Printf("secret = %s\n", *item.Secret)
+	}
+
+	if item.Locked {
+		fmt.Println("locked = true")
+	}
+
+	fmt.Printf("created = %s\n", formatEpoch(item.Created))
+	fmt.Printf("modified = %s\n", formatEpoch(item.Modified))
+
+	if item.Type != "" {
+		fmt.Printf("schema = %s\n", item.Type)
+	}
+
+	keys := make([]string, 0, len(item.Attributes))
+	for k := range item.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Printf("attribute.%s = %s\n", k, item.Attributes[k])
+	}
+}
@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
-	"github.com/yousefvand/secret-service/pkg/client"
 )
 
 func init() {
@@ -15,15 +12,19 @@ var pingCmd = &cobra.Command{
 	Use:   "ping",
 	Short: "Ping secretserviced daemon",
 	Long:  `Send ping signal to secretserviced daemon and wait for pong response`,
+	Args:  cobra.NoArgs,
 	Run: func(_ *cobra.Command, _ []string) {
 
-		ssClient, _ := client.New()
-		response, _ := ssClient.SecretServiceCommand("ping", "")
+		response, err := newClient().SecretServiceCommand("ping", "")
+
+		if err != nil {
+			fail(exitDbus, "secretserviced is not responsive: %v", err)
+		}
 
-		if response == "pong" {
-			fmt.Println("secretserviced is up and responsive")
-		} else {
-			fmt.Println("Something is wrong with secretserviced")
+		if response != "pong" {
+			fail(exitDbus, "Something is wrong with secretserviced, got: '%s'", response)
 		}
+
+		printStatus("secretserviced is up and responsive")
 	},
 }
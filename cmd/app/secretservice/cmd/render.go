@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringP("input", "i", "-", "template file ('-' for stdin)")
+	renderCmd.Flags().StringP("out", "o", "-", "rendered file ('-' for stdout)")
+	renderCmd.Flags().Bool("strict", false, "fail on missing or locked items")
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render -i template -o file",
+	Short: "Render a template with secrets",
+	Long: `Render a Go text/template replacing secret placeholders with secrets of
+the first unlocked item matching given lookup attributes. Rendered file is
+written with 0600 permissions. Missing or locked items render as empty with
+a warning, or fail the command if '--strict' is set.
+Example:
+
+  # app.conf.tmpl
+  password = {{ secret "service=smtp" "user=bot" }}
+
+  secretservice render -i app.conf.tmpl -o app.conf --strict`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		input, _ := cmd.Flags().GetString("input")
+		output, _ := cmd.Flags().GetString("out")
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		name := filepath.Base(input)
+		var text []byte
+		var err error
+
+		if input == "-" {
+			name = "stdin"
+			text, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			text, err = ioutil.ReadFile(input)
+		}
+
+		if err != nil {
+			fail(exitIO, "cannot read template: %v", err)
+		}
+
+		r := &renderer{strict: strict, secrets: make(map[string]string)}
+
+		tmpl, err := template.New(name).
+			Funcs(template.FuncMap{"secret": r.secret}).Parse(string(text))
+
+		if err != nil {
+			fail(exitUsage, "invalid template: %v", err)
+		}
+
+		// render in memory so a failure never leaves a partial file
+		var rendered bytes.Buffer
+
+		if err := tmpl.Execute(&rendered, nil); err != nil {
+			if r.code == exitOK {
+				r.code = exitUsage
+			}
+			fail(r.code, "cannot render template: %v", err)
+		}
+
+		if output == "-" {
+			os.Stdout.Write(rendered.Bytes())
+			return
+		}
+
+		if err := writePrivateFile(output, rendered.Bytes()); err != nil {
+			fail(exitIO, "cannot write '%s': %v", output, err)
+		}
+	},
+}
+
+// renderer resolves 'secret' placeholders of a template
+type renderer struct {
+	strict  bool
+	code    int // exit code of the failed placeholder
+	client  *client.Client
+	session *client.Session
+	secrets map[string]string // already resolved placeholders
+}
+
+// secret returns the secret of the first unlocked item matching
+// given 'attribute=value' arguments
+func (r *renderer) secret(args ...string) (string, error) {
+
+	if len(args) == 0 {
+		r.code = exitUsage
+		return "", fmt.Errorf("expected \"attribute=value\" arguments")
+	}
+
+	lookupAttributes := make(map[string]string)
+
+	for _, arg := range args {
+		separator := strings.Index(arg, "=")
+		if separator < 1 {
+			r.code = exitUsage
+			return "", fmt.Errorf("expected \"attribute=value\", got %q", arg)
+		}
+		lookupAttributes[arg[:separator]] = arg[separator+1:]
+	}
+
+	sorted := append([]string{}, args...)
+	sort.Strings(sorted)
+	key := strings.Join(sorted, "\x00")
+
+	if secret, ok := r.secrets[key]; ok {
+		return secret, nil
+	}
+
+	if r.client == nil {
+		r.client = newClient()
+	}
+
+	unlocked, locked := searchItems(r.client, lookupAttributes)
+
+	if len(unlocked) == 0 {
+
+		r.code = exitNotFound
+		message := fmt.Sprintf("no matching item for %q", strings.Join(args, " "))
+
+		if len(locked) > 0 {
+			r.code = exitLocked
+			message = fmt.Sprintf("matching item is locked: %s", locked[0])
+		}
+
+		if r.strict {
+			return "", fmt.Errorf("%s", message)
+		}
+
+		fmt.Fprintln(os.Stderr, "warning: "+message)
+		r.code = exitOK
+		r.secrets[key] = ""
+		return "", nil
+	}
+
+	if r.session == nil {
+		r.session = openSession(r.client)
+	}
+
+	secret := readSecret(r.session, loadItem(r.client, unlocked[0]))
+	r.secrets[key] = secret
+
+	return secret, nil
+}
+
+// writePrivateFile replaces given file with data readable by owner only
+func writePrivateFile(name string, data []byte) error {
+
+	// temporary files are created with 0600 permissions
+	file, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), name)
+}
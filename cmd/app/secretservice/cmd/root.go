@@ -23,12 +23,18 @@ will export an unencrypted version of secret-service database.
 - Remisa Yousefvand
 - Jürgen Hötzel
 - scrouthtv`,
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		return validateOutput()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	// commands exit by themselves, errors here are wrong usage (flags, arguments)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitUsage)
+	}
 }
 
 func init() {
@@ -39,6 +45,8 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.secret-service.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&plainSession, "plain", false, "use a plain (non-encrypted) session")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "output format: text, json or yaml")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().BoolP("all", "a", false, "show all matching items")
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search [--all] attribute value...",
+	Short: "Search for items",
+	Long: `Show the first (or all) items matching given lookup attributes.
+Secrets of locked items are not shown. Example:
+
+  secretservice search --all service github`,
+	Args: attributeArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		all, _ := cmd.Flags().GetBool("all")
+
+		ssClient := newClient()
+		unlocked, locked := searchItems(ssClient, attributes(args))
+
+		if len(unlocked)+len(locked) == 0 {
+			fail(exitNotFound, "no matching item")
+		}
+
+		session := openSession(ssClient)
+		matches := append(append([]dbus.ObjectPath{}, unlocked...), locked...)
+		items := []itemOutput{}
+
+		for i, itemPath := range matches {
+
+			if i > 0 && !all {
+				break
+			}
+
+			item := loadItem(ssClient, itemPath)
+
+			if i < len(unlocked) {
+				secret := readSecret(session, item)
+				items = append(items, newItemOutput(item, &secret))
+			} else {
+				item.Locked = true
+				items = append(items, newItemOutput(item, nil))
+			}
+		}
+
+		printItems(items)
+	},
+}
+
+// formatEpoch formats a unix time as 'YYYY-MM-DD hh:mm:ss'
+func formatEpoch(epoch uint64) string {
+	return time.Unix(int64(epoch), 0).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"golang.org/x/term"
+)
+
+// plainSession is true if '--plain' flag is set
+var plainSession bool
+
+// newClient connects to secretserviced or exits
+func newClient() *client.Client {
+
+	ssClient, err := client.New()
+
+	if err != nil {
+		fail(exitDbus, "cannot connect to secretserviced: %v", err)
+	}
+
+	return ssClient
+}
+
+// openSession opens an encrypted session (plain session if '--plain' is set) or exits
+func openSession(ssClient *client.Client) *client.Session {
+
+	algorithm := client.Dh_ietf1024_sha256_aes128_cbc_pkcs7
+
+	if plainSession {
+		algorithm = client.Plain
+	}
+
+	session, err := ssClient.OpenSession(algorithm)
+
+	if err != nil {
+		fail(exitDbus, "cannot open session: %v", err)
+	}
+
+	return session
+}
+
+// attributeArgs validates 'attribute value' pairs
+func attributeArgs(_ *cobra.Command, args []string) error {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return fmt.Errorf("expected 'attribute value' pairs, got %d argument(s)", len(args))
+	}
+	return nil
+}
+
+// attributes converts 'attribute value' pairs to lookup attributes
+func attributes(args []string) map[string]string {
+	result := make(map[string]string)
+	for i := 0; i < len(args); i += 2 {
+		result[args[i]] = args[i+1]
+	}
+	return result
+}
+
+// searchItems returns sorted paths of unlocked and locked items
+// matching given lookup attributes or exits
+func searchItems(ssClient *client.Client,
+	attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath) {
+
+	unlocked, locked, err := ssClient.SearchItems(attributes)
+
+	if err != nil {
+		fail(exitCode(err), "search failed: %v", err)
+	}
+
+	sort.Slice(unlocked, func(i, j int) bool { return unlocked[i] < unlocked[j] })
+	sort.Slice(locked, func(i, j int) bool { return locked[i] < locked[j] })
+
+	return unlocked, locked
+}
+
+// loadItem loads the item at given path or exits
+func loadItem(ssClient *client.Client, itemPath dbus.ObjectPath) *client.Item {
+
+	item, err := ssClient.LoadItem(itemPath)
+
+	if err != nil {
+		fail(exitDbus, "cannot read item: %v", err)
+	}
+
+	return item
+}
+
+// readSecret returns the plain secret of given item or exits
+func readSecret(session *client.Session, item *client.Item) string {
+
+	secretApi, err := item.GetSecret(session.ObjectPath)
+
+	if err != nil {
+		fail(exitCode(err), "cannot read secret of '%s': %v", item.ObjectPath, err)
+	}
+
+	plainSecret, err := session.DecryptSecret(secretApi)
+
+	if err != nil {
+		fail(exitDbus, "cannot decrypt secret of '%s': %v", item.ObjectPath, err)
+	}
+
+	return string(plainSecret)
+}
+
+// readStdin returns standard input without the trailing newline. On a
+// terminal given prompt is shown and only one line is read without echo.
+func readStdin(prompt string) string {
+
+	var input []byte
+	var err error
+
+	if isStdinTerminal() {
+		fmt.Fprint(os.Stderr, prompt)
+		input, err = term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+	} else {
+		input, err = ioutil.ReadAll(os.Stdin)
+	}
+
+	if err != nil && len(input) == 0 {
+		fail(exitUsage, "cannot read standard input: %v", err)
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(string(input), "\n"), "\r")
+}
+
+// readStdinTwice is readStdin which asks again on a terminal and exits if
+// inputs don't match
+func readStdinTwice(prompt string, again string) string {
+
+	input := readStdin(prompt)
+
+	if isStdinTerminal() && readStdin(again) != input {
+		fail(exitUsage, "inputs do not match")
+	}
+
+	return input
+}
+
+// isStdinTerminal returns true if standard input is a terminal
+func isStdinTerminal() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// isTerminal returns true if standard output is a terminal
+func isTerminal() bool {
+	stat, err := os.Stdout.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
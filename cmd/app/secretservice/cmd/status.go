@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show secretserviced status",
+	Long: `Show secretserviced version, uptime, config summary, database path, size,
+encryption and last save, number of collections, items and sessions,
+memory usage and number of goroutines`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		status, err := newClient().Status()
+
+		if err != nil {
+			fail(exitDbus, "cannot read secretserviced status: %v", err)
+		}
+
+		output := newDaemonStatusOutput(status)
+
+		printValue(output, func() {
+
+			lastSave := "never"
+			if output.Database.LastSave != 0 {
+				lastSave = formatEpoch(output.Database.LastSave)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(writer, "version:\t%s\n", output.Version)
+			fmt.Fprintf(writer, "uptime:\t%s (since %s)\n",
+				time.Duration(output.Uptime)*time.Second, formatEpoch(output.Started))
+			fmt.Fprintf(writer, "locked:\t%t\n", output.Locked)
+			fmt.Fprintf(writer, "database:\t%s\n", output.Database.Path)
+			fmt.Fprintf(writer, "database size:\t%d bytes\n", output.Database.Size)
+			fmt.Fprintf(writer, "encryption:\t%s\n", output.Database.Encryption)
+			fmt.Fprintf(writer, "last save:\t%s\n", lastSave)
+			if output.Database.LastSaveError != "" {
+				fmt.Fprintf(writer, "last save error:\t%s\n", output.Database.LastSaveError)
+			}
+			fmt.Fprintf(writer, "collections:\t%d\n", output.Collections)
+			fmt.Fprintf(writer, "items:\t%d\n", output.Items)
+			fmt.Fprintf(writer, "sessions:\t%d\n", output.Sessions)
+			fmt.Fprintf(writer, "config:\tallowDbExport=%t portal=%t kwallet=%t schemas=%d\n",
+				output.Config.AllowDbExport, output.Config.Portal,
+				output.Config.KWallet, output.Config.Schemas)
+			fmt.Fprintf(writer, "memory:\t%d MB\n", output.MemoryMB)
+			fmt.Fprintf(writer, "goroutines:\t%d\n", output.Goroutines)
+			writer.Flush()
+		})
+	},
+}
+
+// newDaemonStatusOutput converts daemon status to its output schema
+func newDaemonStatusOutput(status *client.ServiceStatus) daemonStatusOutput {
+	return daemonStatusOutput{
+		Version: status.Version,
+		Started: status.Started,
+		Uptime:  status.Uptime,
+		Locked:  status.Locked,
+		Config: configOutput{
+			AllowDbExport: status.AllowDbExport,
+			Portal:        status.Portal,
+			KWallet:       status.KWallet,
+			Schemas:       status.Schemas,
+		},
+		Database: databaseOutput{
+			Path:          status.DbPath,
+			Size:          status.DbSize,
+			Encryption:    status.Encryption,
+			LastSave:      status.LastSave,
+			LastSaveError: status.LastSaveError,
+		},
+		Collections: status.Collections,
+		Items:       status.Items,
+		Sessions:    status.Sessions,
+		MemoryMB:    status.Memory,
+		Goroutines:  status.Goroutines,
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"reflect"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/otp"
+)
+
+func init() {
+	rootCmd.AddCommand(storeCmd)
+	storeCmd.Flags().StringP("label", "l", "", "item label (required)")
+	storeCmd.Flags().StringP("collection", "c", "default", "collection alias or path")
+	storeCmd.MarkFlagRequired("label")
+}
+
+var storeCmd = &cobra.Command{
+	Use:   "store --label L attribute value...",
+	Short: "Store a secret",
+	Long: `Store a secret read from standard input with given label and lookup
+attributes. An item with exactly the same attributes in the collection is replaced.
+'otpauth://' URIs are validated and stored as otp items (see 'otp' command).
+Example:
+
+  echo -n 'P@ssw0rd' | secretservice store --label 'github' service github user joe`,
+	Args: attributeArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		label, _ := cmd.Flags().GetString("label")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		lookupAttributes := attributes(args)
+
+		secret := readStdin("secret: ")
+
+		ssClient := newClient()
+		session := openSession(ssClient)
+		collection := resolveCollection(ssClient, collectionName)
+
+		contentType := "text/plain"
+
+		// otpauth URIs are checked and marked to be used by 'otp' command
+		if otp.IsURI(secret) {
+			if _, err := otp.Parse(secret); err != nil {
+				fail(exitUsage, "%v", err)
+			}
+			contentType = otp.ContentType
+		}
+
+		secretApi, err := session.EncryptSecret([]byte(secret), contentType)
+
+		if err != nil {
+			fail(exitDbus, "cannot encrypt secret: %v", err)
+		}
+
+		if item := replaceableItem(ssClient, collection, lookupAttributes); item != nil {
+
+			if err := item.SetSecret(secretApi); err != nil {
+				fail(exitCode(err), "cannot replace secret of '%s': %v", item.ObjectPath, err)
+			}
+
+			if err := item.PropertySetLabel(label); err != nil {
+				fail(exitCode(err), "cannot set label of '%s': %v", item.ObjectPath, err)
+			}
+
+			printStored(item)
+			return
+		}
+
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(label),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(lookupAttributes),
+		}
+
+		item, _, err := collection.CreateItem(properties, secretApi, true)
+
+		if err != nil {
+			fail(exitCode(err), "cannot store secret: %v", err)
+		}
+
+		printStored(item)
+	},
+}
+
+// printStored prints the stored item (without secret), text output is empty
+func printStored(item *client.Item) {
+	printValue(newItemOutput(item, nil), func() {})
+}
+
+// resolveCollection returns collection of given alias or path or exits
+func resolveCollection(ssClient *client.Client, name string) *client.Collection {
+
+	collectionPath := dbus.ObjectPath(name)
+
+	if name == "" || name[0] != '/' {
+		var err error
+		collectionPath, err = ssClient.ReadAlias(name)
+		if err != nil {
+			fail(exitCode(err), "cannot read alias '%s': %v", name, err)
+		}
+	}
+
+	if collectionPath == "/" || !collectionPath.IsValid() {
+		fail(exitNotFound, "no such collection: %s", name)
+	}
+
+	collection, err := ssClient.LoadCollection(collectionPath)
+
+	if err != nil {
+		fail(exitNotFound, "no such collection: %s", name)
+	}
+
+	return collection
+}
+
+// replaceableItem returns the unlocked item of given collection having
+// exactly given lookup attributes, otherwise nil
+func replaceableItem(ssClient *client.Client, collection *client.Collection,
+	lookupAttributes map[string]string) *client.Item {
+
+	unlocked, _ := searchItems(ssClient, lookupAttributes)
+
+	for _, itemPath := range unlocked {
+		item := loadItem(ssClient, itemPath)
+		if item.Parent.ObjectPath == collection.ObjectPath &&
+			reflect.DeepEqual(item.LookupAttributes, lookupAttributes) {
+			return item
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/tui"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"golang.org/x/term"
+)
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse collections and items in a terminal UI",
+	Long: `Full-screen terminal browser of collections and items. Screen is
+refreshed when collections or items change. Keys:
+
+  up/down, j/k, PgUp/PgDn  move
+  /                        incremental search in labels and attributes (Esc clears)
+  r                        reveal/hide secret
+  e                        edit label of item or collection
+  a                        edit attributes (key=value, ...)
+  s                        set secret
+  l                        lock/unlock collection
+  d                        delete item or collection (asks for confirmation)
+  R                        reload
+  q                        quit`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !isTerminal() {
+			fail(exitUsage, "tui needs a terminal")
+		}
+
+		ssClient := newClient()
+		backend := &tuiBackend{client: ssClient, session: openSession(ssClient)}
+
+		if err := tui.Run(backend, watchChanges(ssClient)); err != nil {
+			fail(exitCode(err), "tui failed: %v", err)
+		}
+	},
+}
+
+// watchChanges returns a channel receiving a value whenever
+// collections, items or their properties change
+func watchChanges(ssClient *client.Client) <-chan struct{} {
+
+	// client already watches org.freedesktop.Secret.Service signals
+	for _, dbusInterface := range []string{
+		"org.freedesktop.Secret.Collection",
+		"org.freedesktop.DBus.Properties",
+		"ir.remisa.SecretService",
+	} {
+		ssClient.Connection.AddMatchSignal(
+			dbus.WithMatchInterface(dbusInterface),
+			dbus.WithMatchSender("org.freedesktop.secrets"),
+		)
+	}
+
+	refresh := make(chan struct{}, 1)
+
+	go func() {
+		for range ssClient.SignalChan {
+			// bursts of signals cause a single reload
+			select {
+			case refresh <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return refresh
+}
+
+// tuiBackend implements tui.Backend over dbus
+type tuiBackend struct {
+	client  *client.Client
+	session *client.Session
+}
+
+// Load reads all collections and items (without secrets)
+func (backend *tuiBackend) Load() ([]tui.Collection, error) {
+
+	collectionPaths, err := backend.client.PropertyGetCollections()
+
+	if err != nil {
+		return nil, err
+	}
+
+	collections := []tui.Collection{}
+
+	for _, collectionPath := range collectionPaths {
+
+		properties, err := backend.properties(dbus.ObjectPath(collectionPath),
+			"org.freedesktop.Secret.Collection")
+
+		if err != nil {
+			return nil, err
+		}
+
+		collection := tui.Collection{Path: collectionPath}
+		collection.Label, _ = properties["Label"].Value().(string)
+		collection.Locked, _ = properties["Locked"].Value().(bool)
+		itemPaths, _ := properties["Items"].Value().([]dbus.ObjectPath)
+
+		for _, itemPath := range itemPaths {
+
+			properties, err := backend.properties(itemPath, "org.freedesktop.Secret.Item")
+
+			if err != nil {
+				return nil, err
+			}
+
+			item := tui.Item{Path: string(itemPath)}
+			item.Label, _ = properties["Label"].Value().(string)
+			item.Attributes, _ = properties["Attributes"].Value().(map[string]string)
+			item.Locked, _ = properties["Locked"].Value().(bool)
+			collection.Items = append(collection.Items, item)
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections, nil
+}
+
+// properties returns all properties of given object and interface
+func (backend *tuiBackend) properties(objectPath dbus.ObjectPath,
+	dbusInterface string) (map[string]dbus.Variant, error) {
+
+	properties := make(map[string]dbus.Variant)
+
+	err := backend.client.Connection.Object("org.freedesktop.secrets", objectPath).
+		Call("org.freedesktop.DBus.Properties.GetAll", 0, dbusInterface).Store(&properties)
+
+	if err != nil {
+		return nil, errors.New("cannot read properties of '" + string(objectPath) + "': " + err.Error())
+	}
+
+	return properties, nil
+}
+
+// collection loads given collection. Signals are watched by watchChanges
+// so signals of collections loaded for the first time are discarded.
+func (backend *tuiBackend) collection(collectionPath dbus.ObjectPath) (*client.Collection, error) {
+
+	known := backend.client.HasCollection(collectionPath)
+	collection, err := backend.client.LoadCollection(collectionPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !known {
+		go func() {
+			for range collection.SignalChan {
+			}
+		}()
+	}
+
+	return collection, nil
+}
+
+// item loads given item and its parent collection
+func (backend *tuiBackend) item(itemPath string) (*client.Item, error) {
+
+	if _, err := backend.collection(dbus.ObjectPath(path.Dir(itemPath))); err != nil {
+		return nil, err
+	}
+
+	return backend.client.LoadItem(dbus.ObjectPath(itemPath))
+}
+
+// Secret returns the plain secret of given item
+func (backend *tuiBackend) Secret(itemPath string) (string, error) {
+
+	item, err := backend.item(itemPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	secretApi, err := item.GetSecret(backend.session.ObjectPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := backend.session.DecryptSecret(secretApi)
+
+	return string(secret), err
+}
+
+// SetSecret replaces the secret of given item
+func (backend *tuiBackend) SetSecret(itemPath string, secret string) error {
+
+	item, err := backend.item(itemPath)
+
+	if err != nil {
+		return err
+	}
+
+	secretApi, err := backend.session.EncryptSecret([]byte(secret), "text/plain")
+
+	if err != nil {
+		return err
+	}
+
+	return item.SetSecret(secretApi)
+}
+
+// SetItemLabel changes label of given item
+func (backend *tuiBackend) SetItemLabel(itemPath string, label string) error {
+
+	item, err := backend.item(itemPath)
+
+	if err != nil {
+		return err
+	}
+
+	return item.PropertySetLabel(label)
+}
+
+// SetItemAttributes replaces lookup attributes of given item
+func (backend *tuiBackend) SetItemAttributes(itemPath string, attributes map[string]string) error {
+
+	item, err := backend.item(itemPath)
+
+	if err != nil {
+		return err
+	}
+
+	return item.PropertySetAttributes(attributes)
+}
+
+// DeleteItem deletes given item
+func (backend *tuiBackend) DeleteItem(itemPath string) error {
+
+	item, err := backend.item(itemPath)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = item.Delete()
+
+	return err
+}
+
+// SetCollectionLabel changes label of given collection
+func (backend *tuiBackend) SetCollectionLabel(collectionPath string, label string) error {
+
+	collection, err := backend.collection(dbus.ObjectPath(collectionPath))
+
+	if err != nil {
+		return err
+	}
+
+	return collection.PropertySetLabel(label)
+}
+
+// DeleteCollection deletes given collection and its items
+func (backend *tuiBackend) DeleteCollection(collectionPath string) error {
+
+	collection, err := backend.collection(dbus.ObjectPath(collectionPath))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.Delete()
+
+	return err
+}
+
+// Lock locks given collection
+func (backend *tuiBackend) Lock(collectionPath string) error {
+	_, _, err := backend.client.Lock([]dbus.ObjectPath{dbus.ObjectPath(collectionPath)})
+	return err
+}
+
+// Unlock unlocks given collection. Password protected collections stay locked.
+func (backend *tuiBackend) Unlock(collectionPath string) error {
+
+	unlocked, _, err := backend.client.Unlock([]dbus.ObjectPath{dbus.ObjectPath(collectionPath)})
+
+	if err != nil {
+		return err
+	}
+
+	if len(unlocked) == 0 {
+		return errors.New("collection stays locked (password protected)")
+	}
+
+	return nil
+}
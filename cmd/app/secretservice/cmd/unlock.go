@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock secretserviced",
+	Long: `Unlock a service locked by 'lock' command. Master password
+is read from standard input and sent to service over an encrypted session`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		password := readStdin("master password: ")
+
+		ssClient := newClient()
+		session := openSession(ssClient)
+
+		if err := ssClient.ServiceUnlock(session, password); err != nil {
+			fail(exitCode(err), "unlocking service failed: %v", err)
+		}
+
+		printStatus("secretserviced is unlocked")
+	},
+}
@@ -0,0 +1,278 @@
+// Package dockercredential implements docker credential helper protocol
+// (used by docker, podman and buildah) on top of secret service.
+// Credentials are kept in a dedicated collection with 'server' and
+// 'username' lookup attributes.
+package dockercredential
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+const (
+	// Schema is the libsecret schema (xdg:schema) of docker credentials
+	Schema = "io.docker.Credentials"
+	// CollectionAlias is the alias of the collection keeping docker credentials
+	CollectionAlias = "docker"
+	// CollectionLabel is the label of the collection keeping docker credentials
+	CollectionLabel = "Docker Credentials"
+)
+
+// ErrNotFound is returned if there are no credentials for a server. Its
+// message is checked by docker so it must not change.
+var ErrNotFound = errors.New("credentials not found in native keychain")
+
+// ErrMissingServerURL is returned if credentials have no server url
+var ErrMissingServerURL = errors.New("no credentials server URL")
+
+// ErrMissingUsername is returned if stored credentials have no username
+var ErrMissingUsername = errors.New("no credentials username")
+
+// Credentials of a registry as exchanged with docker
+type Credentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// Helper runs credential helper actions against secret service
+type Helper struct {
+	// secret service client
+	Client *client.Client
+	// session to transfer secrets
+	Session *client.Session
+}
+
+// NewHelper connects to secret service and opens an encrypted session
+func NewHelper() (*Helper, error) {
+
+	ssClient, err := client.New()
+
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Helper{Client: ssClient, Session: session}, nil
+}
+
+// Add stores credentials replacing former credentials of the same server
+func (helper *Helper) Add(credentials *Credentials) error {
+
+	if credentials.ServerURL == "" {
+		return ErrMissingServerURL
+	}
+
+	if credentials.Username == "" {
+		return ErrMissingUsername
+	}
+
+	collection, err := helper.collection(true)
+
+	if err != nil {
+		return err
+	}
+
+	// former credentials are removed once new ones are stored so they are
+	// not lost if storing fails
+	items, err := helper.search(collection, credentials.ServerURL)
+
+	if err != nil {
+		return err
+	}
+
+	secretApi, err := helper.Session.EncryptSecret([]byte(credentials.Secret), "text/plain")
+
+	if err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label": dbus.MakeVariant(credentials.ServerURL),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(map[string]string{
+			"xdg:schema": Schema,
+			"server":     credentials.ServerURL,
+			"username":   credentials.Username,
+		}),
+	}
+
+	item, _, err := collection.CreateItem(properties, secretApi, true)
+
+	if err != nil {
+		return err
+	}
+
+	for _, stale := range items {
+		if stale.ObjectPath == item.ObjectPath {
+			continue
+		}
+		if _, err := stale.Delete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns credentials of given server
+func (helper *Helper) Get(serverURL string) (*Credentials, error) {
+
+	if serverURL == "" {
+		return nil, ErrMissingServerURL
+	}
+
+	collection, err := helper.collection(false)
+
+	if err != nil || collection == nil {
+		return nil, orNotFound(err)
+	}
+
+	items, err := helper.search(collection, serverURL)
+
+	if err != nil || len(items) == 0 {
+		return nil, orNotFound(err)
+	}
+
+	secretApi, err := items[0].GetSecret(helper.Session.ObjectPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := helper.Session.DecryptSecret(secretApi)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credentials{
+		ServerURL: serverURL,
+		Username:  items[0].LookupAttributes["username"],
+		Secret:    string(secret),
+	}, nil
+}
+
+// Delete removes credentials of given server
+func (helper *Helper) Delete(serverURL string) error {
+
+	if serverURL == "" {
+		return ErrMissingServerURL
+	}
+
+	collection, err := helper.collection(false)
+
+	if err != nil || collection == nil {
+		return orNotFound(err)
+	}
+
+	items, err := helper.search(collection, serverURL)
+
+	if err != nil || len(items) == 0 {
+		return orNotFound(err)
+	}
+
+	for _, item := range items {
+		if _, err := item.Delete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns server urls of all credentials and their usernames
+func (helper *Helper) List() (map[string]string, error) {
+
+	result := make(map[string]string)
+	collection, err := helper.collection(false)
+
+	if err != nil || collection == nil {
+		return result, err
+	}
+
+	items, err := helper.search(collection, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		result[item.LookupAttributes["server"]] = item.LookupAttributes["username"]
+	}
+
+	return result, nil
+}
+
+// collection returns docker credentials collection, if it doesn't
+// exist it is created if asked so, otherwise nil is returned
+func (helper *Helper) collection(create bool) (*client.Collection, error) {
+
+	collectionPath, err := helper.Client.ReadAlias(CollectionAlias)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if collectionPath != "/" {
+		return helper.Client.LoadCollection(collectionPath)
+	}
+
+	if !create {
+		return nil, nil
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant(CollectionLabel),
+	}
+
+	collection, _, err := helper.Client.CreateCollection(properties, CollectionAlias)
+
+	return collection, err
+}
+
+// search returns items of given server (all items if server is empty)
+func (helper *Helper) search(collection *client.Collection,
+	serverURL string) ([]*client.Item, error) {
+
+	attributes := map[string]string{"xdg:schema": Schema}
+
+	if serverURL != "" {
+		attributes["server"] = serverURL
+	}
+
+	itemPaths, err := collection.SearchItems(attributes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(itemPaths, func(i, j int) bool { return itemPaths[i] < itemPaths[j] })
+
+	var items []*client.Item
+
+	for _, itemPath := range itemPaths {
+		item, err := helper.Client.LoadItem(itemPath)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// orNotFound returns given error or ErrNotFound if it is nil
+func orNotFound(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,118 @@
+package dockercredential_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/dockercredential"
+)
+
+// serve runs a helper action with scripted protocol input and returns its output
+func serve(helper *dockercredential.Helper, action string, input string) (string, error) {
+
+	var output bytes.Buffer
+	err := dockercredential.Serve(helper, action, strings.NewReader(input), &output)
+
+	return output.String(), err
+}
+
+func TestServe(t *testing.T) {
+
+	helper, err := dockercredential.NewHelper()
+
+	if err != nil {
+		t.Fatalf("NewHelper failed. Error: %v", err)
+	}
+
+	t.Run("not found", func(t *testing.T) {
+
+		if _, err := serve(helper, "get", "https://index.docker.io/v1/\n"); err != dockercredential.ErrNotFound {
+			t.Errorf("Expected '%v', got: %v", dockercredential.ErrNotFound, err)
+		}
+
+		if output, err := serve(helper, "list", ""); err != nil || output != "{}\n" {
+			t.Errorf("Expected empty list, got: %q (%v)", output, err)
+		}
+	})
+
+	t.Run("store and get", func(t *testing.T) {
+
+		for _, input := range []string{
+			`{"ServerURL":"https://index.docker.io/v1/","Username":"joe","Secret":"first"}`,
+			`{"ServerURL":"https://index.docker.io/v1/","Username":"joe","Secret":"second"}`,
+			`{"ServerURL":"registry.example.com","Username":"bot","Secret":"token"}`,
+		} {
+			if _, err := serve(helper, "store", input); err != nil {
+				t.Fatalf("store failed. Error: %v", err)
+			}
+		}
+
+		output, err := serve(helper, "get", "https://index.docker.io/v1/\n")
+
+		if err != nil {
+			t.Fatalf("get failed. Error: %v", err)
+		}
+
+		if output != `{"ServerURL":"https://index.docker.io/v1/","Username":"joe","Secret":"second"}`+"\n" {
+			t.Errorf("Expected replaced credentials, got: %s", output)
+		}
+
+		unlocked, locked, _ := helper.Client.SearchItems(map[string]string{"server": "https://index.docker.io/v1/"})
+
+		if len(unlocked)+len(locked) != 1 {
+			t.Errorf("Expected former credentials to be removed, got: %d items", len(unlocked)+len(locked))
+		}
+
+		output, err = serve(helper, "list", "")
+
+		if err != nil {
+			t.Fatalf("list failed. Error: %v", err)
+		}
+
+		if output != `{"https://index.docker.io/v1/":"joe","registry.example.com":"bot"}`+"\n" {
+			t.Errorf("Unexpected list: %s", output)
+		}
+
+		collectionPath, _ := helper.Client.ReadAlias(dockercredential.CollectionAlias)
+		defaultPath, _ := helper.Client.ReadAlias("default")
+
+		if collectionPath == "/" || collectionPath == defaultPath {
+			t.Errorf("Expected credentials in a dedicated collection, got: %s", collectionPath)
+		}
+	})
+
+	t.Run("erase", func(t *testing.T) {
+
+		if _, err := serve(helper, "erase", "registry.example.com"); err != nil {
+			t.Fatalf("erase failed. Error: %v", err)
+		}
+
+		if _, err := serve(helper, "get", "registry.example.com"); err != dockercredential.ErrNotFound {
+			t.Errorf("Expected erased credentials not to be found, got: %v", err)
+		}
+
+		if _, err := serve(helper, "erase", "registry.example.com"); err != dockercredential.ErrNotFound {
+			t.Errorf("Expected erasing missing credentials to fail, got: %v", err)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+
+		if _, err := serve(helper, "store", `{"Username":"joe","Secret":"x"}`); err != dockercredential.ErrMissingServerURL {
+			t.Errorf("Expected missing server URL to fail, got: %v", err)
+		}
+
+		if _, err := serve(helper, "store", "not json"); err == nil {
+			t.Error("Expected malformed credentials to fail")
+		}
+
+		if _, err := serve(helper, "get", "\n"); err != dockercredential.ErrMissingServerURL {
+			t.Errorf("Expected missing server URL to fail, got: %v", err)
+		}
+
+		if _, err := serve(helper, "unknown", ""); err == nil {
+			t.Error("Expected unknown action to fail")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package dockercredential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+// Serve runs a credential helper action reading its input from reader and
+// writing its output to writer. On failure, helpers are expected to print
+// the error to standard output and exit with code 1.
+func Serve(helper *Helper, action string, reader io.Reader, writer io.Writer) error {
+
+	switch action {
+
+	case "store":
+		var credentials Credentials
+		if err := json.NewDecoder(reader).Decode(&credentials); err != nil {
+			return fmt.Errorf("cannot read credentials: %v", err)
+		}
+		return helper.Add(&credentials)
+
+	case "get":
+		serverURL, err := readServerURL(reader)
+		if err != nil {
+			return err
+		}
+		credentials, err := helper.Get(serverURL)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(writer).Encode(credentials)
+
+	case "erase":
+		serverURL, err := readServerURL(reader)
+		if err != nil {
+			return err
+		}
+		return helper.Delete(serverURL)
+
+	case "list":
+		servers, err := helper.List()
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(writer).Encode(servers)
+
+	case "version":
+		_, err := fmt.Fprintf(writer, "docker-credential-secretservice %s\n", service.Version)
+		return err
+
+	default:
+		return fmt.Errorf("unknown credential action '%s'", action)
+	}
+}
+
+// readServerURL reads a server url from reader
+func readServerURL(reader io.Reader) (string, error) {
+
+	input, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read server URL: %v", err)
+	}
+
+	serverURL := strings.TrimSpace(string(input))
+
+	if serverURL == "" {
+		return "", ErrMissingServerURL
+	}
+
+	return serverURL, nil
+}
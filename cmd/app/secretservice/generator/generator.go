@@ -0,0 +1,152 @@
+// Package generator generates random passwords and diceware passphrases
+// using crypto/rand. Passphrase words come from an embedded list of 2048
+// common English words (11 bits of entropy per word).
+package generator
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// DefaultCharset is the charset of passwords if none is given
+const DefaultCharset = "alnum,symbols"
+
+// charsets are named character sets which can be combined by ','
+var charsets = map[string]string{
+	"lower":   "abcdefghijklmnopqrstuvwxyz",
+	"upper":   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"digits":  "0123456789",
+	"alpha":   "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"alnum":   "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+	"hex":     "0123456789abcdef",
+	"symbols": "!#$%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+//go:embed wordlist.txt
+var wordlist string
+
+// words of passphrases
+var words = strings.Fields(wordlist)
+
+// ErrEmptyCharset is returned if there is no character to generate from
+var ErrEmptyCharset = errors.New("charset is empty")
+
+// CharsetNames returns names of known charsets in order
+func CharsetNames() []string {
+
+	var names []string
+
+	for name := range charsets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Charset returns unique characters of a charset specification which is
+// either known charset names separated by ',' (i.e. 'lower,digits') or
+// literal characters (i.e. 'abc123')
+func Charset(spec string) ([]rune, error) {
+
+	var characters string
+
+	for _, name := range strings.Split(spec, ",") {
+		set, ok := charsets[name]
+		if !ok {
+			characters = spec
+			break
+		}
+		characters += set
+	}
+
+	var result []rune
+	seen := make(map[rune]bool)
+
+	for _, character := range characters {
+		if !seen[character] {
+			seen[character] = true
+			result = append(result, character)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrEmptyCharset
+	}
+
+	return result, nil
+}
+
+// Password returns a password of given length drawn uniformly from charset
+func Password(length int, charset []rune) (string, error) {
+
+	if length < 1 {
+		return "", fmt.Errorf("invalid password length: %d", length)
+	}
+
+	if len(charset) == 0 {
+		return "", ErrEmptyCharset
+	}
+
+	password := make([]rune, length)
+
+	for i := range password {
+		index, err := randomIndex(len(charset))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[index]
+	}
+
+	return string(password), nil
+}
+
+// Passphrase returns given number of random words joined by separator
+func Passphrase(count int, separator string) (string, error) {
+
+	if count < 1 {
+		return "", fmt.Errorf("invalid number of words: %d", count)
+	}
+
+	passphrase := make([]string, count)
+
+	for i := range passphrase {
+		index, err := randomIndex(len(words))
+		if err != nil {
+			return "", err
+		}
+		passphrase[i] = words[index]
+	}
+
+	return strings.Join(passphrase, separator), nil
+}
+
+// Words returns number of words passphrases are drawn from
+func Words() int {
+	return len(words)
+}
+
+// Entropy returns bits of entropy of count symbols drawn uniformly from
+// a set of given size (characters of a password or words of a passphrase)
+func Entropy(count int, size int) float64 {
+	return float64(count) * math.Log2(float64(size))
+}
+
+// randomIndex returns a uniformly random number in [0, n)
+func randomIndex(n int) (int, error) {
+
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+
+	if err != nil {
+		return 0, fmt.Errorf("cannot read random numbers: %v", err)
+	}
+
+	return int(index.Int64()), nil
+}
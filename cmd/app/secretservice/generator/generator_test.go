@@ -0,0 +1,114 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/generator"
+)
+
+func TestCharset(t *testing.T) {
+
+	for _, tc := range []struct {
+		spec     string
+		expected string
+	}{
+		{"digits", "0123456789"},
+		{"hex,digits", "0123456789abcdef"},
+		{"lower,upper", "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+		{"abcab", "abc"},
+		{"digits,xyz", "digts,xyz"}, // literal characters
+		{"ÄÖÜ", "ÄÖÜ"},
+	} {
+		charset, err := generator.Charset(tc.spec)
+		if err != nil {
+			t.Errorf("Charset '%s' failed. Error: %v", tc.spec, err)
+			continue
+		}
+		if string(charset) != tc.expected {
+			t.Errorf("Charset '%s': expected '%s', got: '%s'", tc.spec, tc.expected, string(charset))
+		}
+	}
+
+	if _, err := generator.Charset(""); err != generator.ErrEmptyCharset {
+		t.Errorf("Expected '%v', got: %v", generator.ErrEmptyCharset, err)
+	}
+}
+
+func TestPassword(t *testing.T) {
+
+	charset, _ := generator.Charset("hex")
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+
+		password, err := generator.Password(32, charset)
+
+		if err != nil {
+			t.Fatalf("Password failed. Error: %v", err)
+		}
+
+		if len(password) != 32 {
+			t.Fatalf("Expected 32 characters, got: %d", len(password))
+		}
+
+		if strings.Trim(password, "0123456789abcdef") != "" {
+			t.Fatalf("Unexpected characters in password: %s", password)
+		}
+
+		if seen[password] {
+			t.Fatalf("Duplicate password: %s", password)
+		}
+
+		seen[password] = true
+	}
+
+	// all characters of charset are used
+	password, _ := generator.Password(2000, charset)
+	for _, character := range charset {
+		if !strings.ContainsRune(password, character) {
+			t.Errorf("Character '%c' is never generated", character)
+		}
+	}
+
+	if _, err := generator.Password(0, charset); err == nil {
+		t.Error("Expected zero length to fail")
+	}
+
+	if _, err := generator.Password(8, nil); err != generator.ErrEmptyCharset {
+		t.Errorf("Expected '%v', got: %v", generator.ErrEmptyCharset, err)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+
+	if generator.Words() != 2048 {
+		t.Errorf("Expected 2048 words, got: %d", generator.Words())
+	}
+
+	passphrase, err := generator.Passphrase(6, "-")
+
+	if err != nil {
+		t.Fatalf("Passphrase failed. Error: %v", err)
+	}
+
+	words := strings.Split(passphrase, "-")
+
+	if len(words) != 6 {
+		t.Fatalf("Expected 6 words, got: %s", passphrase)
+	}
+
+	for _, word := range words {
+		if len(word) < 3 || strings.Trim(word, "abcdefghijklmnopqrstuvwxyz") != "" {
+			t.Errorf("Unexpected word '%s' in: %s", word, passphrase)
+		}
+	}
+
+	if _, err := generator.Passphrase(0, " "); err == nil {
+		t.Error("Expected zero words to fail")
+	}
+
+	if entropy := generator.Entropy(6, generator.Words()); entropy != 66 {
+		t.Errorf("Expected 66 bits of entropy, got: %v", entropy)
+	}
+}
@@ -0,0 +1,220 @@
+// Package gitcredential implements git credential helper protocol
+// (git-credential(1)) on top of secret service. Credentials are kept
+// with the same lookup attributes as 'git-credential-libsecret' so
+// credentials stored by either helper are found by the other one.
+package gitcredential
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Schema is the libsecret schema (xdg:schema) of git credentials
+const Schema = "org.git.Password"
+
+// Credential is a git credential as described in git-credential(1)
+type Credential struct {
+	Protocol string
+	Host     string
+	Port     uint16
+	Path     string
+	Username string
+	Password string
+	// expiry of password (unix time), i.e. for OAuth access tokens
+	PasswordExpiryUTC string
+	OAuthRefreshToken string
+}
+
+// Read reads 'key=value' lines of a credential until an empty line or EOF.
+// Unknown keys are ignored like git does.
+func Read(reader io.Reader) (*Credential, error) {
+
+	credential := &Credential{}
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if line == "" {
+			break
+		}
+
+		separator := strings.Index(line, "=")
+
+		if separator < 1 {
+			return nil, fmt.Errorf("invalid credential line: '%s'", line)
+		}
+
+		key, value := line[:separator], line[separator+1:]
+
+		switch key {
+		case "protocol":
+			credential.Protocol = value
+		case "host":
+			credential.Host = value
+			// like git-credential-libsecret, port is kept separately
+			if index := strings.LastIndex(value, ":"); index >= 0 {
+				if port, err := strconv.ParseUint(value[index+1:], 10, 16); err == nil {
+					credential.Host = value[:index]
+					credential.Port = uint16(port)
+				}
+			}
+		case "path":
+			credential.Path = value
+		case "username":
+			credential.Username = value
+		case "password":
+			credential.Password = value
+		case "password_expiry_utc":
+			credential.PasswordExpiryUTC = value
+		case "oauth_refresh_token":
+			credential.OAuthRefreshToken = value
+		case "url":
+			if err := credential.setURL(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// setURL sets credential fields from given url
+func (credential *Credential) setURL(rawURL string) error {
+
+	parsed, err := url.Parse(rawURL)
+
+	if err != nil {
+		return fmt.Errorf("invalid credential url: %v", err)
+	}
+
+	credential.Protocol = parsed.Scheme
+	credential.Host = parsed.Hostname()
+	credential.Path = strings.TrimPrefix(parsed.Path, "/")
+
+	if port, err := strconv.ParseUint(parsed.Port(), 10, 16); err == nil {
+		credential.Port = uint16(port)
+	}
+
+	if parsed.User != nil {
+		credential.Username = parsed.User.Username()
+		credential.Password, _ = parsed.User.Password()
+	}
+
+	return nil
+}
+
+// Write writes non-empty fields of credential as 'key=value' lines
+func (credential *Credential) Write(writer io.Writer) error {
+
+	host := credential.Host
+
+	if host != "" && credential.Port != 0 {
+		host += ":" + strconv.Itoa(int(credential.Port))
+	}
+
+	for _, field := range [][2]string{
+		{"protocol", credential.Protocol},
+		{"host", host},
+		{"path", credential.Path},
+		{"username", credential.Username},
+		{"password", credential.Password},
+		{"password_expiry_utc", credential.PasswordExpiryUTC},
+		{"oauth_refresh_token", credential.OAuthRefreshToken},
+	} {
+		if field[1] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(writer, "%s=%s\n", field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Attributes returns lookup attributes of credential (without password)
+// the same as 'git-credential-libsecret'
+func (credential *Credential) Attributes() map[string]string {
+
+	attributes := make(map[string]string)
+
+	if credential.Username != "" {
+		attributes["user"] = credential.Username
+	}
+
+	if credential.Protocol != "" {
+		attributes["protocol"] = credential.Protocol
+	}
+
+	if credential.Host != "" {
+		attributes["server"] = credential.Host
+	}
+
+	if credential.Port != 0 {
+		attributes["port"] = strconv.Itoa(int(credential.Port))
+	}
+
+	if credential.Path != "" {
+		attributes["object"] = credential.Path
+	}
+
+	return attributes
+}
+
+// Label returns item label of credential the same as 'git-credential-libsecret'
+func (credential *Credential) Label() string {
+
+	label := "Git: " + credential.Protocol + "://" + credential.Host
+
+	if credential.Port != 0 {
+		label += ":" + strconv.Itoa(int(credential.Port))
+	}
+
+	if credential.Path != "" {
+		label += "/" + credential.Path
+	}
+
+	return label
+}
+
+// secret returns the stored secret of credential: password followed by
+// 'password_expiry_utc' and 'oauth_refresh_token' lines if they are set
+func (credential *Credential) secret() string {
+
+	secret := credential.Password
+
+	if credential.PasswordExpiryUTC != "" {
+		secret += "\npassword_expiry_utc=" + credential.PasswordExpiryUTC
+	}
+
+	if credential.OAuthRefreshToken != "" {
+		secret += "\noauth_refresh_token=" + credential.OAuthRefreshToken
+	}
+
+	return secret
+}
+
+// setSecret sets password and its extra fields from a stored secret
+func (credential *Credential) setSecret(secret string) {
+
+	lines := strings.Split(secret, "\n")
+	credential.Password = lines[0]
+
+	for _, line := range lines[1:] {
+		if value := strings.TrimPrefix(line, "password_expiry_utc="); value != line {
+			credential.PasswordExpiryUTC = value
+		} else if value := strings.TrimPrefix(line, "oauth_refresh_token="); value != line {
+			credential.OAuthRefreshToken = value
+		}
+	}
+}
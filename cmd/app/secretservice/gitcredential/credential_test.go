@@ -0,0 +1,90 @@
+package gitcredential_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/gitcredential"
+)
+
+func TestRead(t *testing.T) {
+
+	input := "protocol=https\nhost=example.com:8443\npath=team/repo.git\n" +
+		"username=joe\npassword=P@ss=word\nwwwauth[]=Basic\n\nprotocol=ignored\n"
+
+	credential, err := gitcredential.Read(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	expected := &gitcredential.Credential{
+		Protocol: "https",
+		Host:     "example.com",
+		Port:     8443,
+		Path:     "team/repo.git",
+		Username: "joe",
+		Password: "P@ss=word",
+	}
+
+	if !reflect.DeepEqual(credential, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, credential)
+	}
+
+	var output bytes.Buffer
+	credential.Write(&output)
+
+	if output.String() != "protocol=https\nhost=example.com:8443\npath=team/repo.git\n"+
+		"username=joe\npassword=P@ss=word\n" {
+		t.Errorf("Unexpected output:\n%s", output.String())
+	}
+
+	if _, err := gitcredential.Read(strings.NewReader("no separator\n")); err == nil {
+		t.Error("Expected invalid line to fail")
+	}
+}
+
+func TestRead_URL(t *testing.T) {
+
+	credential, err := gitcredential.Read(strings.NewReader("url=https://joe@example.com:8443/repo.git\n"))
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	if credential.Protocol != "https" || credential.Host != "example.com" || credential.Port != 8443 ||
+		credential.Path != "repo.git" || credential.Username != "joe" {
+		t.Errorf("Unexpected credential: %+v", credential)
+	}
+}
+
+func TestCredential_Attributes(t *testing.T) {
+
+	credential := &gitcredential.Credential{
+		Protocol: "https",
+		Host:     "example.com",
+		Port:     8443,
+		Path:     "repo.git",
+		Username: "joe",
+		Password: "secret",
+	}
+
+	// same as git-credential-libsecret
+	expected := map[string]string{
+		"user":     "joe",
+		"protocol": "https",
+		"server":   "example.com",
+		"port":     "8443",
+		"object":   "repo.git",
+	}
+
+	if attributes := credential.Attributes(); !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("Expected attributes %v, got: %v", expected, attributes)
+	}
+
+	if label := credential.Label(); label != "Git: https://example.com:8443/repo.git" {
+		t.Errorf("Unexpected label: %s", label)
+	}
+}
@@ -0,0 +1,173 @@
+package gitcredential
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+// ErrIncomplete is returned if a credential lacks fields required by
+// an action (same requirements as 'git-credential-libsecret')
+var ErrIncomplete = errors.New("credential needs protocol and host or path" +
+	" (and username and password to store)")
+
+// Helper runs credential helper actions against secret service
+type Helper struct {
+	// secret service client
+	Client *client.Client
+	// session to transfer secrets
+	Session *client.Session
+	// collection new credentials are stored in
+	Collection *client.Collection
+}
+
+// Get returns username and password (and their extra fields) of the first
+// unlocked credential matching given one, nil if there is no such credential
+func (helper *Helper) Get(credential *Credential) (*Credential, error) {
+
+	if credential.Protocol == "" || (credential.Host == "" && credential.Path == "") {
+		return nil, ErrIncomplete
+	}
+
+	items, err := helper.search(credential)
+
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	secret, err := helper.secret(items[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Credential{Username: credential.Username}
+	result.setSecret(secret)
+
+	if result.Username == "" {
+		result.Username = items[0].LookupAttributes["user"]
+	}
+
+	return result, nil
+}
+
+// Store stores given credential replacing the one with the same attributes
+func (helper *Helper) Store(credential *Credential) error {
+
+	if credential.Protocol == "" || (credential.Host == "" && credential.Path == "") ||
+		credential.Username == "" || credential.Password == "" {
+		return ErrIncomplete
+	}
+
+	secretApi, err := helper.Session.EncryptSecret([]byte(credential.secret()), "text/plain")
+
+	if err != nil {
+		return err
+	}
+
+	attributes := credential.Attributes()
+	attributes["xdg:schema"] = Schema
+
+	items, err := helper.search(credential)
+
+	if err != nil {
+		return err
+	}
+
+	// replace the credential with exactly the same attributes
+	for _, item := range items {
+		if reflect.DeepEqual(item.LookupAttributes, attributes) {
+			if err := item.SetSecret(secretApi); err != nil {
+				return err
+			}
+			return item.PropertySetLabel(credential.Label())
+		}
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(credential.Label()),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(attributes),
+	}
+
+	_, _, err = helper.Collection.CreateItem(properties, secretApi, true)
+
+	return err
+}
+
+// Erase removes unlocked credentials matching given one. If password is
+// given only credentials with the same password are removed.
+func (helper *Helper) Erase(credential *Credential) error {
+
+	if credential.Protocol == "" || (credential.Host == "" && credential.Path == "") {
+		return ErrIncomplete
+	}
+
+	items, err := helper.search(credential)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+
+		if credential.Password != "" {
+			secret, err := helper.secret(item)
+			if err != nil {
+				return err
+			}
+			stored := &Credential{}
+			stored.setSecret(secret)
+			if stored.Password != credential.Password {
+				continue
+			}
+		}
+
+		if _, err := item.Delete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// search returns unlocked items matching given credential. Schema is not
+// matched, like recent 'git-credential-libsecret' versions.
+func (helper *Helper) search(credential *Credential) ([]*client.Item, error) {
+
+	unlocked, _, err := helper.Client.SearchItems(credential.Attributes())
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(unlocked, func(i, j int) bool { return unlocked[i] < unlocked[j] })
+
+	var items []*client.Item
+
+	for _, itemPath := range unlocked {
+		item, err := helper.Client.LoadItem(itemPath)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// secret returns decrypted secret of given item
+func (helper *Helper) secret(item *client.Item) (string, error) {
+
+	secretApi, err := item.GetSecret(helper.Session.ObjectPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := helper.Session.DecryptSecret(secretApi)
+
+	return string(secret), err
+}
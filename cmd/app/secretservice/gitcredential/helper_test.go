@@ -0,0 +1,154 @@
+package gitcredential_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/gitcredential"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+// newHelper returns a helper storing credentials in default collection
+func newHelper(t *testing.T) *gitcredential.Helper {
+
+	ssClient, err := client.New()
+
+	if err != nil {
+		t.Fatalf("Cannot create client. Error: %v", err)
+	}
+
+	session, err := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+
+	if err != nil {
+		t.Fatalf("Cannot open session. Error: %v", err)
+	}
+
+	collectionPath, err := ssClient.ReadAlias("default")
+
+	if err != nil {
+		t.Fatalf("Cannot read default alias. Error: %v", err)
+	}
+
+	collection, err := ssClient.LoadCollection(collectionPath)
+
+	if err != nil {
+		t.Fatalf("Cannot load default collection. Error: %v", err)
+	}
+
+	return &gitcredential.Helper{Client: ssClient, Session: session, Collection: collection}
+}
+
+// run runs a helper action with scripted protocol input and returns its output
+func run(t *testing.T, helper *gitcredential.Helper, action string, input string) string {
+
+	credential, err := gitcredential.Read(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	var output bytes.Buffer
+
+	switch action {
+	case "get":
+		result, err := helper.Get(credential)
+		if err != nil {
+			t.Fatalf("get failed. Error: %v", err)
+		}
+		if result != nil {
+			result.Write(&output)
+		}
+	case "store":
+		err = helper.Store(credential)
+	case "erase":
+		err = helper.Erase(credential)
+	}
+
+	if err != nil {
+		t.Fatalf("%s failed. Error: %v", action, err)
+	}
+
+	return output.String()
+}
+
+func TestHelper(t *testing.T) {
+
+	helper := newHelper(t)
+
+	t.Run("store and get", func(t *testing.T) {
+
+		run(t, helper, "store", "protocol=https\nhost=git.example.com\nusername=joe\npassword=first\n\n")
+		run(t, helper, "store", "protocol=https\nhost=git.example.com\nusername=joe\npassword=second\n"+
+			"password_expiry_utc=1893456000\n\n")
+
+		output := run(t, helper, "get", "protocol=https\nhost=git.example.com\n\n")
+
+		if output != "username=joe\npassword=second\npassword_expiry_utc=1893456000\n" {
+			t.Errorf("Expected replaced credential, got:\n%s", output)
+		}
+
+		unlocked, _, _ := helper.Client.SearchItems(map[string]string{"server": "git.example.com"})
+
+		if len(unlocked) != 1 {
+			t.Errorf("Expected credential to be replaced, found %d items", len(unlocked))
+		}
+
+		if output := run(t, helper, "get", "protocol=https\nhost=other.example.com\n\n"); output != "" {
+			t.Errorf("Expected no credential for other host, got:\n%s", output)
+		}
+	})
+
+	t.Run("git-credential-libsecret compatibility", func(t *testing.T) {
+
+		// credential as stored by git-credential-libsecret
+		secretApi, _ := helper.Session.EncryptSecret([]byte("libsecret"), "text/plain")
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("Git: https://legacy.example.com:8443"),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(map[string]string{
+				"xdg:schema": "org.git.Password",
+				"user":       "jane",
+				"protocol":   "https",
+				"server":     "legacy.example.com",
+				"port":       "8443",
+			}),
+		}
+
+		if _, _, err := helper.Collection.CreateItem(properties, secretApi, true); err != nil {
+			t.Fatalf("Cannot create item. Error: %v", err)
+		}
+
+		output := run(t, helper, "get", "protocol=https\nhost=legacy.example.com:8443\n\n")
+
+		if output != "username=jane\npassword=libsecret\n" {
+			t.Errorf("Expected libsecret credential, got:\n%s", output)
+		}
+	})
+
+	t.Run("erase", func(t *testing.T) {
+
+		run(t, helper, "erase", "protocol=https\nhost=git.example.com\nusername=joe\npassword=wrong\n\n")
+
+		if output := run(t, helper, "get", "protocol=https\nhost=git.example.com\n\n"); output == "" {
+			t.Error("Expected credential with other password not to be erased")
+		}
+
+		run(t, helper, "erase", "protocol=https\nhost=git.example.com\nusername=joe\n\n")
+
+		if output := run(t, helper, "get", "protocol=https\nhost=git.example.com\n\n"); output != "" {
+			t.Errorf("Expected credential to be erased, got:\n%s", output)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+
+		if err := helper.Store(&gitcredential.Credential{Protocol: "https", Host: "example.com"}); err != gitcredential.ErrIncomplete {
+			t.Errorf("Expected store without username and password to fail, got: %v", err)
+		}
+
+		if _, err := helper.Get(&gitcredential.Credential{Host: "example.com"}); err != gitcredential.ErrIncomplete {
+			t.Errorf("Expected get without protocol to fail, got: %v", err)
+		}
+	})
+}
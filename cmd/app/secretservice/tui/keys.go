@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key is a key press. Printable keys are their runes, others are negative
+type Key rune
+
+const (
+	KeyUp Key = -(iota + 1)
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyCtrlC
+)
+
+// escapeSequences maps terminal escape sequences (without ESC) to keys
+var escapeSequences = map[string]Key{
+	"[A":  KeyUp,
+	"[B":  KeyDown,
+	"OA":  KeyUp,
+	"OB":  KeyDown,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+	"[H":  KeyHome,
+	"[F":  KeyEnd,
+	"OH":  KeyHome,
+	"OF":  KeyEnd,
+	"[1~": KeyHome,
+	"[4~": KeyEnd,
+}
+
+// Printable returns true if key is a printable character
+func (key Key) Printable() bool {
+	return key >= 0 && unicode.IsPrint(rune(key))
+}
+
+// ParseKeys converts bytes read from a terminal in raw mode to key presses.
+// Unknown escape sequences and control characters are dropped.
+func ParseKeys(input []byte) []Key {
+
+	var keys []Key
+
+	for len(input) > 0 {
+
+		switch input[0] {
+		case 0x1b:
+			key, size := parseEscape(input[1:])
+			if key != 0 {
+				keys = append(keys, key)
+			}
+			input = input[1+size:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, KeyEnter)
+		case 0x7f, 0x08:
+			keys = append(keys, KeyBackspace)
+		case 0x03:
+			keys = append(keys, KeyCtrlC)
+		default:
+			r, size := utf8.DecodeRune(input)
+			if key := Key(r); r != utf8.RuneError && key.Printable() {
+				keys = append(keys, key)
+			}
+			input = input[size:]
+			continue
+		}
+
+		input = input[1:]
+	}
+
+	return keys
+}
+
+// parseEscape returns the key of the escape sequence at the beginning of
+// input and its length. A lone ESC is the escape key.
+func parseEscape(input []byte) (Key, int) {
+
+	if len(input) == 0 || (input[0] != '[' && input[0] != 'O') {
+		return KeyEscape, 0
+	}
+
+	// CSI sequences end with a byte in range 0x40-0x7e
+	for size := 2; size <= len(input); size++ {
+		if last := input[size-1]; last >= 0x40 && last <= 0x7e {
+			return escapeSequences[string(input[:size])], size
+		}
+	}
+
+	return 0, len(input)
+}
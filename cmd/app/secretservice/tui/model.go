@@ -0,0 +1,723 @@
+// Package tui is a full-screen terminal browser of
+// secret service collections and items
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Item is an item as shown by the browser
+type Item struct {
+	Path       string
+	Label      string
+	Attributes map[string]string
+	Locked     bool
+}
+
+// Collection is a collection and its items as shown by the browser
+type Collection struct {
+	Path   string
+	Label  string
+	Locked bool
+	Items  []Item
+}
+
+// Backend reads and changes collections and items of secret service
+type Backend interface {
+	Load() ([]Collection, error)
+	Secret(itemPath string) (string, error)
+	SetSecret(itemPath string, secret string) error
+	SetItemLabel(itemPath string, label string) error
+	SetItemAttributes(itemPath string, attributes map[string]string) error
+	DeleteItem(itemPath string) error
+	SetCollectionLabel(collectionPath string, label string) error
+	DeleteCollection(collectionPath string) error
+	Lock(collectionPath string) error
+	Unlock(collectionPath string) error
+}
+
+// Style is how a line is drawn
+type Style uint8
+
+const (
+	StyleNormal Style = iota
+	StyleHeader
+	StyleCollection
+	StyleSelected
+	StyleDim
+)
+
+// Line is a line of the screen
+type Line struct {
+	Text  string
+	Style Style
+}
+
+type mode uint8
+
+const (
+	modeBrowse mode = iota
+	modeSearch
+	modeEdit
+	modeConfirm
+)
+
+// row is a visible row of the list, item is -1 for collection rows.
+// Collection rows shown only for their matching items don't match.
+type row struct {
+	collection int
+	item       int
+	match      bool
+}
+
+// Model is the state of the browser. It is independent of the terminal
+type Model struct {
+	backend     Backend
+	collections []Collection
+	rows        []row
+	cursor      int
+	offset      int
+	query       string
+	mode        mode
+	secrets     map[string]string // revealed secrets by item path
+	prompt      string
+	input       string
+	masked      bool
+	submit      func(input string) error
+	onConfirm   func() error
+	message     string
+	quit        bool
+}
+
+// NewModel returns a browser model on top of given backend
+func NewModel(backend Backend) *Model {
+	return &Model{
+		backend: backend,
+		secrets: make(map[string]string),
+	}
+}
+
+// Quit returns true if user asked to quit
+func (m *Model) Quit() bool {
+	return m.quit
+}
+
+// Reload reads collections and items from backend keeping
+// selection, search and revealed secrets where possible
+func (m *Model) Reload() error {
+
+	collections, err := m.backend.Load()
+
+	if err != nil {
+		m.message = "error: " + err.Error()
+		return err
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return less(collections[i].Label, collections[i].Path,
+			collections[j].Label, collections[j].Path)
+	})
+
+	for _, collection := range collections {
+		items := collection.Items
+		// items of a locked collection are locked as well
+		for i := range items {
+			items[i].Locked = items[i].Locked || collection.Locked
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return less(items[i].Label, items[i].Path, items[j].Label, items[j].Path)
+		})
+	}
+
+	selected := m.selectedPath()
+	m.collections = collections
+
+	// revealed secrets may have changed as well
+	for itemPath := range m.secrets {
+		item := m.findItem(itemPath)
+		if item == nil || item.Locked {
+			delete(m.secrets, itemPath)
+			continue
+		}
+		if secret, err := m.backend.Secret(itemPath); err == nil {
+			m.secrets[itemPath] = secret
+		} else {
+			delete(m.secrets, itemPath)
+		}
+	}
+
+	m.filter(selected)
+
+	return nil
+}
+
+// HandleKey changes model state according to given key press
+func (m *Model) HandleKey(key Key) {
+
+	switch m.mode {
+	case modeSearch:
+		m.handleSearch(key)
+	case modeEdit:
+		m.handleEdit(key)
+	case modeConfirm:
+		m.handleConfirm(key)
+	default:
+		m.handleBrowse(key)
+	}
+}
+
+func (m *Model) handleBrowse(key Key) {
+
+	m.message = ""
+	collection, item := m.selected()
+
+	switch key {
+	case KeyCtrlC, 'q':
+		m.quit = true
+	case KeyUp, 'k':
+		m.move(-1)
+	case KeyDown, 'j':
+		m.move(1)
+	case KeyPageUp:
+		m.move(-10)
+	case KeyPageDown:
+		m.move(10)
+	case KeyHome, 'g':
+		m.move(-len(m.rows))
+	case KeyEnd, 'G':
+		m.move(len(m.rows))
+	case '/':
+		m.mode = modeSearch
+	case KeyEscape:
+		if m.query != "" {
+			m.query = ""
+			m.filter(m.selectedPath())
+		}
+	case 'R':
+		m.Reload()
+	case 'r':
+		if item != nil {
+			m.toggleSecret(item)
+		}
+	case 'e':
+		if item != nil {
+			path := item.Path
+			m.edit("label", item.Label, false, func(label string) error {
+				return m.backend.SetItemLabel(path, label)
+			})
+		} else if collection != nil {
+			path := collection.Path
+			m.edit("label", collection.Label, false, func(label string) error {
+				return m.backend.SetCollectionLabel(path, label)
+			})
+		}
+	case 'a':
+		if item != nil {
+			path := item.Path
+			m.edit("attributes (key=value, ...)", FormatAttributes(item.Attributes), false,
+				func(input string) error {
+					attributes, err := ParseAttributes(input)
+					if err != nil {
+						return err
+					}
+					return m.backend.SetItemAttributes(path, attributes)
+				})
+		}
+	case 's':
+		if item != nil {
+			path := item.Path
+			m.edit("new secret", "", true, func(secret string) error {
+				if err := m.backend.SetSecret(path, secret); err != nil {
+					return err
+				}
+				if _, ok := m.secrets[path]; ok {
+					m.secrets[path] = secret
+				}
+				return nil
+			})
+		}
+	case 'l':
+		if collection != nil {
+			m.toggleLock(collection)
+		}
+	case 'd':
+		if item != nil {
+			path := item.Path
+			m.confirm(fmt.Sprintf("delete item '%s'? (y/N)", item.Label), func() error {
+				return m.backend.DeleteItem(path)
+			})
+		} else if collection != nil {
+			path := collection.Path
+			m.confirm(fmt.Sprintf("delete collection '%s' and its %d item(s)? (y/N)",
+				collection.Label, len(collection.Items)), func() error {
+				return m.backend.DeleteCollection(path)
+			})
+		}
+	}
+}
+
+func (m *Model) handleSearch(key Key) {
+
+	switch key {
+	case KeyEnter:
+		m.mode = modeBrowse
+		return
+	case KeyEscape, KeyCtrlC:
+		m.mode = modeBrowse
+		m.query = ""
+	case KeyUp:
+		m.move(-1)
+		return
+	case KeyDown:
+		m.move(1)
+		return
+	case KeyBackspace:
+		m.query = dropLastRune(m.query)
+	default:
+		if !key.Printable() {
+			return
+		}
+		m.query += string(rune(key))
+	}
+
+	m.filter(m.selectedPath())
+}
+
+func (m *Model) handleEdit(key Key) {
+
+	switch key {
+	case KeyEnter:
+		m.mode = modeBrowse
+		if err := m.submit(m.input); err != nil {
+			m.message = "error: " + err.Error()
+		} else {
+			m.message = "saved"
+			m.Reload()
+		}
+		m.input = ""
+	case KeyEscape, KeyCtrlC:
+		m.mode = modeBrowse
+		m.input = ""
+		m.message = "cancelled"
+	case KeyBackspace:
+		m.input = dropLastRune(m.input)
+	default:
+		if key.Printable() {
+			m.input += string(rune(key))
+		}
+	}
+}
+
+func (m *Model) handleConfirm(key Key) {
+
+	m.mode = modeBrowse
+
+	if key != 'y' && key != 'Y' {
+		m.message = "cancelled"
+		return
+	}
+
+	if err := m.onConfirm(); err != nil {
+		m.message = "error: " + err.Error()
+		return
+	}
+
+	m.message = "deleted"
+	m.Reload()
+}
+
+// edit asks user for input and passes it to submit
+func (m *Model) edit(prompt string, input string, masked bool, submit func(string) error) {
+	m.mode = modeEdit
+	m.prompt = prompt
+	m.input = input
+	m.masked = masked
+	m.submit = submit
+}
+
+// confirm asks user a yes/no question and calls onConfirm on yes
+func (m *Model) confirm(question string, onConfirm func() error) {
+	m.mode = modeConfirm
+	m.prompt = question
+	m.onConfirm = onConfirm
+}
+
+func (m *Model) toggleSecret(item *Item) {
+
+	if _, ok := m.secrets[item.Path]; ok {
+		delete(m.secrets, item.Path)
+		return
+	}
+
+	if item.Locked {
+		m.message = "item is locked"
+		return
+	}
+
+	secret, err := m.backend.Secret(item.Path)
+
+	if err != nil {
+		m.message = "error: " + err.Error()
+		return
+	}
+
+	m.secrets[item.Path] = secret
+}
+
+func (m *Model) toggleLock(collection *Collection) {
+
+	var err error
+
+	if collection.Locked {
+		err = m.backend.Unlock(collection.Path)
+	} else {
+		err = m.backend.Lock(collection.Path)
+	}
+
+	if err != nil {
+		m.message = "error: " + err.Error()
+		return
+	}
+
+	m.Reload()
+}
+
+// move moves cursor by given number of rows
+func (m *Model) move(delta int) {
+
+	m.cursor += delta
+
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// filter rebuilds visible rows according to search query and puts the cursor
+// on given path if it matches, otherwise on the first match (or where it was)
+func (m *Model) filter(selected string) {
+
+	previous := m.cursor
+	m.rows = nil
+	query := strings.ToLower(m.query)
+
+	for c, collection := range m.collections {
+
+		collectionMatch := query == "" || strings.Contains(strings.ToLower(collection.Label), query)
+		var items []row
+
+		for i, item := range collection.Items {
+			if collectionMatch || matchItem(item, query) {
+				items = append(items, row{collection: c, item: i, match: true})
+			}
+		}
+
+		if collectionMatch || len(items) > 0 {
+			m.rows = append(m.rows, row{collection: c, item: -1, match: collectionMatch})
+			m.rows = append(m.rows, items...)
+		}
+	}
+
+	m.cursor = 0
+
+	for index, row := range m.rows {
+		if row.match && m.rowPath(index) == selected {
+			m.cursor = index
+			return
+		}
+	}
+
+	// selected row is gone (i.e. deleted)
+	if query == "" {
+		m.move(previous)
+		return
+	}
+
+	for index, row := range m.rows {
+		if row.match {
+			m.cursor = index
+			return
+		}
+	}
+}
+
+// matchItem returns true if label or one of 'key=value' attributes contains query
+func matchItem(item Item, query string) bool {
+
+	if strings.Contains(strings.ToLower(item.Label), query) {
+		return true
+	}
+
+	for key, value := range item.Attributes {
+		if strings.Contains(strings.ToLower(key+"="+value), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selected returns collection and item (nil on collection rows) under cursor
+func (m *Model) selected() (*Collection, *Item) {
+
+	if m.cursor >= len(m.rows) {
+		return nil, nil
+	}
+
+	row := m.rows[m.cursor]
+	collection := &m.collections[row.collection]
+
+	if row.item < 0 {
+		return collection, nil
+	}
+
+	return collection, &collection.Items[row.item]
+}
+
+func (m *Model) selectedPath() string {
+	return m.rowPath(m.cursor)
+}
+
+func (m *Model) rowPath(index int) string {
+
+	if index >= len(m.rows) {
+		return ""
+	}
+
+	row := m.rows[index]
+
+	if row.item < 0 {
+		return m.collections[row.collection].Path
+	}
+
+	return m.collections[row.collection].Items[row.item].Path
+}
+
+func (m *Model) findItem(itemPath string) *Item {
+
+	for c := range m.collections {
+		for i := range m.collections[c].Items {
+			if m.collections[c].Items[i].Path == itemPath {
+				return &m.collections[c].Items[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// View returns the screen of given size
+func (m *Model) View(width int, height int) []Line {
+
+	collection, item := m.selected()
+	details := m.details(collection, item)
+
+	if len(details) > height/2 {
+		details = details[:height/2]
+	}
+
+	listHeight := height - len(details) - 2
+
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+
+	lines := []Line{{Text: m.header(), Style: StyleHeader}}
+
+	for index := m.offset; index < len(m.rows) && index < m.offset+listHeight; index++ {
+		line := m.rowLine(m.rows[index])
+		if index == m.cursor {
+			line.Style = StyleSelected
+		}
+		lines = append(lines, line)
+	}
+
+	for len(lines) < listHeight+1 {
+		lines = append(lines, Line{})
+	}
+
+	lines = append(lines, details...)
+	lines = append(lines, m.footer())
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	return lines
+}
+
+func (m *Model) header() string {
+
+	items := 0
+	for _, collection := range m.collections {
+		items += len(collection.Items)
+	}
+
+	header := fmt.Sprintf(" secretservice: %d collection(s), %d item(s)", len(m.collections), items)
+
+	if m.query != "" {
+		header += fmt.Sprintf("  search: %s", m.query)
+	}
+
+	return header
+}
+
+func (m *Model) rowLine(row row) Line {
+
+	collection := m.collections[row.collection]
+
+	if row.item < 0 {
+		text := fmt.Sprintf("%s (%d)", collection.Label, len(collection.Items))
+		if collection.Locked {
+			text += " [locked]"
+		}
+		return Line{Text: text, Style: StyleCollection}
+	}
+
+	item := collection.Items[row.item]
+	text := "  " + item.Label
+
+	if item.Locked {
+		text += " [locked]"
+	}
+
+	if attributes := FormatAttributes(item.Attributes); attributes != "" {
+		text += "  " + attributes
+	}
+
+	return Line{Text: text}
+}
+
+func (m *Model) details(collection *Collection, item *Item) []Line {
+
+	if collection == nil {
+		return []Line{{Text: strings.Repeat("-", 8), Style: StyleDim}, {Text: "no collections"}}
+	}
+
+	lines := []Line{{Text: strings.Repeat("-", 8), Style: StyleDim}}
+
+	if item == nil {
+		lines = append(lines,
+			Line{Text: "collection: " + collection.Path},
+			Line{Text: "label:      " + collection.Label},
+			Line{Text: fmt.Sprintf("locked:     %t", collection.Locked)},
+			Line{Text: fmt.Sprintf("items:      %d", len(collection.Items))})
+		return lines
+	}
+
+	lines = append(lines,
+		Line{Text: "item:       " + item.Path},
+		Line{Text: "label:      " + item.Label},
+		Line{Text: fmt.Sprintf("locked:     %t", item.Locked)})
+
+	secret, ok := m.secrets[item.Path]
+
+	if ok {
+		lines = append(lines, Line{Text: "secret:     " + secret})
+	} else {
+		lines = append(lines, Line{Text: "secret:     ******** (r to reveal)", Style: StyleDim})
+	}
+
+	keys := make([]string, 0, len(item.Attributes))
+	for key := range item.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines = append(lines, Line{Text: "  " + key + " = " + item.Attributes[key]})
+	}
+
+	return lines
+}
+
+func (m *Model) footer() Line {
+
+	switch m.mode {
+	case modeSearch:
+		return Line{Text: "/" + m.query + "_"}
+	case modeEdit:
+		input := m.input
+		if m.masked {
+			input = strings.Repeat("*", len([]rune(input)))
+		}
+		return Line{Text: m.prompt + ": " + input + "_"}
+	case modeConfirm:
+		return Line{Text: m.prompt}
+	}
+
+	if m.message != "" {
+		return Line{Text: m.message}
+	}
+
+	return Line{Text: "/ search  r reveal  e label  a attributes  s secret  l lock  d delete  q quit",
+		Style: StyleDim}
+}
+
+// FormatAttributes returns attributes as sorted 'key=value' pairs separated by comma
+func FormatAttributes(attributes map[string]string) string {
+
+	pairs := make([]string, 0, len(attributes))
+
+	for key, value := range attributes {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ", ")
+}
+
+// ParseAttributes parses comma separated 'key=value' pairs
+func ParseAttributes(input string) (map[string]string, error) {
+
+	attributes := make(map[string]string)
+
+	for _, pair := range strings.Split(input, ",") {
+
+		pair = strings.TrimSpace(pair)
+
+		if pair == "" {
+			continue
+		}
+
+		index := strings.Index(pair, "=")
+
+		if index < 1 {
+			return nil, fmt.Errorf("expected 'key=value', got '%s'", pair)
+		}
+
+		attributes[strings.TrimSpace(pair[:index])] = strings.TrimSpace(pair[index+1:])
+	}
+
+	return attributes, nil
+}
+
+// less orders by label then by path
+func less(label1 string, path1 string, label2 string, path2 string) bool {
+	if label1 != label2 {
+		return label1 < label2
+	}
+	return path1 < path2
+}
+
+func dropLastRune(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	return string(runes[:len(runes)-1])
+}
@@ -0,0 +1,390 @@
+package tui_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yousefvand/secret-service/cmd/app/secretservice/tui"
+)
+
+// fakeBackend keeps collections and secrets in memory
+type fakeBackend struct {
+	collections []tui.Collection
+	secrets     map[string]string
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		collections: []tui.Collection{
+			{Path: "/c/default", Label: "default", Items: []tui.Item{
+				{Path: "/c/default/1", Label: "github", Attributes: map[string]string{"service": "github", "user": "joe"}},
+				{Path: "/c/default/2", Label: "mail", Attributes: map[string]string{"service": "imap"}},
+			}},
+			{Path: "/c/work", Label: "work", Locked: true, Items: []tui.Item{
+				{Path: "/c/work/1", Label: "vpn", Locked: true},
+			}},
+		},
+		secrets: map[string]string{"/c/default/1": "P@ssw0rd", "/c/default/2": "Victoria"},
+	}
+}
+
+func (b *fakeBackend) Load() ([]tui.Collection, error) {
+	// return a copy like a real backend does
+	collections := make([]tui.Collection, len(b.collections))
+	for c, collection := range b.collections {
+		collections[c] = collection
+		collections[c].Items = append([]tui.Item{}, collection.Items...)
+	}
+	return collections, nil
+}
+
+func (b *fakeBackend) find(path string) (*tui.Collection, *tui.Item) {
+	for c := range b.collections {
+		if b.collections[c].Path == path {
+			return &b.collections[c], nil
+		}
+		for i := range b.collections[c].Items {
+			if b.collections[c].Items[i].Path == path {
+				return &b.collections[c], &b.collections[c].Items[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (b *fakeBackend) Secret(itemPath string) (string, error) {
+	if _, item := b.find(itemPath); item == nil || item.Locked {
+		return "", errors.New("locked")
+	}
+	return b.secrets[itemPath], nil
+}
+
+func (b *fakeBackend) SetSecret(itemPath string, secret string) error {
+	b.secrets[itemPath] = secret
+	return nil
+}
+
+func (b *fakeBackend) SetItemLabel(itemPath string, label string) error {
+	_, item := b.find(itemPath)
+	item.Label = label
+	return nil
+}
+
+func (b *fakeBackend) SetItemAttributes(itemPath string, attributes map[string]string) error {
+	_, item := b.find(itemPath)
+	item.Attributes = attributes
+	return nil
+}
+
+func (b *fakeBackend) DeleteItem(itemPath string) error {
+	collection, _ := b.find(itemPath)
+	for i, item := range collection.Items {
+		if item.Path == itemPath {
+			collection.Items = append(collection.Items[:i], collection.Items[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such item")
+}
+
+func (b *fakeBackend) SetCollectionLabel(collectionPath string, label string) error {
+	collection, _ := b.find(collectionPath)
+	collection.Label = label
+	return nil
+}
+
+func (b *fakeBackend) DeleteCollection(collectionPath string) error {
+	for c, collection := range b.collections {
+		if collection.Path == collectionPath {
+			b.collections = append(b.collections[:c], b.collections[c+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such collection")
+}
+
+func (b *fakeBackend) setLocked(collectionPath string, locked bool) {
+	collection, _ := b.find(collectionPath)
+	collection.Locked = locked
+	for i := range collection.Items {
+		collection.Items[i].Locked = locked
+	}
+}
+
+func (b *fakeBackend) Lock(collectionPath string) error {
+	b.setLocked(collectionPath, true)
+	return nil
+}
+
+func (b *fakeBackend) Unlock(collectionPath string) error {
+	b.setLocked(collectionPath, false)
+	return nil
+}
+
+// press sends given keys to model
+func press(model *tui.Model, keys ...tui.Key) {
+	for _, key := range keys {
+		model.HandleKey(key)
+	}
+}
+
+// typeText sends runes of given text to model
+func typeText(model *tui.Model, text string) {
+	for _, r := range text {
+		model.HandleKey(tui.Key(r))
+	}
+}
+
+// screen returns the text of the whole screen
+func screen(model *tui.Model) string {
+	var lines []string
+	for _, line := range model.View(120, 30) {
+		lines = append(lines, line.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selected returns the text of the selected line
+func selected(model *tui.Model) string {
+	for _, line := range model.View(120, 30) {
+		if line.Style == tui.StyleSelected {
+			return line.Text
+		}
+	}
+	return ""
+}
+
+func newModel(t *testing.T) (*tui.Model, *fakeBackend) {
+	backend := newFakeBackend()
+	model := tui.NewModel(backend)
+	if err := model.Reload(); err != nil {
+		t.Fatalf("Reload failed. Error: %v", err)
+	}
+	return model, backend
+}
+
+func TestModel(t *testing.T) {
+
+	t.Run("browse", func(t *testing.T) {
+
+		model, _ := newModel(t)
+
+		if !strings.HasPrefix(selected(model), "default") {
+			t.Errorf("Expected default collection to be selected, got: %q", selected(model))
+		}
+
+		press(model, tui.KeyDown)
+
+		if !strings.Contains(selected(model), "github") {
+			t.Errorf("Expected 'github' item to be selected, got: %q", selected(model))
+		}
+
+		press(model, tui.KeyEnd)
+
+		if !strings.Contains(selected(model), "vpn") {
+			t.Errorf("Expected last item to be selected, got: %q", selected(model))
+		}
+
+		press(model, 'q')
+
+		if !model.Quit() {
+			t.Error("Expected 'q' to quit")
+		}
+	})
+
+	t.Run("incremental search", func(t *testing.T) {
+
+		model, _ := newModel(t)
+
+		press(model, '/')
+		typeText(model, "imap")
+
+		view := screen(model)
+
+		if strings.Contains(view, "github") || !strings.Contains(view, "mail") {
+			t.Errorf("Expected only 'mail' item to match attribute search, got:\n%s", view)
+		}
+
+		if !strings.Contains(selected(model), "mail") {
+			t.Errorf("Expected first matching item to be selected, got: %q", selected(model))
+		}
+
+		press(model, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace)
+		typeText(model, "GIT")
+
+		if !strings.Contains(selected(model), "github") {
+			t.Errorf("Expected search to ignore case, got: %q", selected(model))
+		}
+
+		press(model, tui.KeyEscape)
+
+		if !strings.Contains(screen(model), "mail") {
+			t.Error("Expected Esc to clear search")
+		}
+	})
+
+	t.Run("reveal secret", func(t *testing.T) {
+
+		model, _ := newModel(t)
+
+		press(model, tui.KeyDown)
+
+		if strings.Contains(screen(model), "P@ssw0rd") {
+			t.Error("Expected secret to be hidden")
+		}
+
+		press(model, 'r')
+
+		if !strings.Contains(screen(model), "P@ssw0rd") {
+			t.Error("Expected secret to be revealed")
+		}
+
+		press(model, 'r')
+
+		if strings.Contains(screen(model), "P@ssw0rd") {
+			t.Error("Expected secret to be hidden again")
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+
+		model, backend := newModel(t)
+
+		press(model, tui.KeyDown, 'e', tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace)
+		typeText(model, "lab")
+		press(model, tui.KeyEnter)
+
+		if label := backend.collections[0].Items[0].Label; label != "gitlab" {
+			t.Errorf("Expected label 'gitlab', got: %q", label)
+		}
+
+		press(model, 'a')
+		typeText(model, ", team=core")
+		press(model, tui.KeyEnter)
+
+		expected := map[string]string{"service": "github", "user": "joe", "team": "core"}
+		if attributes := backend.collections[0].Items[0].Attributes; !reflect.DeepEqual(attributes, expected) {
+			t.Errorf("Expected attributes %v, got: %v", expected, attributes)
+		}
+
+		press(model, 's')
+		typeText(model, "s3cret")
+
+		if strings.Contains(screen(model), "s3cret") {
+			t.Error("Expected secret input to be masked")
+		}
+
+		press(model, tui.KeyEnter)
+
+		if secret := backend.secrets["/c/default/1"]; secret != "s3cret" {
+			t.Errorf("Expected secret 's3cret', got: %q", secret)
+		}
+
+		press(model, 'a')
+		typeText(model, ", broken")
+		press(model, tui.KeyEnter)
+
+		if !strings.Contains(screen(model), "error:") {
+			t.Error("Expected invalid attributes to be reported")
+		}
+	})
+
+	t.Run("lock and unlock", func(t *testing.T) {
+
+		model, backend := newModel(t)
+
+		press(model, 'l')
+
+		if !backend.collections[0].Locked || !strings.Contains(selected(model), "[locked]") {
+			t.Error("Expected collection to be locked")
+		}
+
+		press(model, tui.KeyDown, 'r')
+
+		if !strings.Contains(screen(model), "item is locked") {
+			t.Error("Expected locked item secret not to be revealed")
+		}
+
+		press(model, 'l')
+
+		if backend.collections[0].Locked {
+			t.Error("Expected collection to be unlocked")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+
+		model, backend := newModel(t)
+
+		press(model, tui.KeyDown, 'd', 'n')
+
+		if len(backend.collections[0].Items) != 2 {
+			t.Error("Expected delete to be cancelled")
+		}
+
+		press(model, 'd', 'y')
+
+		if len(backend.collections[0].Items) != 1 || backend.collections[0].Items[0].Label != "mail" {
+			t.Errorf("Expected 'github' item to be deleted, got: %v", backend.collections[0].Items)
+		}
+
+		if !strings.Contains(selected(model), "mail") {
+			t.Errorf("Expected cursor to stay in place, got: %q", selected(model))
+		}
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+
+		model, backend := newModel(t)
+
+		press(model, tui.KeyDown, tui.KeyDown)
+
+		backend.collections[0].Items = append(backend.collections[0].Items,
+			tui.Item{Path: "/c/default/0", Label: "aws"})
+		model.Reload()
+
+		if !strings.Contains(screen(model), "aws") {
+			t.Error("Expected new item after reload")
+		}
+
+		if !strings.Contains(selected(model), "mail") {
+			t.Errorf("Expected selection to follow item, got: %q", selected(model))
+		}
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+
+	keys := tui.ParseKeys([]byte("a\x1b[A\x1b[6~\r\x7f\x03\x1bé"))
+	expected := []tui.Key{'a', tui.KeyUp, tui.KeyPageDown, tui.KeyEnter,
+		tui.KeyBackspace, tui.KeyCtrlC, tui.KeyEscape, 'é'}
+
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got: %v", expected, keys)
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+
+	attributes, err := tui.ParseAttributes(" service = github ,user=joe,")
+
+	if err != nil {
+		t.Fatalf("ParseAttributes failed. Error: %v", err)
+	}
+
+	expected := map[string]string{"service": "github", "user": "joe"}
+
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("Expected %v, got: %v", expected, attributes)
+	}
+
+	if tui.FormatAttributes(attributes) != "service=github, user=joe" {
+		t.Errorf("Unexpected format: %s", tui.FormatAttributes(attributes))
+	}
+
+	if _, err := tui.ParseAttributes("=x"); err == nil {
+		t.Error("Expected missing key to fail")
+	}
+}
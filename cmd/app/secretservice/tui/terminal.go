@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences
+const (
+	alternateScreen = "\x1b[?1049h"
+	normalScreen    = "\x1b[?1049l"
+	hideCursor      = "\x1b[?25l"
+	showCursor      = "\x1b[?25h"
+	cursorHome      = "\x1b[H"
+	clearLine       = "\x1b[K"
+	clearBelow      = "\x1b[J"
+	resetStyle      = "\x1b[0m"
+)
+
+var styles = map[Style]string{
+	StyleHeader:     "\x1b[7m",
+	StyleCollection: "\x1b[1m",
+	StyleSelected:   "\x1b[7m",
+	StyleDim:        "\x1b[2m",
+}
+
+// Run shows the browser full-screen on the terminal until user quits.
+// Each value received from refresh reloads collections and items.
+func Run(backend Backend, refresh <-chan struct{}) error {
+
+	model := NewModel(backend)
+
+	if err := model.Reload(); err != nil {
+		return err
+	}
+
+	stdin := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(stdin)
+
+	if err != nil {
+		return err
+	}
+
+	defer term.Restore(stdin, state)
+
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString(alternateScreen + hideCursor)
+
+	defer func() {
+		out.WriteString(resetStyle + showCursor + normalScreen)
+		out.Flush()
+	}()
+
+	keys := make(chan []Key)
+	go readKeys(os.Stdin, keys)
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(stop)
+
+	for !model.Quit() {
+
+		draw(out, model)
+
+		select {
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range pressed {
+				model.HandleKey(key)
+			}
+		case <-refresh:
+			model.Reload()
+		case <-resize:
+		case <-stop:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// readKeys sends key presses read from given reader until it fails
+func readKeys(reader io.Reader, keys chan<- []Key) {
+
+	buffer := make([]byte, 256)
+
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			keys <- ParseKeys(buffer[:n])
+		}
+		if err != nil {
+			close(keys)
+			return
+		}
+	}
+}
+
+// draw renders model on the whole terminal
+func draw(out *bufio.Writer, model *Model) {
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+
+	if err != nil || width < 1 || height < 1 {
+		width, height = 80, 24
+	}
+
+	out.WriteString(cursorHome)
+
+	for index, line := range model.View(width, height) {
+
+		if index > 0 {
+			out.WriteString("\r\n")
+		}
+
+		text := fit(line.Text, width)
+
+		if line.Style == StyleHeader || line.Style == StyleSelected {
+			text += strings.Repeat(" ", width-utf8.RuneCountInString(text))
+		}
+
+		out.WriteString(styles[line.Style] + text + resetStyle + clearLine)
+	}
+
+	out.WriteString(clearBelow)
+	out.Flush()
+}
+
+// fit truncates text to given width and replaces control characters
+func fit(text string, width int) string {
+
+	runes := []rune(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '?'
+		}
+		return r
+	}, text))
+
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+
+	return string(runes)
+}
@@ -3,11 +3,16 @@
 package internal
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
 	"github.com/yousefvand/secret-service/pkg/service"
+	"gopkg.in/yaml.v3"
 )
 
 // App data structure, all other data are
@@ -19,6 +24,8 @@ type AppData struct {
 	Connection *dbus.Conn
 	// configurations data structure
 	Config *Config
+	// Mutex for lock/unlock Config which is replaced by reloading configurations
+	configMutex sync.RWMutex
 	// Service data structure
 	Service *service.Service
 }
@@ -54,16 +61,59 @@ func NewApp() *AppData {
 	}
 	app.Connection = connection
 	app.Service.Connection = connection
+	app.Service.Reload = app.Reload
 
 	return app
 }
 
-// Load configurations and setup logger
+// Load configurations and setup logger. Configurations are built aside
+// and replace current ones at once.
 func (app *AppData) Load() {
-	app.Config.Load(app)
-	app.Service.Config.AllowDbExport = app.Config.AllowDbExport
-	app.Service.Config.EncryptDatabase = app.Config.Encryption
+
+	config := NewConfig()
+	config.Load(app)
+
+	app.configMutex.Lock()
+	app.Config = config
 	app.SetupLogger()
+	app.configMutex.Unlock()
+
+	app.Service.ApplySettings(service.ServiceConfig{
+		AllowDbExport:   config.AllowDbExport,
+		EncryptDatabase: config.Encryption,
+		Portal:          config.Portal,
+		KWallet:         config.KWallet,
+		BackupDirectory: config.BackupDirectory,
+		BackupInterval:  time.Duration(config.BackupInterval) * time.Hour,
+		BackupRetention: service.BackupRetention{
+			KeepLast:   config.BackupKeepLast,
+			KeepDaily:  config.BackupKeepDaily,
+			KeepWeekly: config.BackupKeepWeekly,
+		},
+	})
+	app.Service.SetSchemas(LoadSchemas(app.Service.Config.Home, config))
+}
+
+// Reload reloads configurations unless config file is malformed,
+// in which case current configurations are kept
+func (app *AppData) Reload() error {
+
+	filePath := filepath.Join(app.Service.Config.Home, "config.yaml")
+	data, err := ioutil.ReadFile(filePath)
+
+	if err != nil {
+		return fmt.Errorf("cannot read config file '%s'. Error: %v", filePath, err)
+	}
+
+	if err := yaml.Unmarshal(data, NewConfig()); err != nil {
+		return fmt.Errorf("malformed config file '%s'. Error: %v", filePath, err)
+	}
+
+	// removed settings get their default value
+	app.Load()
+	log.Info("Configurations reloaded")
+
+	return nil
 }
 
 // SetupLogger sets up logger based on configurations
@@ -74,7 +124,9 @@ func (app *AppData) SetupLogger() {
 // Notify sends a desktop notification
 func (app *AppData) Notify(title string, body string, duration time.Duration) {
 
+	app.configMutex.RLock()
 	icon := app.Config.Icon
+	app.configMutex.RUnlock()
 
 	if icon == "" {
 		icon = "view-private" // or "flag"
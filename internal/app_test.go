@@ -19,6 +19,28 @@ func Test_App(t *testing.T) {
 		}
 	})
 
+	t.Run("App reload", func(t *testing.T) {
+
+		app := internal.NewApp()
+		app.Load()
+
+		// configurations are read meanwhile (run with -race)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				app.Service.GetStatus()
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			if err := app.Reload(); err != nil {
+				t.Errorf("Reload failed. Error: %v", err)
+			}
+		}
+		<-done
+	})
+
 	t.Run("App notify", func(t *testing.T) {
 
 		app := internal.NewApp()
@@ -1,9 +1,16 @@
 package internal
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,35 +45,59 @@ const (
 
 type Config struct {
 	// Config file version
-	Version string `yaml:"version"`
+	Version string `yaml:"version" json:"version"`
 	// Encrypt database using AES-CBC-256
-	Encryption bool `yaml:"encryption"`
+	Encryption bool `yaml:"encryption" json:"encryption"`
 	// Desktop notification icon
-	Icon string `yaml:"icon"`
+	Icon string `yaml:"icon" json:"icon"`
 	// Allow database to be exported without encryption
-	AllowDbExport bool `yaml:"allowDbExport"`
+	AllowDbExport bool `yaml:"allowDbExport" json:"allowDbExport"`
 	// Prompting when necessary
-	Prompting bool `yaml:"prompting"`
+	Prompting bool `yaml:"prompting" json:"prompting"`
 	// Absolute path to log file
-	LogFile string `yaml:"logFile"`
+	LogFile string `yaml:"logFile" json:"logFile"`
 	// Logger is enabled or not
-	Logging bool `yaml:"logging"`
+	Logging bool `yaml:"logging" json:"logging"`
 	// Log file format: 'text' or 'json'
-	LogFormat string `yaml:"logFormat"`
+	LogFormat string `yaml:"logFormat" json:"logFormat"`
 	// Level of logging verbosity
 	// 0-6  where 6 is the most verbose and 0 logs very severe events
-	LogLevel LogLevel `yaml:"logLevel"`
+	LogLevel LogLevel `yaml:"logLevel" json:"logLevel"`
 	// Maximum log size in MB before rotation
-	LogMaxSize int `yaml:"logMaxSize"`
+	LogMaxSize int `yaml:"logMaxSize" json:"logMaxSize"`
 	// Maximum number of rotated log files (backups)
-	LogMaxBackups int `yaml:"logMaxBackups"`
+	LogMaxBackups int `yaml:"logMaxBackups" json:"logMaxBackups"`
 	// Maximum age (in days) of a log file before rotation
 	// Between size and age which comes first makes log rotate
-	LogMaxAge int `yaml:"logMaxAge"`
+	LogMaxAge int `yaml:"logMaxAge" json:"logMaxAge"`
 	// Compress backup log files (true) or not (false)
-	LogCompress bool `yaml:"logCompress"`
+	LogCompress bool `yaml:"logCompress" json:"logCompress"`
 	// Log report caller function (makes logs larger)
-	LogReportCaller bool `yaml:"logReportCaller"`
+	LogReportCaller bool `yaml:"logReportCaller" json:"logReportCaller"`
+	// libsecret schemas (xdg:schema) and their required lookup attributes
+	Schemas []SchemaConfig `yaml:"schemas" json:"schemas"`
+	// Implement Flatpak secret portal backend (org.freedesktop.impl.portal.Secret)
+	Portal bool `yaml:"portal" json:"portal"`
+	// Implement KWallet compatibility layer (org.kde.kwalletd5 and org.kde.kwalletd6)
+	KWallet bool `yaml:"kwallet" json:"kwallet"`
+	// Absolute path to backup directory, empty means 'backups' in service home
+	BackupDirectory string `yaml:"backupDirectory" json:"backupDirectory"`
+	// Interval (in hours) of scheduled backups, 0 disables them
+	BackupInterval int `yaml:"backupInterval" json:"backupInterval"`
+	// Number of newest backups to keep (at least 1)
+	BackupKeepLast int `yaml:"backupKeepLast" json:"backupKeepLast"`
+	// Number of days to keep the newest backup of
+	BackupKeepDaily int `yaml:"backupKeepDaily" json:"backupKeepDaily"`
+	// Number of weeks to keep the newest backup of
+	BackupKeepWeekly int `yaml:"backupKeepWeekly" json:"backupKeepWeekly"`
+}
+
+// Schema configuration (libsecret 'xdg:schema')
+type SchemaConfig struct {
+	// Schema name i.e. 'org.gnome.keyring.NetworkPassword'
+	Name string `yaml:"name" json:"name"`
+	// Lookup attributes every item of this schema must have
+	Required []string `yaml:"required" json:"required"`
 }
 
 // NewConfig returns a new instance of Config
@@ -89,25 +120,330 @@ func (config *Config) Load(app *AppData) {
 
 	err = yaml.Unmarshal(data, config)
 	if err != nil {
-		log.Warnf("found malformed config file: '%s'. Using default config.", filePath)
+		// keep malformed file so changes are not lost
+		newName := time.Now().Format("2006.01.02-15:04:05") + "-" + "config.yaml.malformed"
 		app.Notify("Malformed config file", "Config file is malformed. Using default configurations.", time.Second*5)
-		createDefaultConfig(serviceHome)
-		data, _ = ioutil.ReadFile(filePath)
-		_ = yaml.Unmarshal(data, config)
+		*config = Config{}
+		if errRename := os.Rename(filePath, filepath.Join(serviceHome, newName)); errRename != nil {
+			// default config is used without overwriting the file
+			log.Errorf("found malformed config file: '%s'. Using default config. Cannot rename malformed file. Error: %v",
+				filePath, errRename)
+			_ = yaml.Unmarshal(defaultConfig, config)
+		} else {
+			log.Errorf("found malformed config file: '%s'. Using default config. Malformed file renamed to %s. Error: %v",
+				filePath, newName, err)
+			createDefaultConfig(serviceHome)
+			data, _ = ioutil.ReadFile(filePath)
+			_ = yaml.Unmarshal(data, config)
+		}
 	}
 
 	// old config, upgrade
 	if config.Version != configVersion {
 		newName := time.Now().Format("2006.01.02-15:04:05") + "-" + "config.yaml.bak"
-		os.Rename(filePath, filepath.Join(serviceHome, newName))
-		config.Load(app)
-
-		log.Warnf("Config file upgraded. Old config file renamed to %s", newName)
+		if err := os.Rename(filePath, filepath.Join(serviceHome, newName)); err != nil {
+			// old config is used as is rather than losing it
+			log.Errorf("Cannot upgrade config file '%s'. Cannot rename it. Error: %v", filePath, err)
+		} else {
+			config.Load(app)
+			log.Warnf("Config file upgraded. Old config file renamed to %s", newName)
+		}
 	}
 
 	fillMissingConfigurations(config)
 }
 
+// ValidateConfig returns problems of given config file content which
+// make secretserviced ignore the file or some of its settings
+func ValidateConfig(data []byte) []string {
+
+	var problems []string
+	config := NewConfig()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		// unknown fields are reported but the rest of config is used
+		if _, ok := err.(*yaml.TypeError); !ok {
+			return []string{fmt.Sprintf("malformed yaml, default config is used: %v", err)}
+		}
+		for _, message := range err.(*yaml.TypeError).Errors {
+			problems = append(problems, unknownSettingPattern.ReplaceAllString(message, "unknown setting '$1'"))
+		}
+	}
+
+	if config.Version != configVersion {
+		problems = append(problems, fmt.Sprintf("version is '%s', expected '%s'. "+
+			"File is renamed to a backup and replaced by default config", config.Version, configVersion))
+	}
+
+	if config.LogLevel > 6 {
+		problems = append(problems, fmt.Sprintf("logLevel %d is not in 0-6 range, 4 is used", config.LogLevel))
+	}
+
+	if logFormat := strings.ToLower(config.LogFormat); logFormat != "text" && logFormat != "json" {
+		problems = append(problems, fmt.Sprintf("logFormat '%s' is not 'text' or 'json', 'text' is used", config.LogFormat))
+	}
+
+	if config.LogFile != "" {
+		if exist, err := fileOrFolderExists(filepath.Dir(config.LogFile)); err != nil || !exist {
+			problems = append(problems, fmt.Sprintf("logFile directory '%s' does not exist, default log file is used",
+				filepath.Dir(config.LogFile)))
+		}
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"logMaxSize", config.LogMaxSize},
+		{"logMaxBackups", config.LogMaxBackups},
+		{"logMaxAge", config.LogMaxAge},
+	} {
+		if setting.value < 1 {
+			problems = append(problems, fmt.Sprintf("%s %d is less than 1, 1 is used", setting.name, setting.value))
+		}
+	}
+
+	if config.BackupDirectory != "" && !filepath.IsAbs(config.BackupDirectory) {
+		problems = append(problems, fmt.Sprintf("backupDirectory '%s' is not an absolute path, default backup directory is used",
+			config.BackupDirectory))
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"backupInterval", config.BackupInterval},
+		{"backupKeepLast", config.BackupKeepLast},
+		{"backupKeepDaily", config.BackupKeepDaily},
+		{"backupKeepWeekly", config.BackupKeepWeekly},
+	} {
+		if setting.value < 0 {
+			problems = append(problems, fmt.Sprintf("%s %d is negative, 0 is used", setting.name, setting.value))
+		}
+	}
+
+	for i, schema := range config.Schemas {
+		if schema.Name == "" {
+			problems = append(problems, fmt.Sprintf("schema #%d has no name and is ignored", i+1))
+		}
+	}
+
+	return problems
+}
+
+// ParseConfig parses config file content reporting malformed yaml,
+// unknown settings and settings of wrong type with their line numbers
+func ParseConfig(data []byte) (*Config, error) {
+
+	config := NewConfig()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return nil, errors.New(unknownSettingPattern.ReplaceAllString(err.Error(), "unknown setting '$1'"))
+	}
+
+	return config, nil
+}
+
+// unknownSettingPattern matches yaml error of an unknown setting
+var unknownSettingPattern = regexp.MustCompile(`field (\S+) not found in type internal\.Config`)
+
+// ConfigKeys returns keys of all settings in config file order
+func ConfigKeys() []string {
+
+	var keys []string
+	configType := reflect.TypeOf(Config{})
+
+	for i := 0; i < configType.NumField(); i++ {
+		keys = append(keys, configType.Field(i).Tag.Get("yaml"))
+	}
+
+	return keys
+}
+
+// Get returns value of the setting with given key
+func (config *Config) Get(key string) (interface{}, error) {
+
+	field, err := configField(config, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return field.Interface(), nil
+}
+
+// configField returns field of config with given yaml key
+func configField(config *Config, key string) (reflect.Value, error) {
+
+	configValue := reflect.ValueOf(config).Elem()
+
+	for i := 0; i < configValue.NumField(); i++ {
+		if configValue.Type().Field(i).Tag.Get("yaml") == key {
+			return configValue.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown setting '%s', known settings: %s",
+		key, strings.Join(ConfigKeys(), ", "))
+}
+
+// settingValue returns value of the setting with given key (nil if unknown)
+func settingValue(config *Config, key string) interface{} {
+	value, _ := config.Get(key)
+	return value
+}
+
+// parseConfigValue converts given text to the value of the setting with
+// given key and checks it is allowed. Returned text is its yaml form.
+func parseConfigValue(key string, text string) (string, error) {
+
+	field, err := configField(NewConfig(), key)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch key {
+	case "version":
+		return "", errors.New("'version' is managed by secretserviced")
+	case "schemas":
+		return "", errors.New("'schemas' is a list, edit config file or add a file to 'schemas.d' directory")
+	}
+
+	switch field.Kind() {
+
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return "", fmt.Errorf("'%s' must be true or false, got '%s'", key, text)
+		}
+		return strconv.FormatBool(value), nil
+
+	case reflect.Int, reflect.Uint8:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return "", fmt.Errorf("'%s' must be a number, got '%s'", key, text)
+		}
+		if key == "logLevel" && (value < 0 || value > 6) {
+			return "", fmt.Errorf("'logLevel' must be between 0 (panic) and 6 (trace), got %d", value)
+		}
+		if value < 0 && zeroAllowed[key] {
+			return "", fmt.Errorf("'%s' must be at least 0, got %d", key, value)
+		}
+		if value < 1 && key != "logLevel" && !zeroAllowed[key] {
+			return "", fmt.Errorf("'%s' must be at least 1, got %d", key, value)
+		}
+		return strconv.Itoa(value), nil
+
+	default: // string
+		switch key {
+		case "logFormat":
+			if text != "text" && text != "json" {
+				return "", fmt.Errorf("'logFormat' must be 'text' or 'json', got '%s'", text)
+			}
+		case "backupDirectory":
+			if text != "" && !filepath.IsAbs(text) {
+				return "", fmt.Errorf("'backupDirectory' must be an absolute path, got '%s'", text)
+			}
+		case "logFile":
+			if text != "" && !filepath.IsAbs(text) {
+				return "", fmt.Errorf("'logFile' must be an absolute path, got '%s'", text)
+			}
+			if exist, err := fileOrFolderExists(filepath.Dir(text)); text != "" && (err != nil || !exist) {
+				return "", fmt.Errorf("'logFile' directory '%s' does not exist", filepath.Dir(text))
+			}
+		}
+		return "'" + strings.ReplaceAll(text, "'", "''") + "'", nil
+	}
+}
+
+// zeroAllowed are number settings where 0 is allowed
+var zeroAllowed = map[string]bool{
+	"backupInterval":   true,
+	"backupKeepDaily":  true,
+	"backupKeepWeekly": true,
+}
+
+// SetConfigValue returns config file content with the setting of given key
+// changed to given value. Value is checked against setting type and allowed
+// values. Comments and layout of the file are kept.
+func SetConfigValue(data []byte, key string, text string) ([]byte, error) {
+
+	value, err := parseConfigValue(key, text)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ParseConfig(data); err != nil {
+		return nil, fmt.Errorf("config file is malformed: %v", err)
+	}
+
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("config file is malformed: %v", err)
+	}
+
+	var valueNode *yaml.Node
+
+	if len(document.Content) > 0 {
+
+		mapping := document.Content[0]
+
+		if mapping.Kind != yaml.MappingNode {
+			return nil, errors.New("config file is not a yaml mapping")
+		}
+
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				valueNode = mapping.Content[i+1]
+			}
+		}
+	}
+
+	if valueNode == nil { // missing setting, append it
+		result := string(data)
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			result += "\n"
+		}
+		return []byte(result + key + ": " + value + "\n"), nil
+	}
+
+	if valueNode.Kind != yaml.ScalarNode || valueNode.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, fmt.Errorf("'%s' spans multiple lines, edit config file by hand", key)
+	}
+
+	// replace the rest of the value line keeping its comment
+	lines := strings.Split(string(data), "\n")
+	line := lines[valueNode.Line-1]
+	replacement := line[:valueNode.Column-1] + value
+
+	if valueNode.LineComment != "" {
+		replacement += " " + valueNode.LineComment
+	}
+
+	lines[valueNode.Line-1] = replacement
+	result := []byte(strings.Join(lines, "\n"))
+
+	// changed file must read back the new value
+	expected := NewConfig()
+	yaml.Unmarshal([]byte(key+": "+value), expected)
+	config, err := ParseConfig(result)
+
+	if err != nil || !reflect.DeepEqual(settingValue(config, key), settingValue(expected, key)) {
+		return nil, fmt.Errorf("'%s' cannot be changed, edit config file by hand", key)
+	}
+
+	return result, nil
+}
+
 /* Just in case needed
 
 func (config *Config) Save() error {
@@ -165,6 +501,26 @@ func fillMissingConfigurations(config *Config) *Config {
 		config.LogMaxAge = 1
 	}
 
+	if !filepath.IsAbs(config.BackupDirectory) {
+		config.BackupDirectory = ""
+	}
+
+	if config.BackupInterval < 0 {
+		config.BackupInterval = 0
+	}
+
+	if config.BackupKeepLast < 1 {
+		config.BackupKeepLast = 1
+	}
+
+	if config.BackupKeepDaily < 0 {
+		config.BackupKeepDaily = 0
+	}
+
+	if config.BackupKeepWeekly < 0 {
+		config.BackupKeepWeekly = 0
+	}
+
 	return config
 }
 
@@ -227,4 +583,32 @@ logCompress: true
 
 # Log report caller function
 logReportCaller: false
+
+# libsecret schemas (xdg:schema) and lookup attributes their items must have
+# More schemas can be added as yaml files in 'schemas.d' directory next to this file
+# Example:
+# schemas:
+#   - name: 'org.gnome.keyring.NetworkPassword'
+#     required: ['user', 'server', 'protocol']
+schemas: []
+
+# Implement Flatpak secret portal backend (org.freedesktop.impl.portal.Secret)
+# Sandboxed applications get a stable per-application secret kept in 'portal' collection
+portal: false
+
+# Implement KWallet compatibility layer (org.kde.kwalletd5 and org.kde.kwalletd6)
+# KDE applications would use the same store. Don't enable if kwalletd is running
+kwallet: false
+
+# Absolute path to backup directory. Default (empty): 'backups' next to this file
+backupDirectory: ''
+
+# Interval (in hours) of scheduled backups, 0 disables scheduled backups
+backupInterval: 24
+
+# Scheduled backups are pruned keeping the newest 'backupKeepLast' backups (at least 1)
+# and the newest backup of each of the latest 'backupKeepDaily' days and 'backupKeepWeekly' weeks
+backupKeepLast: 7
+backupKeepDaily: 7
+backupKeepWeekly: 4
 `)
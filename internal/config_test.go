@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -28,3 +29,107 @@ func Test_Config(t *testing.T) {
 		}
 	})
 }
+
+func Test_ValidateConfig(t *testing.T) {
+
+	t.Run("default config", func(t *testing.T) {
+
+		if problems := ValidateConfig(defaultConfig); len(problems) != 0 {
+			t.Errorf("Expected default config to be valid, got: %v", problems)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+
+		problems := ValidateConfig([]byte("version: [0.2.0"))
+
+		if len(problems) != 1 || !strings.Contains(problems[0], "malformed") {
+			t.Errorf("Expected malformed yaml to be reported, got: %v", problems)
+		}
+	})
+
+	t.Run("wrong settings", func(t *testing.T) {
+
+		problems := ValidateConfig([]byte("version: 0.2.0\nlogLevel: 9\nlogFormat: xml\n" +
+			"logMaxSize: 5\nlogMaxBackups: 1\nlogMaxAge: 1\nunknown: true\n"))
+
+		expected := []string{"unknown", "logLevel", "logFormat"}
+
+		if len(problems) != len(expected) {
+			t.Fatalf("Expected %d problems, got: %v", len(expected), problems)
+		}
+
+		for i, word := range expected {
+			if !strings.Contains(problems[i], word) {
+				t.Errorf("Expected problem about '%s', got: %s", word, problems[i])
+			}
+		}
+	})
+}
+
+func Test_SetConfigValue(t *testing.T) {
+
+	data := []byte("version: 0.2.0\n\n# Log level\nlogLevel: 4 # info\n\nlogFormat: 'text'\n")
+
+	t.Run("change", func(t *testing.T) {
+
+		changed, err := SetConfigValue(data, "logLevel", "6")
+
+		if err != nil {
+			t.Fatalf("SetConfigValue failed. Error: %v", err)
+		}
+
+		expected := "version: 0.2.0\n\n# Log level\nlogLevel: 6 # info\n\nlogFormat: 'text'\n"
+
+		if string(changed) != expected {
+			t.Errorf("Expected comments and layout to be kept, got:\n%s", changed)
+		}
+
+		changed, _ = SetConfigValue(data, "logFormat", "json")
+
+		if config, err := ParseConfig(changed); err != nil || config.LogFormat != "json" {
+			t.Errorf("Expected logFormat to be 'json', got:\n%s", changed)
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+
+		changed, err := SetConfigValue(data, "icon", "it's")
+
+		if err != nil {
+			t.Fatalf("SetConfigValue failed. Error: %v", err)
+		}
+
+		if config, err := ParseConfig(changed); err != nil || config.Icon != "it's" {
+			t.Errorf("Expected icon to be appended, got:\n%s", changed)
+		}
+
+		changed, err = SetConfigValue(data, "backupInterval", "0")
+
+		if config, _ := ParseConfig(changed); err != nil || config.BackupInterval != 0 {
+			t.Errorf("Expected backupInterval 0 to be accepted. Error: %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		for _, setting := range [][]string{
+			{"logLevel", "7"},
+			{"logLevel", "high"},
+			{"logFormat", "xml"},
+			{"logMaxAge", "0"},
+			{"encryption", "maybe"},
+			{"logFile", "relative.log"},
+			{"backupKeepLast", "0"},
+			{"backupInterval", "-1"},
+			{"backupDirectory", "backups"},
+			{"version", "0.3.0"},
+			{"schemas", "[]"},
+			{"unknown", "1"},
+		} {
+			if _, err := SetConfigValue(data, setting[0], setting[1]); err == nil {
+				t.Errorf("Expected '%s: %s' to be rejected", setting[0], setting[1])
+			}
+		}
+	})
+}
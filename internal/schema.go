@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yousefvand/secret-service/pkg/service"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSchemas returns schemas declared in config file followed by schemas
+// in 'schemas.d' drop-in directory. Each drop-in file is a yaml file with
+// a single schema. A drop-in schema overrides config schema with the same name.
+// Drop-in directory: ~/.secret-service/secretserviced/schemas.d/
+func LoadSchemas(serviceHome string, config *Config) []*service.Schema {
+
+	schemas := map[string]*service.Schema{}
+
+	for _, schemaConfig := range config.Schemas {
+		if schemaConfig.Name == "" {
+			log.Warn("Ignoring schema with no name in config file")
+			continue
+		}
+		schemas[schemaConfig.Name] = service.NewSchema(schemaConfig.Name, schemaConfig.Required)
+	}
+
+	files, err := filepath.Glob(filepath.Join(serviceHome, "schemas.d", "*.yaml"))
+	if err != nil {
+		log.Warnf("Cannot read schemas drop-in directory. Error: %v", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Warnf("Cannot read schema file: '%s'. Error: %v", file, err)
+			continue
+		}
+
+		var schemaConfig SchemaConfig
+		if err := yaml.Unmarshal(data, &schemaConfig); err != nil || schemaConfig.Name == "" {
+			log.Warnf("Ignoring malformed schema file: '%s'", file)
+			continue
+		}
+
+		schemas[schemaConfig.Name] = service.NewSchema(schemaConfig.Name, schemaConfig.Required)
+	}
+
+	var names []string
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []*service.Schema
+	for _, name := range names {
+		log.Debugf("Schema '%s' requires attributes: %v", name, schemas[name].Required)
+		result = append(result, schemas[name])
+	}
+
+	return result
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_LoadSchemas(t *testing.T) {
+
+	serviceHome := tempDir(t)
+
+	config := NewConfig()
+	config.Schemas = []SchemaConfig{
+		{Name: "org.git.Password", Required: []string{"server", "protocol"}},
+		{Name: "org.example.Overridden", Required: []string{"a"}},
+		{Name: "", Required: []string{"ignored"}},
+	}
+
+	dropIn := filepath.Join(serviceHome, "schemas.d")
+	if err := os.Mkdir(dropIn, 0700); err != nil {
+		t.Fatalf("Cannot create drop-in directory. Error: %v", err)
+	}
+
+	files := map[string]string{
+		"overridden.yaml": "name: org.example.Overridden\nrequired: [b, c]\n",
+		"note.yaml":       "name: org.gnome.keyring.Note\nrequired: []\n",
+		"malformed.yaml":  "name: [\n",
+		"ignored.txt":     "name: org.example.Ignored\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dropIn, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Cannot write schema file. Error: %v", err)
+		}
+	}
+
+	schemas := LoadSchemas(serviceHome, config)
+
+	got := map[string][]string{}
+	for _, schema := range schemas {
+		got[schema.Name] = schema.Required
+	}
+
+	want := map[string][]string{
+		"org.git.Password":       {"protocol", "server"},
+		"org.example.Overridden": {"b", "c"},
+		"org.gnome.keyring.Note": {},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected schemas: %v, got: %v", want, got)
+	}
+}
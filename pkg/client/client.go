@@ -154,6 +154,97 @@ func (client *Client) GetCollectionByPath(collectionPath dbus.ObjectPath) *Colle
 	return nil
 }
 
+// LoadCollection returns the collection at given path reading its
+// properties from dbus. Collections already known to client are reused.
+func (client *Client) LoadCollection(collectionPath dbus.ObjectPath) (*Collection, error) {
+
+	if collection := client.GetCollectionByPath(collectionPath); collection != nil {
+		return collection, nil
+	}
+
+	collection, err := NewCollection(client)
+
+	if err != nil {
+		return nil, err
+	}
+
+	collection.ObjectPath = collectionPath
+	collection.SetProperties(map[string]dbus.Variant{})
+
+	label, err := collection.GetProperty("Label")
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load collection '%s'. Error: %v", collectionPath, err)
+	}
+
+	collection.Label, _ = label.Value().(string)
+
+	if locked, err := collection.GetProperty("Locked"); err == nil {
+		collection.Locked, _ = locked.Value().(bool)
+	}
+
+	collection.Created, _ = collection.PropertyCreated()
+	collection.Modified, _ = collection.PropertyModified()
+	client.AddCollection(collection)
+
+	return collection, nil
+}
+
+// LoadItem returns the item at given path reading its properties from dbus.
+// Parent collection of the item is loaded as well.
+func (client *Client) LoadItem(itemPath dbus.ObjectPath) (*Item, error) {
+
+	index := strings.LastIndex(string(itemPath), "/")
+
+	if index < 1 {
+		return nil, errors.New("Invalid item path: " + string(itemPath))
+	}
+
+	collection, err := client.LoadCollection(itemPath[:index])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item := collection.GetItemByPath(itemPath); item != nil {
+		return item, nil
+	}
+
+	item := NewItem(collection)
+	item.ObjectPath = itemPath
+
+	label, err := item.GetProperty("Label")
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load item '%s'. Error: %v", itemPath, err)
+	}
+
+	item.Label, _ = label.Value().(string)
+
+	if attributes, err := item.GetProperty("Attributes"); err == nil {
+		if attributes, ok := attributes.Value().(map[string]string); ok {
+			item.LookupAttributes = attributes
+		}
+	}
+
+	if itemType, err := item.GetProperty("Type"); err == nil {
+		item.Type, _ = itemType.Value().(string)
+	}
+
+	if locked, err := item.GetProperty("Locked"); err == nil {
+		item.Locked, _ = locked.Value().(bool)
+	}
+
+	item.Created, _ = item.PropertyCreated()
+	item.Modified, _ = item.PropertyModified()
+
+	if err := collection.AddItem(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Collection <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
 
 /* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Signals >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
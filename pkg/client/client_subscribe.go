@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Event is a change reported by a secret service signal
+type Event struct {
+	// time signal was received
+	Time time.Time
+	// full signal name i.e. 'org.freedesktop.Secret.Service.CollectionCreated'
+	Signal string
+	// object path signal was emitted on
+	Path dbus.ObjectPath
+	// collection or item the signal is about (empty for other signals)
+	Object dbus.ObjectPath
+	// interface of changed properties (PropertiesChanged only)
+	Interface string
+	// changed properties and their new values (PropertiesChanged only)
+	Changed map[string]dbus.Variant
+	// invalidated properties (PropertiesChanged only)
+	Invalidated []string
+}
+
+// Subscribe returns a channel receiving all 'org.freedesktop.Secret.Service',
+// 'org.freedesktop.Secret.Collection' and 'ir.remisa.SecretService' signals
+// and 'PropertiesChanged' signals of secret service objects. Unlike WatchSignal
+// subscription is continuous until returned cancel function is called. A
+// dedicated dbus connection is used so signals of other clients don't mix.
+func (client *Client) Subscribe() (<-chan Event, func(), error) {
+
+	connection, err := dbus.ConnectSessionBus()
+
+	if err != nil {
+		return nil, nil, errors.New("cannot connect to session dbus. Error: " + err.Error())
+	}
+
+	matches := [][]dbus.MatchOption{
+		{dbus.WithMatchInterface("org.freedesktop.Secret.Service")},
+		{dbus.WithMatchInterface("org.freedesktop.Secret.Collection")},
+		{dbus.WithMatchInterface("ir.remisa.SecretService")},
+		{dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged")},
+	}
+
+	for _, match := range matches {
+		match = append(match, dbus.WithMatchSender("org.freedesktop.secrets"))
+		if err := connection.AddMatchSignal(match...); err != nil {
+			connection.Close()
+			return nil, nil, errors.New("cannot watch for signals. Error: " + err.Error())
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 64)
+	connection.Signal(signals)
+
+	events := make(chan Event)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case signal, ok := <-signals:
+				if !ok {
+					return
+				}
+				select {
+				case events <- newEvent(signal):
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			connection.Close()
+		})
+	}
+
+	return events, cancel, nil
+}
+
+// newEvent converts a dbus signal to an Event
+func newEvent(signal *dbus.Signal) Event {
+
+	event := Event{
+		Time:   time.Now(),
+		Signal: signal.Name,
+		Path:   signal.Path,
+	}
+
+	if signal.Name == "org.freedesktop.DBus.Properties.PropertiesChanged" {
+		event.Object = signal.Path
+		if len(signal.Body) == 3 {
+			event.Interface, _ = signal.Body[0].(string)
+			event.Changed, _ = signal.Body[1].(map[string]dbus.Variant)
+			event.Invalidated, _ = signal.Body[2].([]string)
+		}
+		return event
+	}
+
+	if len(signal.Body) > 0 {
+		event.Object, _ = signal.Body[0].(dbus.ObjectPath)
+	}
+
+	return event
+}
@@ -0,0 +1,76 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+func TestClient_Subscribe(t *testing.T) {
+
+	ssClient, _ := client.New()
+
+	events, cancel, err := ssClient.Subscribe()
+
+	if err != nil {
+		t.Fatalf("Subscribe failed. Error: %v", err)
+	}
+
+	// waitEvent waits for given signal or fails after a timeout
+	waitEvent := func(signal string) client.Event {
+		for {
+			select {
+			case event := <-events:
+				if event.Signal == signal {
+					return event
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Expected '%s' event", signal)
+			}
+		}
+	}
+
+	collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{
+		"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant("subscribe"),
+	}, "")
+
+	t.Run("CollectionCreated", func(t *testing.T) {
+		event := waitEvent("org.freedesktop.Secret.Service.CollectionCreated")
+		if event.Object != collection.ObjectPath {
+			t.Errorf("Expected event about '%s', got: '%s'", collection.ObjectPath, event.Object)
+		}
+	})
+
+	t.Run("PropertiesChanged", func(t *testing.T) {
+
+		collection.PropertySetLabel("subscribed")
+
+		for {
+			event := waitEvent("org.freedesktop.DBus.Properties.PropertiesChanged")
+			if event.Object != collection.ObjectPath {
+				continue
+			}
+			if label, _ := event.Changed["Label"].Value().(string); label != "subscribed" {
+				t.Errorf("Expected changed label 'subscribed', got: %v", event.Changed)
+			}
+			break
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+
+		cancel()
+		cancel() // cancel is idempotent
+
+		select {
+		case _, ok := <-events:
+			for ok {
+				_, ok = <-events
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Expected events channel to be closed")
+		}
+	})
+}
@@ -3,6 +3,7 @@
 package client_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/godbus/dbus/v5"
@@ -108,3 +109,66 @@ func TestNew(t *testing.T) {
 
 	})
 }
+
+func TestClient_LoadItem(t *testing.T) {
+
+	t.Run("LoadItem", func(t *testing.T) {
+
+		ssClient, _ := client.New()
+		session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "default")
+
+		secretApi, err := session.EncryptSecret([]byte("Victoria"), "text/plain")
+
+		if err != nil {
+			t.Fatalf("EncryptSecret failed. Error: %v", err)
+		}
+
+		attributes := map[string]string{"load": "item", "user": "joe"}
+		created, _, err := collection.CreateItem(map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("load item"),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(attributes),
+		}, secretApi, true)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		// a new client knows nothing about the item
+		newClient, _ := client.New()
+		item, err := newClient.LoadItem(created.ObjectPath)
+
+		if err != nil {
+			t.Fatalf("LoadItem failed. Error: %v", err)
+		}
+
+		if item.Label != "load item" {
+			t.Errorf("Expected label 'load item', got: '%s'", item.Label)
+		}
+
+		if !reflect.DeepEqual(item.LookupAttributes, attributes) {
+			t.Errorf("Expected attributes: %v, got: %v", attributes, item.LookupAttributes)
+		}
+
+		if item.Parent.ObjectPath != collection.ObjectPath {
+			t.Errorf("Expected parent collection: %s, got: %s", collection.ObjectPath, item.Parent.ObjectPath)
+		}
+
+		newSession, _ := newClient.OpenSession(client.Plain)
+		secret, err := item.GetSecret(newSession.ObjectPath)
+
+		if err != nil {
+			t.Fatalf("GetSecret failed. Error: %v", err)
+		}
+
+		plainSecret, err := newSession.DecryptSecret(secret)
+
+		if err != nil || string(plainSecret) != "Victoria" {
+			t.Errorf("Expected secret 'Victoria', got: '%s' (error: %v)", plainSecret, err)
+		}
+
+		if _, err := newClient.LoadItem("/org/freedesktop/secrets/aliases/default/nothing"); err == nil {
+			t.Error("Expected error loading a non-existing item")
+		}
+	})
+}
@@ -177,7 +177,7 @@ func (collection *Collection) GetProperty(name string) (dbus.Variant, error) {
 	variant, err := busObject.GetProperty("org.freedesktop.Secret.Collection." + name)
 
 	if err != nil {
-		return dbus.MakeVariant(nil),
+		return dbus.Variant{},
 			fmt.Errorf("error getting property '%s'. Error: %v", name, err)
 	}
 
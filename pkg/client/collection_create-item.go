@@ -57,6 +57,14 @@ func (collection *Collection) CreateItem(properties map[string]dbus.Variant,
 				fmt.Errorf("'Attributes' in 'CreateItem' are not 'map[string]string'. Error: %T", attributes.Value())
 		}
 	}
+	if itemType, ok := properties["org.freedesktop.Secret.Item.Type"]; ok {
+		if itemType, ok := itemType.Value().(string); ok {
+			item.Type = itemType
+		}
+	} else { // service uses libsecret schema name (if any)
+		item.Type = item.LookupAttributes["xdg:schema"]
+	}
+
 	item.ObjectPath = itemPath
 	item.Created, _ = item.PropertyCreated()
 	item.Modified, _ = item.PropertyModified()
@@ -48,6 +48,50 @@ type SecretServiceCLiSession struct {
 	SymmetricKey []byte // 16 bytes (128 bits)
 }
 
+// daemon status and statistics returned by 'Status' method
+type ServiceStatus struct {
+	// secretserviced version
+	Version string
+	// service start time (epoch)
+	Started uint64
+	// seconds since service start
+	Uptime uint64
+	// database encryption mode: 'AES-CBC-256' or 'none'
+	Encryption string
+	// database can be exported without encryption
+	AllowDbExport bool
+	// Flatpak secret portal backend is enabled
+	Portal bool
+	// KWallet compatibility layer is enabled
+	KWallet bool
+	// number of registered schemas
+	Schemas uint32
+	// service is locked (ServiceLock)
+	Locked bool
+	// database file path
+	DbPath string
+	// database file size in bytes (0 if not saved yet)
+	DbSize uint64
+	// number of collections
+	Collections uint32
+	// number of items in all collections
+	Items uint32
+	// number of open sessions
+	Sessions uint32
+	// time of last successful database save (epoch), 0 if never saved
+	LastSave uint64
+	// error of last database save, empty if it succeeded
+	LastSaveError string
+	// memory obtained from OS in MB
+	Memory uint64
+	// number of goroutines
+	Goroutines uint32
+	// MASTERPASSWORD of daemon: 'valid', 'missing' or 'invalid' (not 32 characters)
+	MasterPassword string
+	// error of reading database file or decrypting it by MASTERPASSWORD, empty if it is fine
+	DbError string
+}
+
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< SecretService <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
 
 /* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Session >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
@@ -153,6 +197,8 @@ type Item struct {
 	LookupAttributes map[string]string
 	// label of this item
 	Label string
+	// item type (org.freedesktop.Secret.Item.Type) aka libsecret schema name
+	Type string
 	// Mutex to lock/unlock Locked status of item
 	LockMutex *sync.Mutex
 	// true if item is locked otherwise false
@@ -46,7 +46,7 @@ func (item *Item) GetProperty(name string) (dbus.Variant, error) {
 	variant, err := busObject.GetProperty("org.freedesktop.Secret.Item." + name)
 
 	if err != nil {
-		return dbus.MakeVariant(nil),
+		return dbus.Variant{},
 			fmt.Errorf("error getting property '%s'. Error: %v", name, err)
 	}
 
@@ -207,6 +207,41 @@ func (item *Item) PropertySetLabel(label string) error {
 	return nil
 }
 
+// PropertyGetType returns 'Type' property of the item
+func (item *Item) PropertyGetType() (string, error) {
+
+	variant, err := item.GetProperty("Type")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read 'Type' property. Error: %v", err)
+	}
+
+	itemType, ok := variant.Value().(string)
+
+	if !ok {
+		return "", fmt.Errorf("expected 'Type' to be of type 'string', got: '%T'",
+			variant.Value())
+	}
+
+	return itemType, nil
+}
+
+// PropertySetType changes 'Type' property of the item to the given value
+func (item *Item) PropertySetType(itemType string) error {
+
+	itemType = strings.TrimSpace(itemType)
+
+	err := item.SetProperty("Type", itemType)
+
+	if err != nil {
+		return fmt.Errorf("failed to write 'Type' property. Error: %v", err)
+	}
+
+	item.Type = itemType
+
+	return nil
+}
+
 // PropertyCreated returns 'Created' property of the item
 func (item *Item) PropertyCreated() (uint64, error) {
 
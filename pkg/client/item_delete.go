@@ -14,13 +14,17 @@ import (
 func (item *Item) Delete() (dbus.ObjectPath, error) {
 
 	client := item.Parent.Parent
-	_, err := client.Call("org.freedesktop.secrets", item.ObjectPath,
+	call, err := client.Call("org.freedesktop.secrets", item.ObjectPath,
 		"org.freedesktop.Secret.Item", "Delete")
 
 	if err != nil {
 		return "", errors.New("dbus call failed. Error: " + err.Error())
 	}
 
+	if call.Err != nil {
+		return "", errors.New("'Delete' failed. Error: " + call.Err.Error())
+	}
+
 	err = item.Parent.RemoveItem(item.ObjectPath)
 
 	if err != nil {
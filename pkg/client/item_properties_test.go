@@ -8,6 +8,7 @@ import (
 	"github.com/godbus/dbus/v5"
 	"github.com/yousefvand/secret-service/pkg/client"
 	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
 )
 
 func Test_Item_Properties(t *testing.T) {
@@ -645,4 +646,118 @@ func Test_Item_Properties(t *testing.T) {
 
 	/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Modified <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
 
+	/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Type >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+	/*
+		READWRITE String Type ;
+	*/
+	t.Run("Item Property - Type", func(t *testing.T) {
+
+		ssClient, _ := client.New()
+
+		// open session
+		session, err := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+
+		if err != nil {
+			t.Errorf("failed to open session. Error: %v", err)
+		}
+
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "item property")
+
+		// Add item with 'xdg:schema' attribute
+
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("typed item"),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(map[string]string{
+				"xdg:schema": "org.test.TypeBefore",
+				"typed":      "yes",
+			}),
+		}
+
+		iv, cipherData, err := crypto.AesCBCEncrypt([]byte("Victoria1"), session.SymmetricKey)
+
+		if err != nil {
+			t.Errorf("encryption error: %v", err)
+		}
+
+		secretApi := client.NewSecretApi()
+		secretApi.ContentType = "text/plain"
+		secretApi.Session = session.ObjectPath
+		secretApi.Parameters = iv
+		secretApi.Value = cipherData
+
+		item, _, err := collection.CreateItem(properties, secretApi, true)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		itemType, err := item.PropertyGetType()
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		if itemType != "org.test.TypeBefore" {
+			t.Errorf("Expected item's type property to be: 'org.test.TypeBefore', got: '%v'", itemType)
+		}
+
+		////////////////////////////// Set item Type //////////////////////////////
+
+		err = item.PropertySetType("org.test.TypeAfter")
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		serviceItem := Service.GetItemByPath(item.ObjectPath)
+
+		if serviceItem == nil || serviceItem.Type != "org.test.TypeAfter" {
+			t.Errorf("Expected item's service side type to be: 'org.test.TypeAfter'")
+		}
+
+		////////////////////////////// Search by schema //////////////////////////////
+
+		unlocked, locked, err := ssClient.SearchItemsBySchema("org.test.TypeAfter",
+			map[string]string{"typed": "yes"})
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		if len(unlocked)+len(locked) != 1 {
+			t.Errorf("Expected exactly one item of type 'org.test.TypeAfter', got: %d",
+				len(unlocked)+len(locked))
+		}
+
+		unlocked, locked, err = ssClient.SearchItemsBySchema("org.test.TypeBefore",
+			map[string]string{"typed": "yes"})
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		// 'xdg:schema' attribute still matches like libsecret
+		if len(unlocked)+len(locked) != 1 {
+			t.Errorf("Expected exactly one item with 'xdg:schema' 'org.test.TypeBefore', got: %d",
+				len(unlocked)+len(locked))
+		}
+
+		////////////////////////////// Type of a registered schema //////////////////////////////
+
+		Service.SetSchemas([]*service.Schema{service.NewSchema("org.test.Required", []string{"user"})})
+		defer Service.SetSchemas(nil)
+
+		if err := item.PropertySetType("org.test.Required"); err == nil {
+			t.Error("Expected 'Type' of a schema with missing required attributes to be rejected")
+		}
+
+		if serviceItem.Type != "org.test.TypeAfter" {
+			t.Errorf("Expected item's type to stay 'org.test.TypeAfter', got: '%s'", serviceItem.Type)
+		}
+
+	})
+
+	/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Type <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
 }
@@ -2,8 +2,6 @@ package client
 
 import (
 	"errors"
-
-	"github.com/yousefvand/secret-service/pkg/crypto"
 )
 
 /*
@@ -14,20 +12,28 @@ import (
 func (item *Item) SetSecret(secretApi *SecretApi) error {
 
 	client := item.Parent.Parent
-	_, err := client.Call("org.freedesktop.secrets", item.ObjectPath,
+	call, err := client.Call("org.freedesktop.secrets", item.ObjectPath,
 		"org.freedesktop.Secret.Item", "SetSecret", *secretApi)
 
 	if err != nil {
 		return errors.New("dbus call failed. Error: " + err.Error())
 	}
 
+	if call.Err != nil {
+		return errors.New("'SetSecret' failed. Error: " + call.Err.Error())
+	}
+
 	item.Secret.SecretApi = secretApi
 	session := client.GetSessionByPath(secretApi.Session)
-	plainSecret, err := crypto.AesCBCDecrypt(secretApi.Parameters,
-		secretApi.Value, session.SymmetricKey)
+
+	if session == nil {
+		return errors.New("No such session: " + string(secretApi.Session))
+	}
+
+	plainSecret, err := session.DecryptSecret(secretApi)
 
 	if err != nil {
-		return errors.New("Decryption error: " + err.Error())
+		return err
 	}
 
 	item.Secret.PlainSecret = string(plainSecret)
@@ -0,0 +1,194 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+/*
+	CreateBackup ( IN  Boolean plain,
+								 OUT String file);
+
+	RestoreBackup ( IN String file);
+*/
+
+func TestClient_Backup(t *testing.T) {
+
+	t.Run("SecretService CreateBackup and RestoreBackup", func(t *testing.T) {
+
+		const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+		ssClient, _ := client.New()
+		session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+		attributes := map[string]string{"backup": string(collection.ObjectPath)}
+		secretApi, _ := session.EncryptSecret([]byte("P@ssw0rd"), "text/plain")
+		item, _, err := collection.CreateItem(map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("backup"),
+			"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(attributes),
+		}, secretApi, true)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		// unencrypted backups need database export to be allowed
+		if _, err := ssClient.CreateBackup(true); err == nil {
+			t.Error("Expected plain backup to be refused without 'allowDbExport'")
+		}
+
+		t.Setenv("MASTERPASSWORD", "")
+
+		if _, err := ssClient.CreateBackup(false); err == nil {
+			t.Error("Expected backup without master password to fail")
+		}
+
+		t.Setenv("MASTERPASSWORD", masterPassword)
+
+		backupFile, err := ssClient.CreateBackup(false)
+
+		if err != nil {
+			t.Fatalf("CreateBackup failed. Error: %v", err)
+		}
+
+		if filepath.Dir(backupFile) != filepath.Join(Service.Config.Home, "backups") {
+			t.Errorf("Expected backup in service backup directory, got: %s", backupFile)
+		}
+
+		manifest, err := service.ReadBackupManifest(backupFile)
+
+		if err != nil {
+			t.Fatalf("ReadBackupManifest failed. Error: %v", err)
+		}
+
+		if !manifest.Encrypted || manifest.Items < 1 {
+			t.Errorf("Unexpected manifest: %+v", manifest)
+		}
+
+		// collections of other tests are kept by restoring them too, and a
+		// dedicated collection is added
+		db, err := service.ReadBackup(backupFile, masterPassword)
+
+		if err != nil {
+			t.Fatalf("ReadBackup failed. Error: %v", err)
+		}
+
+		restoredPath := dbus.ObjectPath("/org/freedesktop/secrets/collection/restored_backup")
+		restoredAttributes := map[string]string{"backup": "restored"}
+		db.Collections = append(db.Collections, service.DbCollection{
+			ObjectPath: restoredPath,
+			Label:      "restored backup",
+			Properties: map[string]string{"Label": "restored backup"},
+			Items: []service.DbItem{{
+				Parent:           restoredPath,
+				ObjectPath:       restoredPath + "/1",
+				Label:            "restored",
+				Properties:       map[string]string{"Label": "restored"},
+				LookupAttributes: restoredAttributes,
+				Secret:           service.DbSecret{SecretText: "Victoria"},
+			}},
+		})
+
+		content, err := service.NewBackup(db, masterPassword, time.Now())
+
+		if err != nil {
+			t.Fatalf("NewBackup failed. Error: %v", err)
+		}
+
+		restoreFile := filepath.Join(t.TempDir(), "restore.json")
+		ioutil.WriteFile(restoreFile, content, 0600)
+
+		collectionsBefore, _ := ssClient.PropertyGetCollections()
+
+		if _, err := item.Delete(); err != nil {
+			t.Fatalf("Delete failed. Error: %v", err)
+		}
+
+		if err := ssClient.RestoreBackup(restoreFile); err != nil {
+			t.Fatalf("RestoreBackup failed. Error: %v", err)
+		}
+
+		unlocked, _, _ := ssClient.SearchItems(attributes)
+
+		if len(unlocked) != 1 || unlocked[0] != item.ObjectPath {
+			t.Fatalf("Expected restored item '%s', got: %v", item.ObjectPath, unlocked)
+		}
+
+		restored, _ := item.GetSecret(session.ObjectPath)
+		secret, _ := session.DecryptSecret(restored)
+
+		if string(secret) != "P@ssw0rd" {
+			t.Errorf("Expected restored secret 'P@ssw0rd', got: '%s'", secret)
+		}
+
+		unlocked, _, _ = ssClient.SearchItems(restoredAttributes)
+
+		if len(unlocked) != 1 || unlocked[0] != restoredPath+"/1" {
+			t.Errorf("Expected item of dedicated collection, got: %v", unlocked)
+		}
+
+		collectionsAfter, _ := ssClient.PropertyGetCollections()
+		expected := append(append([]string{}, collectionsBefore...), string(restoredPath))
+		sort.Strings(expected)
+		sort.Strings(collectionsAfter)
+
+		if len(collectionsAfter) != len(expected) {
+			t.Fatalf("Expected collections %v, got: %v", expected, collectionsAfter)
+		}
+
+		for i := range expected {
+			if collectionsAfter[i] != expected[i] {
+				t.Fatalf("Expected collections %v, got: %v", expected, collectionsAfter)
+			}
+		}
+
+		// corrupted backups are refused and current data is kept
+		content, _ = ioutil.ReadFile(backupFile)
+		corrupted := filepath.Join(t.TempDir(), "corrupted.json")
+		content[len(content)/2] ^= 1
+		ioutil.WriteFile(corrupted, content, 0600)
+
+		if err := ssClient.RestoreBackup(corrupted); err == nil {
+			t.Error("Expected restoring a corrupted backup to fail")
+		}
+
+		if err := ssClient.RestoreBackup(filepath.Join(os.TempDir(), "none.json")); err == nil {
+			t.Error("Expected restoring a missing backup to fail")
+		}
+
+		// backups are validated before current data is replaced
+		db.Collections[len(db.Collections)-1].Items[0].ObjectPath = "/org/freedesktop/secrets/collection/other/1"
+		content, _ = service.NewBackup(db, masterPassword, time.Now())
+		ioutil.WriteFile(restoreFile, content, 0600)
+
+		if err := ssClient.RestoreBackup(restoreFile); err == nil {
+			t.Error("Expected restoring a backup with an invalid item path to fail")
+		}
+
+		if unlocked, _, _ := ssClient.SearchItems(attributes); len(unlocked) != 1 {
+			t.Errorf("Expected item to survive failed restores, got: %v", unlocked)
+		}
+
+		// remove collections of this test from test service
+		restoredCollection, err := ssClient.LoadCollection(restoredPath)
+
+		if err != nil {
+			t.Fatalf("LoadCollection failed. Error: %v", err)
+		}
+
+		for _, testCollection := range []*client.Collection{collection, restoredCollection} {
+			if _, err := testCollection.Delete(); err != nil {
+				t.Errorf("Delete failed. Error: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"errors"
+)
+
+/*
+	CreateBackup ( IN  Boolean plain,
+								 OUT String file);
+*/
+
+// CreateBackup asks service to write a backup of all collections and items
+// to its backup directory and returns backup file. Backup is encrypted by
+// master password of service unless plain is true (service must allow
+// database export).
+func (client *Client) CreateBackup(plain bool) (string, error) {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "CreateBackup", plain)
+
+	if err != nil {
+		return "", errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return "", errors.New("'CreateBackup' failed. Error: " + call.Err.Error())
+	}
+
+	var file string
+
+	err = call.Store(&file)
+
+	if err != nil {
+		return "", errors.New("Type conversion failed in 'CreateBackup'. Error: " + err.Error())
+	}
+
+	return file, nil
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+/*
+	ListAliases ( OUT Dict<String,ObjectPath> aliases );
+*/
+
+// ListAliases returns all aliases and their collection paths
+func (client *Client) ListAliases() (map[string]dbus.ObjectPath, error) {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "ListAliases")
+
+	if err != nil {
+		return nil, errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	var aliases map[string]dbus.ObjectPath
+
+	err = call.Store(&aliases)
+
+	if err != nil {
+		return nil, errors.New("Type conversion failed in 'ListAliases'. Error: " + err.Error())
+	}
+
+	return aliases, nil
+}
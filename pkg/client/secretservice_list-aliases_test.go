@@ -0,0 +1,51 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+/*
+	ListAliases ( OUT Dict<String,ObjectPath> aliases );
+*/
+
+func TestClient_ListAliases(t *testing.T) {
+
+	t.Run("SecretService ListAliases", func(t *testing.T) {
+
+		ssClient, _ := client.New()
+
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "listed")
+
+		aliases, err := ssClient.ListAliases()
+
+		if err != nil {
+			t.Fatalf("ListAliases failed. Error: %v", err)
+		}
+
+		if aliases["listed"] != collection.ObjectPath {
+			t.Errorf("Expected alias 'listed' for '%s', got: '%s'", collection.ObjectPath, aliases["listed"])
+		}
+
+		if aliases["default"] != "/org/freedesktop/secrets/aliases/default" {
+			t.Errorf("Expected alias 'default' in aliases, got: %v", aliases)
+		}
+
+		// Documentation: collection '/' removes the alias
+		if err := ssClient.SetAlias("listed", "/"); err != nil {
+			t.Fatalf("SetAlias failed. Error: %v", err)
+		}
+
+		aliases, _ = ssClient.ListAliases()
+
+		if _, ok := aliases["listed"]; ok {
+			t.Error("Expected alias 'listed' to be removed")
+		}
+
+		if err := ssClient.SetAlias("default", "/"); err == nil {
+			t.Error("Expected removing 'default' alias to fail")
+		}
+	})
+}
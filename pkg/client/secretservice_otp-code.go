@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+/*
+	OtpCode ( IN  ObjectPath item,
+						OUT String code,
+						OUT UInt32 remaining);
+*/
+
+// OtpCode returns current TOTP code of an otp item and seconds it remains
+// valid. The otpauth URI (seed) of the item is not transferred.
+func (client *Client) OtpCode(item dbus.ObjectPath) (string, uint32, error) {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "OtpCode", item)
+
+	if err != nil {
+		return "", 0, errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return "", 0, errors.New("'OtpCode' failed. Error: " + call.Err.Error())
+	}
+
+	var code string
+	var remaining uint32
+
+	err = call.Store(&code, &remaining)
+
+	if err != nil {
+		return "", 0, errors.New("Type conversion failed in 'OtpCode'. Error: " + err.Error())
+	}
+
+	return code, remaining, nil
+}
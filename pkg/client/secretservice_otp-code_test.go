@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/otp"
+)
+
+/*
+	OtpCode ( IN  ObjectPath item,
+						OUT String code,
+						OUT UInt32 remaining);
+*/
+
+func TestClient_OtpCode(t *testing.T) {
+
+	t.Run("SecretService OtpCode", func(t *testing.T) {
+
+		const uri = "otpauth://totp/Test:joe?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=Test"
+
+		ssClient, _ := client.New()
+		session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+		secretApi, _ := session.EncryptSecret([]byte(uri), otp.ContentType)
+		item, _, err := collection.CreateItem(map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("otp"),
+		}, secretApi, true)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		key, _ := otp.Parse(uri)
+		before, _ := key.Code(time.Now())
+		code, remaining, err := ssClient.OtpCode(item.ObjectPath)
+		after, _ := key.Code(time.Now())
+
+		if err != nil {
+			t.Fatalf("OtpCode failed. Error: %v", err)
+		}
+
+		if code != before && code != after {
+			t.Errorf("Expected code '%s', got: '%s'", before, code)
+		}
+
+		if remaining < 1 || remaining > 30 {
+			t.Errorf("Expected remaining validity in [1, 30], got: %d", remaining)
+		}
+
+		// content type is kept
+		stored, _ := item.GetSecret(session.ObjectPath)
+
+		if stored.ContentType != otp.ContentType {
+			t.Errorf("Expected content type '%s', got: '%s'", otp.ContentType, stored.ContentType)
+		}
+
+		plainApi, _ := session.EncryptSecret([]byte("P@ssw0rd"), "text/plain")
+		plainItem, _, _ := collection.CreateItem(map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("not otp"),
+		}, plainApi, true)
+
+		if _, _, err := ssClient.OtpCode(plainItem.ObjectPath); err == nil {
+			t.Error("Expected OtpCode of a non otp item to fail")
+		}
+
+		if _, _, err := ssClient.OtpCode("/org/freedesktop/secrets/collection/none/none"); err == nil {
+			t.Error("Expected OtpCode of a missing item to fail")
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"errors"
+)
+
+/*
+	RestoreBackup ( IN String file);
+*/
+
+// RestoreBackup asks service to validate given backup file and replace all
+// collections and items with the ones in backup
+func (client *Client) RestoreBackup(file string) error {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "RestoreBackup", file)
+
+	if err != nil {
+		return errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return errors.New("'RestoreBackup' failed. Error: " + call.Err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"errors"
+)
+
+/*
+	ServiceLock ( void );
+*/
+
+// ServiceLock locks all collections and seals all secrets until service is unlocked
+func (client *Client) ServiceLock() error {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "ServiceLock")
+
+	if err != nil {
+		return errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return errors.New("'ServiceLock' failed. Error: " + call.Err.Error())
+	}
+
+	return nil
+}
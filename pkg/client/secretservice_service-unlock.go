@@ -0,0 +1,33 @@
+package client
+
+import (
+	"errors"
+)
+
+/*
+	ServiceUnlock ( IN Secret master );
+*/
+
+// ServiceUnlock unlocks the service using master password. Password
+// is transferred encrypted if the session is an encrypted one
+func (client *Client) ServiceUnlock(session *Session, password string) error {
+
+	master, err := session.EncryptSecret([]byte(password), "text/plain")
+
+	if err != nil {
+		return err
+	}
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "ServiceUnlock", *master)
+
+	if err != nil {
+		return errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return errors.New("'ServiceUnlock' failed. Error: " + call.Err.Error())
+	}
+
+	return nil
+}
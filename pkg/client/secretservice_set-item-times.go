@@ -0,0 +1,30 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+/*
+	SetItemTimes ( IN ObjectPath item,
+								 IN UInt64 created,
+								 IN UInt64 modified);
+*/
+
+// SetItemTimes sets creation and modification time (unix epoch) of an item
+func (client *Client) SetItemTimes(item dbus.ObjectPath, created uint64, modified uint64) error {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "SetItemTimes", item, created, modified)
+
+	if err != nil {
+		return errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	if call.Err != nil {
+		return errors.New("'SetItemTimes' failed. Error: " + call.Err.Error())
+	}
+
+	return nil
+}
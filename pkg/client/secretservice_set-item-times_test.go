@@ -0,0 +1,52 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+)
+
+/*
+	SetItemTimes ( IN ObjectPath item,
+								 IN UInt64 created,
+								 IN UInt64 modified);
+*/
+
+func TestClient_SetItemTimes(t *testing.T) {
+
+	t.Run("SecretService SetItemTimes", func(t *testing.T) {
+
+		ssClient, _ := client.New()
+		session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+		collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+		secretApi, _ := session.EncryptSecret([]byte("P@ssw0rd"), "text/plain")
+		item, _, err := collection.CreateItem(map[string]dbus.Variant{
+			"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("times"),
+		}, secretApi, true)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		if err := ssClient.SetItemTimes(item.ObjectPath, 1600000000, 1700000000); err != nil {
+			t.Fatalf("SetItemTimes failed. Error: %v", err)
+		}
+
+		created, _ := item.PropertyCreated()
+		modified, _ := item.PropertyModified()
+
+		if created != 1600000000 || modified != 1700000000 {
+			t.Errorf("Expected times 1600000000 1700000000, got: %d %d", created, modified)
+		}
+
+		if err := ssClient.SetItemTimes(item.ObjectPath, 1700000000, 1600000000); err == nil {
+			t.Error("Expected SetItemTimes with modified before created to fail")
+		}
+
+		if err := ssClient.SetItemTimes("/org/freedesktop/secrets/collection/none/none", 1, 1); err == nil {
+			t.Error("Expected SetItemTimes of a missing item to fail")
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"errors"
+)
+
+/*
+	Status ( OUT Struct status );
+*/
+
+// Status returns daemon status and statistics
+func (client *Client) Status() (*ServiceStatus, error) {
+
+	call, err := client.Call("org.freedesktop.secrets", "/secretservice",
+		"ir.remisa.SecretService", "Status")
+
+	if err != nil {
+		return nil, errors.New("dbus call failed. Error: " + err.Error())
+	}
+
+	status := &ServiceStatus{}
+
+	err = call.Store(status)
+
+	if err != nil {
+		return nil, errors.New("Type conversion failed in 'Status'. Error: " + err.Error())
+	}
+
+	return status, nil
+}
@@ -0,0 +1,63 @@
+package client_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+/*
+	Status ( OUT Struct status );
+*/
+
+func TestClient_Status(t *testing.T) {
+
+	t.Run("SecretService Status", func(t *testing.T) {
+
+		ssClient, _ := client.New()
+
+		ssClient.OpenSession(client.Plain)
+		ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+		status, err := ssClient.Status()
+
+		if err != nil {
+			t.Fatalf("Status failed. Error: %v", err)
+		}
+
+		if status.Version != service.Version {
+			t.Errorf("Expected version '%s', got: '%s'", service.Version, status.Version)
+		}
+
+		if status.Started == 0 {
+			t.Error("Expected service start time")
+		}
+
+		if status.DbPath != filepath.Join(Service.Config.Home, "db.json") {
+			t.Errorf("Unexpected database path: %s", status.DbPath)
+		}
+
+		if status.Encryption != "none" {
+			t.Errorf("Expected no database encryption, got: %s", status.Encryption)
+		}
+
+		if status.Collections < 2 || status.Sessions < 1 || status.Goroutines == 0 || status.Memory == 0 {
+			t.Errorf("Unexpected counters: %+v", *status)
+		}
+
+		t.Setenv("MASTERPASSWORD", "short")
+
+		if status, _ := ssClient.Status(); status == nil || status.MasterPassword != "invalid" {
+			t.Errorf("Expected invalid master password, got: %+v", status)
+		}
+
+		t.Setenv("MASTERPASSWORD", "abcdefghijklmnopqrstuvwxyz012345")
+
+		if status, _ := ssClient.Status(); status == nil || status.MasterPassword != "valid" || status.DbError != "" {
+			t.Errorf("Expected valid master password and database, got: %+v", status)
+		}
+	})
+}
@@ -34,3 +34,18 @@ func (client *Client) SearchItems(
 
 	return unlocked, locked, nil
 }
+
+// SearchItemsBySchema searches for items of given schema (xdg:schema or
+// 'Type' property) matching the lookup attributes
+func (client *Client) SearchItemsBySchema(schema string,
+	attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, error) {
+
+	scoped := map[string]string{"xdg:schema": schema}
+	for k, v := range attributes {
+		if k != "xdg:schema" {
+			scoped[k] = v
+		}
+	}
+
+	return client.SearchItems(scoped)
+}
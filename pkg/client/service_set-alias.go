@@ -14,12 +14,16 @@ import (
 // SetAlias sets (or removes) an alias for given collection
 func (client *Client) SetAlias(name string, collection dbus.ObjectPath) error {
 
-	_, err := client.Call("org.freedesktop.secrets", "/org/freedesktop/secrets",
+	call, err := client.Call("org.freedesktop.secrets", "/org/freedesktop/secrets",
 		"org.freedesktop.Secret.Service", "SetAlias", name, collection)
 
 	if err != nil {
 		return errors.New("dbus call failed. Error: " + err.Error())
 	}
 
+	if call.Err != nil {
+		return errors.New("'SetAlias' failed. Error: " + call.Err.Error())
+	}
+
 	return nil
 }
@@ -1,6 +1,11 @@
 package client
 
-import "github.com/godbus/dbus/v5"
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/crypto"
+)
 
 // NewSession creates and initialize a new session
 func NewSession(parent *Client) *Session {
@@ -32,3 +37,45 @@ func (client *Client) GetSessionByPath(sessionPath dbus.ObjectPath) *Session {
 	}
 	return nil
 }
+
+// EncryptSecret makes a SecretApi out of given plain secret for this session
+func (session *Session) EncryptSecret(plainSecret []byte, contentType string) (*SecretApi, error) {
+
+	secretApi := NewSecretApi()
+	secretApi.Session = session.ObjectPath
+	secretApi.ContentType = contentType
+
+	if session.EncryptionAlgorithm == Plain {
+		secretApi.Parameters = []byte{}
+		secretApi.Value = plainSecret
+		return secretApi, nil
+	}
+
+	iv, cipherData, err := crypto.AesCBCEncrypt(plainSecret, session.SymmetricKey)
+
+	if err != nil {
+		return nil, errors.New("Encryption error: " + err.Error())
+	}
+
+	secretApi.Parameters = iv
+	secretApi.Value = cipherData
+
+	return secretApi, nil
+}
+
+// DecryptSecret returns the plain secret of given SecretApi of this session
+func (session *Session) DecryptSecret(secretApi *SecretApi) ([]byte, error) {
+
+	if session.EncryptionAlgorithm == Plain {
+		return secretApi.Value, nil
+	}
+
+	plainSecret, err := crypto.AesCBCDecrypt(secretApi.Parameters,
+		secretApi.Value, session.SymmetricKey)
+
+	if err != nil {
+		return nil, errors.New("Decryption error: " + err.Error())
+	}
+
+	return plainSecret, nil
+}
@@ -5,10 +5,14 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	// log "github.com/sirupsen/logrus"
 )
 
@@ -150,3 +154,67 @@ func DecryptAESCBC256(key string, cipherText string) (string, error) {
 	return string(plaintext), nil
 
 }
+
+////////////////////////////// password hashing //////////////////////////////
+
+// argon2id parameters used for new password hashes
+const (
+	argon2Time    uint32 = 3
+	argon2Memory  uint32 = 64 * 1024 // KiB
+	argon2Threads uint8  = 2
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen int    = 16
+)
+
+// HashPassword returns argon2id hash of password in PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<base64 salt>$<base64 hash>
+func HashPassword(password string) (string, error) {
+
+	salt := make([]byte, argon2SaltLen)
+
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("cannot read random bytes. Error: %v", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// VerifyPassword returns true if password matches the hash made by HashPassword
+func VerifyPassword(password string, encodedHash string) (bool, error) {
+
+	parts := strings.Split(encodedHash, "$")
+
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("invalid password hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errors.New("unsupported argon2 version")
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, fmt.Errorf("invalid argon2 parameters. Error: %v", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("cannot base64 decode salt. Error: %v", err)
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("cannot base64 decode hash. Error: %v", err)
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(hash)))
+
+	return subtle.ConstantTimeCompare(hash, candidate) == 1, nil
+}
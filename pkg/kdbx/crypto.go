@@ -0,0 +1,420 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+	"golang.org/x/crypto/twofish"
+)
+
+// cipher and key derivation identifiers (uuid)
+var (
+	cipherAES256   = mustHex("31c1f2e6bf714350be5805216afc5aff")
+	cipherChaCha20 = mustHex("d6038a2b8b6f4cb5a524339a31dbb59a")
+	cipherTwofish  = mustHex("ad68f29f576f4bb9a36ad47af965346c")
+	kdfAES         = mustHex("c9d9f39a628a4460bf740d08c18a4fea")
+	kdfAESKdbx3    = mustHex("7c02bb8279a74ac0927d114a00648238")
+	kdfArgon2d     = mustHex("ef636ddf8c29444b91f7a9a403e30a0c")
+	kdfArgon2id    = mustHex("9e298b1956db4773b23dfc3ec6f0a1e6")
+)
+
+// inner random stream identifiers (protected values)
+const (
+	streamNone     = 0
+	streamSalsa20  = 2
+	streamChaCha20 = 3
+)
+
+// mustHex decodes a hex constant
+func mustHex(text string) []byte {
+	data, err := hex.DecodeString(text)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> VariantDictionary >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// value types of variant dictionary
+const (
+	variantEnd    = 0x00
+	variantUInt32 = 0x04
+	variantUInt64 = 0x05
+	variantBool   = 0x08
+	variantInt32  = 0x0C
+	variantInt64  = 0x0D
+	variantString = 0x18
+	variantBytes  = 0x42
+)
+
+// variant is a typed value of variant dictionary
+type variant struct {
+	kind  byte
+	value []byte
+}
+
+// variantDictionary holds key derivation parameters
+type variantDictionary map[string]variant
+
+// readVariantDictionary parses a serialized variant dictionary
+func readVariantDictionary(data []byte) (variantDictionary, error) {
+
+	if len(data) < 2 || binary.LittleEndian.Uint16(data)&0xFF00 != 0x0100 {
+		return nil, errors.New("unsupported key derivation parameters version")
+	}
+
+	dictionary := variantDictionary{}
+	position := 2
+
+	for position < len(data) {
+
+		kind := data[position]
+		position++
+
+		if kind == variantEnd {
+			return dictionary, nil
+		}
+
+		if position+4 > len(data) {
+			break
+		}
+
+		nameLength := int(binary.LittleEndian.Uint32(data[position:]))
+		position += 4
+
+		if nameLength < 0 || position+nameLength+4 > len(data) {
+			break
+		}
+
+		name := string(data[position : position+nameLength])
+		position += nameLength
+		valueLength := int(binary.LittleEndian.Uint32(data[position:]))
+		position += 4
+
+		if valueLength < 0 || position+valueLength > len(data) {
+			break
+		}
+
+		dictionary[name] = variant{kind: kind, value: data[position : position+valueLength]}
+		position += valueLength
+	}
+
+	return nil, errors.New("corrupted database: malformed key derivation parameters")
+}
+
+// bytes returns a byte array value
+func (dictionary variantDictionary) bytes(name string) ([]byte, error) {
+
+	value, ok := dictionary[name]
+
+	if !ok || value.kind != variantBytes {
+		return nil, fmt.Errorf("missing key derivation parameter '%s'", name)
+	}
+
+	return value.value, nil
+}
+
+// uint returns an unsigned integer value
+func (dictionary variantDictionary) uint(name string) (uint64, error) {
+
+	value, ok := dictionary[name]
+
+	switch {
+	case ok && value.kind == variantUInt32 && len(value.value) == 4:
+		return uint64(binary.LittleEndian.Uint32(value.value)), nil
+	case ok && value.kind == variantUInt64 && len(value.value) == 8:
+		return binary.LittleEndian.Uint64(value.value), nil
+	default:
+		return 0, fmt.Errorf("missing key derivation parameter '%s'", name)
+	}
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< VariantDictionary <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Keys >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// transformKey derives the transformed key of composite key
+func transformKey(key *Key, kdf variantDictionary) ([]byte, error) {
+
+	uuid, err := kdf.bytes("$UUID")
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+
+	case bytes.Equal(uuid, kdfAES) || bytes.Equal(uuid, kdfAESKdbx3):
+
+		seed, err := kdf.bytes("S")
+		if err != nil {
+			return nil, err
+		}
+		rounds, err := kdf.uint("R")
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := aes.NewCipher(seed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AES-KDF seed: %v", err)
+		}
+
+		transformed := key.hash
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(transformed[0:16], transformed[0:16])
+			block.Encrypt(transformed[16:32], transformed[16:32])
+		}
+
+		hash := sha256.Sum256(transformed[:])
+		return hash[:], nil
+
+	case bytes.Equal(uuid, kdfArgon2d) || bytes.Equal(uuid, kdfArgon2id):
+
+		mode := argon2d
+		if bytes.Equal(uuid, kdfArgon2id) {
+			mode = argon2id
+		}
+
+		salt, err := kdf.bytes("S")
+		if err != nil {
+			return nil, err
+		}
+		parallelism, err := kdf.uint("P")
+		if err != nil {
+			return nil, err
+		}
+		memory, err := kdf.uint("M")
+		if err != nil {
+			return nil, err
+		}
+		iterations, err := kdf.uint("I")
+		if err != nil {
+			return nil, err
+		}
+		version, err := kdf.uint("V")
+		if err != nil {
+			return nil, err
+		}
+
+		if version != argon2Version {
+			return nil, fmt.Errorf("unsupported Argon2 version 0x%x", version)
+		}
+
+		if parallelism < 1 || parallelism > 255 || iterations < 1 || iterations > 1<<32-1 ||
+			memory < 8*1024 || memory/1024 > 1<<32-1 {
+			return nil, errors.New("unsupported Argon2 parameters")
+		}
+
+		// optional secret key and associated data
+		secret, _ := kdf.bytes("K")
+		data, _ := kdf.bytes("A")
+
+		return argon2Key(mode, key.hash[:], salt, secret, data, uint32(iterations),
+			uint32(memory/1024), uint8(parallelism), 32), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function %x", uuid)
+	}
+}
+
+// hmacBaseKey returns the key which block keys are derived from
+func hmacBaseKey(masterSeed []byte, transformedKey []byte) []byte {
+	hash := sha512.New()
+	hash.Write(masterSeed)
+	hash.Write(transformedKey)
+	hash.Write([]byte{1})
+	return hash.Sum(nil)
+}
+
+// blockKey returns HMAC key of the block with given index
+func blockKey(hmacKey []byte, index uint64) []byte {
+	var indexBytes [8]byte
+	binary.LittleEndian.PutUint64(indexBytes[:], index)
+	hash := sha512.New()
+	hash.Write(indexBytes[:])
+	hash.Write(hmacKey)
+	return hash.Sum(nil)
+}
+
+// headerHMAC returns HMAC of outer header
+func headerHMAC(hmacKey []byte, header []byte) []byte {
+	mac := hmac.New(sha256.New, blockKey(hmacKey, ^uint64(0)))
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+// blockHMAC returns HMAC of a payload block
+func blockHMAC(hmacKey []byte, index uint64, block []byte) []byte {
+	var prefix [12]byte
+	binary.LittleEndian.PutUint64(prefix[0:], index)
+	binary.LittleEndian.PutUint32(prefix[8:], uint32(len(block)))
+	mac := hmac.New(sha256.New, blockKey(hmacKey, index))
+	mac.Write(prefix[:])
+	mac.Write(block)
+	return mac.Sum(nil)
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Keys <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Payload >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// readBlocks verifies HMAC of payload blocks and returns their content
+func readBlocks(data []byte, hmacKey []byte) ([]byte, error) {
+
+	var payload []byte
+	position := 0
+
+	for index := uint64(0); ; index++ {
+
+		if position+36 > len(data) {
+			return nil, errors.New("corrupted database: truncated payload")
+		}
+
+		mac := data[position : position+32]
+		size := int(int32(binary.LittleEndian.Uint32(data[position+32:])))
+		position += 36
+
+		if size < 0 || position+size > len(data) {
+			return nil, errors.New("corrupted database: truncated payload")
+		}
+
+		block := data[position : position+size]
+		position += size
+
+		if !hmac.Equal(mac, blockHMAC(hmacKey, index, block)) {
+			return nil, fmt.Errorf("corrupted database: block %d checksum mismatch", index)
+		}
+
+		if size == 0 {
+			return payload, nil
+		}
+
+		payload = append(payload, block...)
+	}
+}
+
+// decryptPayload decrypts payload by the cipher of header
+func decryptPayload(header *header, transformedKey []byte, payload []byte) ([]byte, error) {
+
+	key := sha256.Sum256(append(append([]byte{}, header.masterSeed...), transformedKey...))
+
+	switch {
+
+	case bytes.Equal(header.cipherID, cipherChaCha20):
+		stream, err := chacha20.NewUnauthenticatedCipher(key[:], header.iv)
+		if err != nil {
+			return nil, fmt.Errorf("corrupted database: %v", err)
+		}
+		plain := make([]byte, len(payload))
+		stream.XORKeyStream(plain, payload)
+		return plain, nil
+
+	case bytes.Equal(header.cipherID, cipherAES256) || bytes.Equal(header.cipherID, cipherTwofish):
+		var block cipher.Block
+		var err error
+		if bytes.Equal(header.cipherID, cipherAES256) {
+			block, err = aes.NewCipher(key[:])
+		} else {
+			block, err = twofish.NewCipher(key[:])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(header.iv) != block.BlockSize() || len(payload) == 0 || len(payload)%block.BlockSize() != 0 {
+			return nil, errors.New("corrupted database: invalid encrypted payload")
+		}
+		plain := make([]byte, len(payload))
+		cipher.NewCBCDecrypter(block, header.iv).CryptBlocks(plain, payload)
+		// PKCS#7 padding
+		padding := int(plain[len(plain)-1])
+		if padding < 1 || padding > block.BlockSize() {
+			return nil, errors.New("corrupted database: invalid padding")
+		}
+		return plain[:len(plain)-padding], nil
+
+	default:
+		return nil, fmt.Errorf("unsupported cipher %x", header.cipherID)
+	}
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Payload <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Protected values >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// protectedStream is the key stream protected values are XORed with, in
+// document order
+type protectedStream struct {
+	// chacha20 stream (nil for Salsa20 or no protection)
+	chacha *chacha20.Cipher
+	// Salsa20 key and counter (nonce and block counter)
+	salsaKey     [32]byte
+	salsaCounter [16]byte
+	// unused Salsa20 key stream
+	salsaBuffer []byte
+	// TRUE for Salsa20
+	salsa bool
+}
+
+// newProtectedStream returns key stream of inner header stream id and key
+func newProtectedStream(id uint32, key []byte) (*protectedStream, error) {
+
+	switch id {
+
+	case streamNone:
+		return &protectedStream{}, nil
+
+	case streamChaCha20:
+		hash := sha512.Sum512(key)
+		chacha, err := chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+		if err != nil {
+			return nil, err
+		}
+		return &protectedStream{chacha: chacha}, nil
+
+	case streamSalsa20:
+		stream := &protectedStream{salsa: true, salsaKey: sha256.Sum256(key)}
+		copy(stream.salsaCounter[:8], []byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A})
+		return stream, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported inner random stream %d", id)
+	}
+}
+
+// xor XORs data with next bytes of key stream in place
+func (stream *protectedStream) xor(data []byte) {
+
+	if stream.chacha != nil {
+		stream.chacha.XORKeyStream(data, data)
+		return
+	}
+
+	if !stream.salsa {
+		return
+	}
+
+	for i := range data {
+		if len(stream.salsaBuffer) == 0 {
+			stream.salsaBuffer = make([]byte, 64)
+			salsa.XORKeyStream(stream.salsaBuffer, stream.salsaBuffer, &stream.salsaCounter, &stream.salsaKey)
+			binary.LittleEndian.PutUint64(stream.salsaCounter[8:],
+				binary.LittleEndian.Uint64(stream.salsaCounter[8:])+1)
+		}
+		data[i] ^= stream.salsaBuffer[0]
+		stream.salsaBuffer = stream.salsaBuffer[1:]
+	}
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Protected values <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -0,0 +1,377 @@
+// Package kdbx reads and writes KeePass 2.x databases in KDBX 4 format
+// (KeePass, KeePassXC, KeePassDX...). Supported key derivations are Argon2d,
+// Argon2id and AES-KDF, supported ciphers are AES-256, ChaCha20 and Twofish.
+// Composite key is a password and/or a key file.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// file signature of KeePass 2.x databases
+const (
+	signature1 uint32 = 0x9AA2D903
+	signature2 uint32 = 0xB54BFB67
+)
+
+// ErrNotKdbx is returned if a file is not a KeePass 2.x database
+var ErrNotKdbx = errors.New("not a KeePass 2.x (kdbx) database")
+
+// ErrInvalidKey is returned if password or key file is wrong
+var ErrInvalidKey = errors.New("wrong password or key file")
+
+// Database is the content of a KDBX database
+type Database struct {
+	// database name
+	Name string
+	// root group, parent of all groups and entries
+	Root *Group
+}
+
+// Group is a folder of entries and groups
+type Group struct {
+	// group unique identifier
+	UUID [16]byte
+	// group name
+	Name string
+	// group notes
+	Notes string
+	// group creation time
+	Created time.Time
+	// group modification time
+	Modified time.Time
+	// TRUE if this is the recycle bin of database
+	RecycleBin bool
+	// child groups
+	Groups []*Group
+	// entries of this group
+	Entries []*Entry
+}
+
+// Entry is a login (or any secret) with its fields
+type Entry struct {
+	// entry unique identifier
+	UUID [16]byte
+	// entry fields in file order (standard fields: Title, UserName,
+	// Password, URL and Notes)
+	Fields []Field
+	// entry tags separated by ';' or ','
+	Tags string
+	// entry creation time
+	Created time.Time
+	// entry modification time
+	Modified time.Time
+}
+
+// Field is a standard or custom field of an entry
+type Field struct {
+	// field name
+	Key string
+	// field value
+	Value string
+	// TRUE if value is protected in memory (i.e. password)
+	Protected bool
+}
+
+// Get returns value of the field with given key, empty if it is missing
+func (entry *Entry) Get(key string) string {
+	for _, field := range entry.Fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Key is the composite key of a database
+type Key struct {
+	// sha256 of concatenated component hashes
+	hash [32]byte
+}
+
+// NewKey returns composite key of a password and/or a key file (content).
+// Empty password means no password, nil key file means no key file.
+func NewKey(password string, keyFile []byte) (*Key, error) {
+
+	if password == "" && keyFile == nil {
+		return nil, errors.New("a password or a key file is required")
+	}
+
+	var components []byte
+
+	if password != "" {
+		hash := sha256.Sum256([]byte(password))
+		components = append(components, hash[:]...)
+	}
+
+	if keyFile != nil {
+		hash, err := keyFileHash(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, hash...)
+	}
+
+	return &Key{hash: sha256.Sum256(components)}, nil
+}
+
+// keyFilePattern matches KeePass xml key files (version 1.0 and 2.0)
+var keyFilePattern = regexp.MustCompile(`(?s)<KeyFile>.*<Version>\s*([0-9.]+)\s*</Version>.*<Data(?:\s+Hash="([0-9A-Fa-f]*)")?\s*>\s*([^<]*?)\s*</Data>`)
+
+// keyFileHash returns key of a key file: xml (KeePass), 32 bytes binary,
+// 64 hex characters or sha256 of any other file
+func keyFileHash(keyFile []byte) ([]byte, error) {
+
+	if match := keyFilePattern.FindSubmatch(keyFile); match != nil {
+
+		version, checksum, data := string(match[1]), string(match[2]), string(match[3])
+
+		switch {
+		case strings.HasPrefix(version, "1."):
+			key, err := decodeBase64(data)
+			if err != nil {
+				return nil, fmt.Errorf("malformed key file: %v", err)
+			}
+			return key, nil
+
+		case strings.HasPrefix(version, "2."):
+			data = strings.Join(strings.Fields(data), "")
+			key, err := hex.DecodeString(data)
+			if err != nil {
+				return nil, fmt.Errorf("malformed key file: %v", err)
+			}
+			hash := sha256.Sum256(key)
+			if checksum != "" && !strings.EqualFold(checksum, hex.EncodeToString(hash[:4])) {
+				return nil, errors.New("malformed key file: checksum mismatch")
+			}
+			return key, nil
+
+		default:
+			return nil, fmt.Errorf("unsupported key file version '%s'", version)
+		}
+	}
+
+	if len(keyFile) == 32 {
+		return keyFile, nil
+	}
+
+	if len(keyFile) == 64 {
+		if key, err := hex.DecodeString(string(keyFile)); err == nil {
+			return key, nil
+		}
+	}
+
+	hash := sha256.Sum256(keyFile)
+
+	return hash[:], nil
+}
+
+// outer header fields of KDBX 4
+const (
+	headerEnd              = 0
+	headerCipherID         = 2
+	headerCompressionFlags = 3
+	headerMasterSeed       = 4
+	headerEncryptionIV     = 7
+	headerKdfParameters    = 11
+)
+
+// inner header fields of KDBX 4
+const (
+	innerHeaderEnd       = 0
+	innerHeaderStreamID  = 1
+	innerHeaderStreamKey = 2
+)
+
+// header is the outer (plain) header of a KDBX 4 file
+type header struct {
+	cipherID   []byte
+	compressed bool
+	masterSeed []byte
+	iv         []byte
+	kdf        variantDictionary
+}
+
+// Read decrypts and parses a KDBX 4 database
+func Read(reader io.Reader, key *Key) (*Database, error) {
+
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 ||
+		binary.LittleEndian.Uint32(data[0:]) != signature1 ||
+		binary.LittleEndian.Uint32(data[4:]) != signature2 {
+		return nil, ErrNotKdbx
+	}
+
+	if major := binary.LittleEndian.Uint32(data[8:]) >> 16; major != 4 {
+		return nil, fmt.Errorf("KDBX %d.x is not supported, save database as KDBX 4", major)
+	}
+
+	header, headerLength, err := readHeader(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < headerLength+64 {
+		return nil, errors.New("corrupted database: truncated header")
+	}
+
+	headerHash := sha256.Sum256(data[:headerLength])
+
+	if !bytes.Equal(headerHash[:], data[headerLength:headerLength+32]) {
+		return nil, errors.New("corrupted database: header checksum mismatch")
+	}
+
+	transformedKey, err := transformKey(key, header.kdf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hmacKey := hmacBaseKey(header.masterSeed, transformedKey)
+
+	if !bytes.Equal(headerHMAC(hmacKey, data[:headerLength]), data[headerLength+32:headerLength+64]) {
+		return nil, ErrInvalidKey
+	}
+
+	payload, err := readBlocks(data[headerLength+64:], hmacKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err = decryptPayload(header, transformedKey, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if header.compressed {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("corrupted database: %v", err)
+		}
+		payload, err = ioutil.ReadAll(gzipReader)
+		if err != nil {
+			return nil, fmt.Errorf("corrupted database: %v", err)
+		}
+	}
+
+	stream, xmlData, err := readInnerHeader(payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(xmlData, stream)
+}
+
+// readHeader parses outer header and returns it with its length
+func readHeader(data []byte) (*header, int, error) {
+
+	result := &header{}
+	position := 12
+
+	for {
+		if position+5 > len(data) {
+			return nil, 0, errors.New("corrupted database: truncated header")
+		}
+
+		id := data[position]
+		size := int(binary.LittleEndian.Uint32(data[position+1:]))
+		position += 5
+
+		if size < 0 || position+size > len(data) {
+			return nil, 0, errors.New("corrupted database: truncated header")
+		}
+
+		value := data[position : position+size]
+		position += size
+
+		switch id {
+		case headerEnd:
+			if result.cipherID == nil || result.masterSeed == nil || result.iv == nil || result.kdf == nil {
+				return nil, 0, errors.New("corrupted database: missing header fields")
+			}
+			return result, position, nil
+		case headerCipherID:
+			result.cipherID = value
+		case headerCompressionFlags:
+			if len(value) != 4 || binary.LittleEndian.Uint32(value) > 1 {
+				return nil, 0, errors.New("unsupported compression")
+			}
+			result.compressed = binary.LittleEndian.Uint32(value) == 1
+		case headerMasterSeed:
+			if len(value) != 32 {
+				return nil, 0, errors.New("corrupted database: invalid master seed")
+			}
+			result.masterSeed = value
+		case headerEncryptionIV:
+			result.iv = value
+		case headerKdfParameters:
+			kdf, err := readVariantDictionary(value)
+			if err != nil {
+				return nil, 0, err
+			}
+			result.kdf = kdf
+		}
+	}
+}
+
+// readInnerHeader parses inner header of decrypted payload and returns
+// protected value stream and xml document
+func readInnerHeader(payload []byte) (*protectedStream, []byte, error) {
+
+	var streamID uint32
+	var streamKey []byte
+	position := 0
+
+	for {
+		if position+5 > len(payload) {
+			return nil, nil, errors.New("corrupted database: truncated inner header")
+		}
+
+		id := payload[position]
+		size := int(binary.LittleEndian.Uint32(payload[position+1:]))
+		position += 5
+
+		if size < 0 || position+size > len(payload) {
+			return nil, nil, errors.New("corrupted database: truncated inner header")
+		}
+
+		value := payload[position : position+size]
+		position += size
+
+		switch id {
+		case innerHeaderEnd:
+			stream, err := newProtectedStream(streamID, streamKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return stream, payload[position:], nil
+		case innerHeaderStreamID:
+			if len(value) != 4 {
+				return nil, nil, errors.New("corrupted database: invalid inner stream")
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerHeaderStreamKey:
+			streamKey = value
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package kdbx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yousefvand/secret-service/pkg/kdbx"
+)
+
+func TestRead(t *testing.T) {
+
+	keyFile, _ := ioutil.ReadFile(filepath.Join("testdata", "keyfile.keyx"))
+	password, _ := kdbx.NewKey("secretservice", nil)
+	passwordAndKeyFile, err := kdbx.NewKey("secretservice", keyFile)
+
+	if err != nil {
+		t.Fatalf("NewKey failed. Error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		file string
+		key  *kdbx.Key
+	}{
+		{"argon2d-chacha20.kdbx", password},
+		{"argon2id-aes.kdbx", password},
+		{"aeskdf-aes-keyfile.kdbx", passwordAndKeyFile},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+
+			file, _ := os.Open(filepath.Join("testdata", tc.file))
+			defer file.Close()
+
+			db, err := kdbx.Read(file, tc.key)
+
+			if err != nil {
+				t.Fatalf("Read failed. Error: %v", err)
+			}
+
+			if db.Name != "Test & Vault" || db.Root.Name != "Passwords" {
+				t.Errorf("Unexpected database '%s' root '%s'", db.Name, db.Root.Name)
+			}
+
+			if len(db.Root.Entries) != 1 || len(db.Root.Groups) != 2 {
+				t.Fatalf("Expected 1 entry and 2 groups, got: %d %d", len(db.Root.Entries), len(db.Root.Groups))
+			}
+
+			github := db.Root.Entries[0]
+
+			// history entry with an older password is dropped
+			for key, value := range map[string]string{
+				"Title":    "GitHub",
+				"UserName": "joe",
+				"Password": "P@ssw0rd",
+				"URL":      "https://github.com",
+				"Notes":    "line1\nline2",
+				"env":      "prod",
+				"recovery": "R3C0V3RY",
+			} {
+				if github.Get(key) != value {
+					t.Errorf("Expected %s '%s', got: '%s'", key, value, github.Get(key))
+				}
+			}
+
+			if github.Tags != "work;dev" {
+				t.Errorf("Expected tags 'work;dev', got: '%s'", github.Tags)
+			}
+
+			if !github.Created.Equal(time.Date(2021, 5, 4, 10, 20, 30, 0, time.UTC)) ||
+				!github.Modified.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)) {
+				t.Errorf("Unexpected times: %v %v", github.Created, github.Modified)
+			}
+
+			email, recycleBin := db.Root.Groups[0], db.Root.Groups[1]
+
+			if email.Name != "Email" || email.RecycleBin || !recycleBin.RecycleBin {
+				t.Errorf("Unexpected groups: %+v %+v", email, recycleBin)
+			}
+
+			if password := email.Entries[0].Get("Password"); password != "gm@il <&>" {
+				t.Errorf("Expected password 'gm@il <&>', got: '%s'", password)
+			}
+
+			if outlook := email.Groups[0].Entries[0]; outlook.Get("Password") != "0utl00k" {
+				t.Errorf("Expected password '0utl00k', got: '%s'", outlook.Get("Password"))
+			}
+		})
+	}
+}
+
+func TestRead_errors(t *testing.T) {
+
+	wrong, _ := kdbx.NewKey("wrong", nil)
+
+	file, _ := os.Open(filepath.Join("testdata", "argon2d-chacha20.kdbx"))
+	defer file.Close()
+
+	if _, err := kdbx.Read(file, wrong); err != kdbx.ErrInvalidKey {
+		t.Errorf("Expected '%v', got: %v", kdbx.ErrInvalidKey, err)
+	}
+
+	keyFile, _ := os.Open(filepath.Join("testdata", "keyfile.keyx"))
+	defer keyFile.Close()
+
+	if _, err := kdbx.Read(keyFile, wrong); err != kdbx.ErrNotKdbx {
+		t.Errorf("Expected '%v', got: %v", kdbx.ErrNotKdbx, err)
+	}
+
+	if _, err := kdbx.NewKey("", nil); err == nil {
+		t.Error("Expected a key without password and key file to fail")
+	}
+}
+
+func TestWrite(t *testing.T) {
+
+	key, _ := kdbx.NewKey("secretservice", nil)
+	created := time.Date(2021, 5, 4, 10, 20, 30, 0, time.UTC)
+	modified := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	db := &kdbx.Database{
+		Name: "secretservice",
+		Root: &kdbx.Group{
+			Name: "secretservice",
+			Groups: []*kdbx.Group{{
+				Name:    "default",
+				Created: created,
+				Entries: []*kdbx.Entry{{
+					Fields: []kdbx.Field{
+						{Key: "Title", Value: "GitHub"},
+						{Key: "UserName", Value: "joe <&>"},
+						{Key: "Password", Value: "P@ssw0rd", Protected: true},
+						{Key: "env", Value: "line1\nline2"},
+						{Key: "pin", Value: "1234", Protected: true},
+					},
+					Created:  created,
+					Modified: modified,
+				}},
+			}},
+		},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := kdbx.Write(&buffer, db, key); err != nil {
+		t.Fatalf("Write failed. Error: %v", err)
+	}
+
+	read, err := kdbx.Read(bytes.NewReader(buffer.Bytes()), key)
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	if read.Name != "secretservice" || len(read.Root.Groups) != 1 ||
+		len(read.Root.Groups[0].Entries) != 1 {
+		t.Fatalf("Unexpected database: %+v", read)
+	}
+
+	group := read.Root.Groups[0]
+
+	if group.Name != "default" || !group.Created.Equal(created) || group.UUID == [16]byte{} {
+		t.Errorf("Unexpected group: %+v", group)
+	}
+
+	entry := group.Entries[0]
+
+	if !reflect.DeepEqual(entry.Fields, db.Root.Groups[0].Entries[0].Fields) {
+		t.Errorf("Expected fields %v, got: %v", db.Root.Groups[0].Entries[0].Fields, entry.Fields)
+	}
+
+	if !entry.Created.Equal(created) || !entry.Modified.Equal(modified) {
+		t.Errorf("Unexpected times: %v %v", entry.Created, entry.Modified)
+	}
+
+	wrong, _ := kdbx.NewKey("wrong", nil)
+
+	if _, err := kdbx.Read(bytes.NewReader(buffer.Bytes()), wrong); err != kdbx.ErrInvalidKey {
+		t.Errorf("Expected '%v', got: %v", kdbx.ErrInvalidKey, err)
+	}
+}
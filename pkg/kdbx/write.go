@@ -0,0 +1,349 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Argon2id parameters and payload block size of written databases
+const (
+	writeArgon2Memory     = 64 * 1024 * 1024
+	writeArgon2Iterations = 3
+	writeArgon2Lanes      = 2
+	writeBlockSize        = 1024 * 1024
+)
+
+// Write encrypts db by key and writes it in KDBX 4 format (Argon2id key
+// derivation, AES-256 cipher, gzip compression and ChaCha20 protected
+// values). Missing uuids and times are generated.
+func Write(writer io.Writer, db *Database, key *Key) error {
+
+	masterSeed, err := randomBytes(32)
+
+	if err != nil {
+		return err
+	}
+
+	iv, err := randomBytes(16)
+
+	if err != nil {
+		return err
+	}
+
+	salt, err := randomBytes(32)
+
+	if err != nil {
+		return err
+	}
+
+	kdf := variantDictionary{
+		"$UUID": {variantBytes, kdfArgon2id},
+		"S":     {variantBytes, salt},
+		"P":     {variantUInt32, uint32Bytes(writeArgon2Lanes)},
+		"M":     {variantUInt64, uint64Bytes(writeArgon2Memory)},
+		"I":     {variantUInt64, uint64Bytes(writeArgon2Iterations)},
+		"V":     {variantUInt32, uint32Bytes(argon2Version)},
+	}
+
+	var head bytes.Buffer
+	binary.Write(&head, binary.LittleEndian, signature1)
+	binary.Write(&head, binary.LittleEndian, signature2)
+	binary.Write(&head, binary.LittleEndian, uint32(0x00040000))
+	writeField(&head, headerCipherID, cipherAES256)
+	writeField(&head, headerCompressionFlags, uint32Bytes(1))
+	writeField(&head, headerMasterSeed, masterSeed)
+	writeField(&head, headerEncryptionIV, iv)
+	writeField(&head, headerKdfParameters, kdf.serialize())
+	writeField(&head, headerEnd, []byte{0x0D, 0x0A, 0x0D, 0x0A})
+
+	transformedKey, err := transformKey(key, kdf)
+
+	if err != nil {
+		return err
+	}
+
+	streamKey, err := randomBytes(64)
+
+	if err != nil {
+		return err
+	}
+
+	stream, err := newProtectedStream(streamChaCha20, streamKey)
+
+	if err != nil {
+		return err
+	}
+
+	var inner bytes.Buffer
+	writeField(&inner, innerHeaderStreamID, uint32Bytes(streamChaCha20))
+	writeField(&inner, innerHeaderStreamKey, streamKey)
+	writeField(&inner, innerHeaderEnd, nil)
+
+	if err := writeXML(&inner, db, stream); err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+
+	if _, err := gzipWriter.Write(inner.Bytes()); err != nil {
+		return err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+
+	payload, err := encryptPayload(masterSeed, iv, transformedKey, compressed.Bytes())
+
+	if err != nil {
+		return err
+	}
+
+	hmacKey := hmacBaseKey(masterSeed, transformedKey)
+	headerHash := sha256.Sum256(head.Bytes())
+
+	var out bytes.Buffer
+	out.Write(head.Bytes())
+	out.Write(headerHash[:])
+	out.Write(headerHMAC(hmacKey, head.Bytes()))
+
+	for index := uint64(0); ; index++ {
+		size := len(payload)
+		if size > writeBlockSize {
+			size = writeBlockSize
+		}
+		out.Write(blockHMAC(hmacKey, index, payload[:size]))
+		binary.Write(&out, binary.LittleEndian, uint32(size))
+		out.Write(payload[:size])
+		payload = payload[size:]
+		// an empty block marks the end of payload
+		if size == 0 {
+			break
+		}
+	}
+
+	_, err = writer.Write(out.Bytes())
+
+	return err
+}
+
+// encryptPayload encrypts payload by AES-256 (CBC, PKCS#7 padding)
+func encryptPayload(masterSeed []byte, iv []byte, transformedKey []byte, payload []byte) ([]byte, error) {
+
+	key := sha256.Sum256(append(append([]byte{}, masterSeed...), transformedKey...))
+	block, err := aes.NewCipher(key[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	padding := aes.BlockSize - len(payload)%aes.BlockSize
+	encrypted := append(append([]byte{}, payload...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	return encrypted, nil
+}
+
+// serialize returns binary form of variant dictionary
+func (dictionary variantDictionary) serialize() []byte {
+
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, uint16(0x0100))
+
+	// fixed order keeps output deterministic
+	for _, name := range []string{"$UUID", "S", "P", "M", "I", "V", "K", "A", "R"} {
+		value, ok := dictionary[name]
+		if !ok {
+			continue
+		}
+		buffer.WriteByte(value.kind)
+		binary.Write(&buffer, binary.LittleEndian, uint32(len(name)))
+		buffer.WriteString(name)
+		binary.Write(&buffer, binary.LittleEndian, uint32(len(value.value)))
+		buffer.Write(value.value)
+	}
+
+	buffer.WriteByte(variantEnd)
+
+	return buffer.Bytes()
+}
+
+// writeField writes a header field (id, size and value)
+func writeField(buffer *bytes.Buffer, id byte, value []byte) {
+	buffer.WriteByte(id)
+	binary.Write(buffer, binary.LittleEndian, uint32(len(value)))
+	buffer.Write(value)
+}
+
+// randomBytes returns n cryptographically secure random bytes
+func randomBytes(n int) ([]byte, error) {
+	data := make([]byte, n)
+	_, err := io.ReadFull(rand.Reader, data)
+	return data, err
+}
+
+// uint32Bytes returns little endian bytes of value
+func uint32Bytes(value uint32) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, value)
+	return data
+}
+
+// uint64Bytes returns little endian bytes of value
+func uint64Bytes(value uint64) []byte {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, value)
+	return data
+}
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> XML >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// xmlWriter writes xml document of a KDBX file, protected values are
+// XORed by key stream in document order
+type xmlWriter struct {
+	buffer *bytes.Buffer
+	stream *protectedStream
+	now    time.Time
+	err    error
+}
+
+// writeXML writes xml document of db
+func writeXML(buffer *bytes.Buffer, db *Database, stream *protectedStream) error {
+
+	writer := &xmlWriter{buffer: buffer, stream: stream, now: time.Now().UTC()}
+
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	buffer.WriteString("<KeePassFile><Meta>")
+	writer.element("Generator", "secretservice")
+	writer.element("DatabaseName", db.Name)
+	writer.element("DatabaseNameChanged", formatTime(writer.now))
+	writer.element("RecycleBinEnabled", "False")
+	buffer.WriteString("</Meta><Root>")
+
+	root := db.Root
+
+	if root == nil {
+		root = &Group{Name: db.Name}
+	}
+
+	writer.group(root)
+	buffer.WriteString("<DeletedObjects/></Root></KeePassFile>")
+
+	return writer.err
+}
+
+// group writes a Group element with its entries and child groups
+func (writer *xmlWriter) group(group *Group) {
+
+	writer.buffer.WriteString("<Group>")
+	writer.element("UUID", writer.uuid(group.UUID))
+	writer.element("Name", group.Name)
+	writer.element("Notes", group.Notes)
+	writer.element("IconID", "48")
+	writer.times(group.Created, group.Modified)
+	writer.element("IsExpanded", "True")
+
+	for _, entry := range group.Entries {
+		writer.entry(entry)
+	}
+
+	for _, child := range group.Groups {
+		writer.group(child)
+	}
+
+	writer.buffer.WriteString("</Group>")
+}
+
+// entry writes an Entry element
+func (writer *xmlWriter) entry(entry *Entry) {
+
+	writer.buffer.WriteString("<Entry>")
+	writer.element("UUID", writer.uuid(entry.UUID))
+	writer.element("IconID", "0")
+	writer.element("Tags", entry.Tags)
+	writer.times(entry.Created, entry.Modified)
+
+	for _, field := range entry.Fields {
+		writer.buffer.WriteString("<String>")
+		writer.element("Key", field.Key)
+		if field.Protected {
+			value := []byte(field.Value)
+			writer.stream.xor(value)
+			writer.buffer.WriteString(`<Value Protected="True">`)
+			writer.buffer.WriteString(base64.StdEncoding.EncodeToString(value))
+			writer.buffer.WriteString("</Value>")
+		} else {
+			writer.element("Value", field.Value)
+		}
+		writer.buffer.WriteString("</String>")
+	}
+
+	writer.buffer.WriteString("<AutoType><Enabled>True</Enabled><DataTransferObfuscation>0</DataTransferObfuscation></AutoType>")
+	writer.buffer.WriteString("</Entry>")
+}
+
+// times writes a Times element, zero times are replaced by current time
+func (writer *xmlWriter) times(created time.Time, modified time.Time) {
+
+	if created.IsZero() {
+		created = writer.now
+	}
+
+	if modified.IsZero() {
+		modified = created
+	}
+
+	writer.buffer.WriteString("<Times>")
+	writer.element("CreationTime", formatTime(created))
+	writer.element("LastModificationTime", formatTime(modified))
+	writer.element("LastAccessTime", formatTime(modified))
+	writer.element("ExpiryTime", formatTime(modified))
+	writer.element("Expires", "False")
+	writer.element("UsageCount", "0")
+	writer.element("LocationChanged", formatTime(modified))
+	writer.buffer.WriteString("</Times>")
+}
+
+// uuid returns base64 of uuid, a random one if it is zero
+func (writer *xmlWriter) uuid(uuid [16]byte) string {
+
+	if uuid == [16]byte{} {
+		data, err := randomBytes(16)
+		if err != nil && writer.err == nil {
+			writer.err = err
+		}
+		copy(uuid[:], data)
+	}
+
+	return base64.StdEncoding.EncodeToString(uuid[:])
+}
+
+// element writes an element with escaped text
+func (writer *xmlWriter) element(name string, text string) {
+
+	if text == "" {
+		writer.buffer.WriteString("<" + name + "/>")
+		return
+	}
+
+	writer.buffer.WriteString("<" + name + ">")
+	xml.EscapeText(writer.buffer, []byte(text))
+	writer.buffer.WriteString("</" + name + ">")
+}
+
+// formatTime returns a KDBX 4 time (base64 seconds since 0001-01-01)
+func formatTime(t time.Time) string {
+	return base64.StdEncoding.EncodeToString(uint64Bytes(uint64(t.Unix() - kdbxEpoch.Unix())))
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< XML <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
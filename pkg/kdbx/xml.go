@@ -0,0 +1,317 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// epoch of KDBX 4 times (seconds since 0001-01-01 UTC)
+var kdbxEpoch = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// xmlParser builds a database from xml document of a KDBX file
+type xmlParser struct {
+	decoder *xml.Decoder
+	// key stream of protected values, consumed in document order
+	stream *protectedStream
+	// uuid of recycle bin group
+	recycleBin []byte
+}
+
+// parseXML parses xml document of a KDBX file
+func parseXML(data []byte, stream *protectedStream) (*Database, error) {
+
+	parser := &xmlParser{decoder: xml.NewDecoder(bytes.NewReader(data)), stream: stream}
+	db := &Database{}
+	recycleBinEnabled := true
+
+	err := parser.children(func(start xml.StartElement) error {
+
+		if start.Name.Local != "KeePassFile" {
+			return parser.skip()
+		}
+
+		return parser.children(func(start xml.StartElement) error {
+
+			switch start.Name.Local {
+
+			case "Meta":
+				return parser.children(func(start xml.StartElement) error {
+					switch start.Name.Local {
+					case "DatabaseName":
+						return parser.text(start, &db.Name)
+					case "RecycleBinEnabled":
+						var enabled string
+						err := parser.text(start, &enabled)
+						recycleBinEnabled = strings.EqualFold(enabled, "true")
+						return err
+					case "RecycleBinUUID":
+						var uuid string
+						err := parser.text(start, &uuid)
+						parser.recycleBin, _ = decodeBase64(uuid)
+						return err
+					default:
+						return parser.skip()
+					}
+				})
+
+			case "Root":
+				return parser.children(func(start xml.StartElement) error {
+					if start.Name.Local != "Group" || db.Root != nil {
+						return parser.skip()
+					}
+					group, err := parser.group()
+					db.Root = group
+					return err
+				})
+
+			default:
+				return parser.skip()
+			}
+		})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("corrupted database: %v", err)
+	}
+
+	if db.Root == nil {
+		return nil, errors.New("corrupted database: missing root group")
+	}
+
+	if recycleBinEnabled && len(parser.recycleBin) == 16 {
+		markRecycleBin(db.Root, parser.recycleBin)
+	}
+
+	return db, nil
+}
+
+// markRecycleBin flags the group with given uuid as recycle bin
+func markRecycleBin(group *Group, uuid []byte) {
+	for _, child := range group.Groups {
+		if bytes.Equal(child.UUID[:], uuid) {
+			child.RecycleBin = true
+		}
+		markRecycleBin(child, uuid)
+	}
+}
+
+// group parses a Group element
+func (parser *xmlParser) group() (*Group, error) {
+
+	group := &Group{}
+
+	err := parser.children(func(start xml.StartElement) error {
+		switch start.Name.Local {
+		case "UUID":
+			return parser.uuid(start, &group.UUID)
+		case "Name":
+			return parser.text(start, &group.Name)
+		case "Notes":
+			return parser.text(start, &group.Notes)
+		case "Times":
+			return parser.times(&group.Created, &group.Modified)
+		case "Entry":
+			entry, err := parser.entry()
+			if err == nil {
+				group.Entries = append(group.Entries, entry)
+			}
+			return err
+		case "Group":
+			child, err := parser.group()
+			if err == nil {
+				group.Groups = append(group.Groups, child)
+			}
+			return err
+		default:
+			return parser.skip()
+		}
+	})
+
+	return group, err
+}
+
+// entry parses an Entry element (history entries are dropped)
+func (parser *xmlParser) entry() (*Entry, error) {
+
+	entry := &Entry{}
+
+	err := parser.children(func(start xml.StartElement) error {
+		switch start.Name.Local {
+		case "UUID":
+			return parser.uuid(start, &entry.UUID)
+		case "Tags":
+			return parser.text(start, &entry.Tags)
+		case "Times":
+			return parser.times(&entry.Created, &entry.Modified)
+		case "String":
+			field := Field{}
+			err := parser.children(func(start xml.StartElement) error {
+				switch start.Name.Local {
+				case "Key":
+					return parser.text(start, &field.Key)
+				case "Value":
+					field.Protected = isProtected(start)
+					return parser.text(start, &field.Value)
+				default:
+					return parser.skip()
+				}
+			})
+			entry.Fields = append(entry.Fields, field)
+			return err
+		default:
+			// History (and any other element) may hold protected values
+			return parser.skip()
+		}
+	})
+
+	return entry, err
+}
+
+// times parses creation and modification time of a Times element
+func (parser *xmlParser) times(created *time.Time, modified *time.Time) error {
+	return parser.children(func(start xml.StartElement) error {
+		var target *time.Time
+		switch start.Name.Local {
+		case "CreationTime":
+			target = created
+		case "LastModificationTime":
+			target = modified
+		default:
+			return parser.skip()
+		}
+		var text string
+		if err := parser.text(start, &text); err != nil {
+			return err
+		}
+		*target = parseTime(text)
+		return nil
+	})
+}
+
+// uuid parses a base64 encoded uuid element
+func (parser *xmlParser) uuid(start xml.StartElement, uuid *[16]byte) error {
+	var text string
+	if err := parser.text(start, &text); err != nil {
+		return err
+	}
+	data, err := decodeBase64(text)
+	if err != nil || len(data) != 16 {
+		return fmt.Errorf("invalid uuid '%s'", text)
+	}
+	copy(uuid[:], data)
+	return nil
+}
+
+// text reads character data of current element, protected values are
+// decrypted by key stream
+func (parser *xmlParser) text(start xml.StartElement, text *string) error {
+
+	var builder strings.Builder
+
+	for {
+		token, err := parser.decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.CharData:
+			builder.Write(token)
+		case xml.StartElement:
+			if err := parser.skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			*text = builder.String()
+			if !isProtected(start) {
+				return nil
+			}
+			data, err := decodeBase64(*text)
+			if err != nil {
+				return fmt.Errorf("invalid protected value: %v", err)
+			}
+			parser.stream.xor(data)
+			*text = string(data)
+			return nil
+		}
+	}
+}
+
+// children calls handle for each child element of current element, handle
+// must consume the whole child element
+func (parser *xmlParser) children(handle func(start xml.StartElement) error) error {
+
+	for {
+		token, err := parser.decoder.Token()
+
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if err := handle(token); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// skip consumes current element, its protected values still consume key
+// stream so later values are decrypted correctly
+func (parser *xmlParser) skip() error {
+	return parser.children(func(start xml.StartElement) error {
+		if isProtected(start) {
+			var ignored string
+			return parser.text(start, &ignored)
+		}
+		return parser.skip()
+	})
+}
+
+// isProtected returns true if element has Protected="True" attribute
+func isProtected(start xml.StartElement) bool {
+	for _, attribute := range start.Attr {
+		if attribute.Name.Local == "Protected" && strings.EqualFold(attribute.Value, "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime parses a KDBX 4 (base64 seconds) or KDBX 3 (ISO 8601) time,
+// zero time if it is malformed
+func parseTime(text string) time.Time {
+
+	if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(text)); err == nil {
+		return parsed
+	}
+
+	data, err := decodeBase64(text)
+
+	if err != nil || len(data) != 8 {
+		return time.Time{}
+	}
+
+	seconds := int64(binary.LittleEndian.Uint64(data))
+
+	return time.Unix(kdbxEpoch.Unix()+seconds, 0).UTC()
+}
+
+// decodeBase64 decodes standard base64 ignoring surrounding spaces
+func decodeBase64(text string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+}
@@ -0,0 +1,217 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// attribute types of binary keyrings
+const (
+	attributeString = 0
+	attributeUint32 = 1
+)
+
+// errTruncated is returned if a binary keyring ends unexpectedly
+var errTruncated = errors.New("corrupted keyring: truncated data")
+
+// buffer reads big endian values of a binary keyring
+type buffer struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+// bytes reads n bytes
+func (b *buffer) bytes(n int) []byte {
+	if b.err != nil {
+		return nil
+	}
+	if n < 0 || b.offset+n > len(b.data) {
+		b.err = errTruncated
+		return nil
+	}
+	value := b.data[b.offset : b.offset+n]
+	b.offset += n
+	return value
+}
+
+// byte reads a byte
+func (b *buffer) byte() byte {
+	if value := b.bytes(1); value != nil {
+		return value[0]
+	}
+	return 0
+}
+
+// uint32 reads a big endian uint32
+func (b *buffer) uint32() uint32 {
+	if value := b.bytes(4); value != nil {
+		return binary.BigEndian.Uint32(value)
+	}
+	return 0
+}
+
+// time reads a time (high and low uint32 of unix seconds)
+func (b *buffer) time() uint64 {
+	high := uint64(b.uint32())
+	return high<<32 | uint64(b.uint32())
+}
+
+// string reads a length prefixed string (0xFFFFFFFF length is null)
+func (b *buffer) string() string {
+	length := b.uint32()
+	if length == 0xFFFFFFFF {
+		return ""
+	}
+	value := b.bytes(int(length))
+	if b.err == nil && !utf8.Valid(value) {
+		b.err = errors.New("corrupted keyring: invalid string")
+	}
+	return string(value)
+}
+
+// attributes reads lookup attributes with their values, hashed attributes
+// have hashed values which are dropped
+func (b *buffer) attributes(hashed bool) map[string]string {
+	attributes := make(map[string]string)
+	count := b.uint32()
+	for i := uint32(0); i < count && b.err == nil; i++ {
+		name := b.string()
+		switch b.uint32() {
+		case attributeString:
+			attributes[name] = b.string()
+		case attributeUint32:
+			attributes[name] = strconv.FormatUint(uint64(b.uint32()), 10)
+		default:
+			b.err = fmt.Errorf("corrupted keyring: invalid type of attribute '%s'", name)
+		}
+	}
+	if hashed {
+		return nil
+	}
+	return attributes
+}
+
+// readBinary decrypts and parses a binary keyring (after magic)
+func readBinary(data []byte, password string) (*Keyring, error) {
+
+	b := &buffer{data: data}
+
+	major, minor, crypto, hash := b.byte(), b.byte(), b.byte(), b.byte()
+
+	if b.err == nil && (major != 0 || minor != 0 || crypto != 0 || hash != 0) {
+		return nil, fmt.Errorf("unsupported keyring version %d.%d (crypto %d, hash %d)",
+			major, minor, crypto, hash)
+	}
+
+	keyring := &Keyring{Encrypted: true}
+	keyring.Name = b.string()
+	keyring.Created = epoch(b.time())
+	keyring.Modified = epoch(b.time())
+	b.uint32() // flags
+	b.uint32() // lock timeout
+	iterations := b.uint32()
+	salt := b.bytes(8)
+	b.bytes(16) // reserved
+
+	count := b.uint32()
+
+	if b.err == nil && uint64(count)*8 > uint64(len(data)) {
+		return nil, errTruncated
+	}
+
+	// hashed part: item id, type and hashed attributes
+	for i := uint32(0); i < count && b.err == nil; i++ {
+		item := &Item{ID: b.uint32(), Type: itemType(b.uint32())}
+		b.attributes(true)
+		keyring.Items = append(keyring.Items, item)
+	}
+
+	encrypted := b.bytes(int(b.uint32()))
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+		return nil, errors.New("corrupted keyring: invalid encrypted data")
+	}
+
+	decrypted, err := decrypt(encrypted, password, salt, iterations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// encrypted part: md5 of the rest, then item details in hashed part order
+	b = &buffer{data: decrypted, offset: md5.Size}
+
+	for _, item := range keyring.Items {
+		item.Label = b.string()
+		item.Secret = []byte(b.string())
+		item.Created = epoch(b.time())
+		item.Modified = epoch(b.time())
+		b.string()  // reserved
+		b.bytes(16) // reserved
+		item.Attributes = b.attributes(false)
+		// access control list
+		acl := b.uint32()
+		for i := uint32(0); i < acl && b.err == nil; i++ {
+			b.uint32() // allowed types
+			b.string() // display name
+			b.string() // path
+			b.string() // reserved
+			b.uint32() // reserved
+		}
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return keyring, nil
+}
+
+// decrypt decrypts encrypted part of a binary keyring (AES-128-CBC with key
+// and iv of iterated SHA-256 of password and salt) and checks its md5
+func decrypt(encrypted []byte, password string, salt []byte, iterations uint32) ([]byte, error) {
+
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	digest := sha256.Sum256(append([]byte(password), salt...))
+
+	for i := uint32(1); i < iterations; i++ {
+		digest = sha256.Sum256(digest[:])
+	}
+
+	block, err := aes.NewCipher(digest[:16])
+
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, digest[16:]).CryptBlocks(decrypted, encrypted)
+
+	if len(decrypted) < md5.Size {
+		return nil, errTruncated
+	}
+
+	hash := md5.Sum(decrypted[md5.Size:])
+
+	if !bytes.Equal(hash[:], decrypted[:md5.Size]) {
+		return nil, ErrInvalidPassword
+	}
+
+	return decrypted, nil
+}
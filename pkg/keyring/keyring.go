@@ -0,0 +1,111 @@
+// Package keyring reads gnome-keyring keyring files
+// (~/.local/share/keyrings/*.keyring): binary keyrings encrypted with the
+// keyring password and textual (ini style) keyrings of keyrings without a
+// password.
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// magic of binary (encrypted) keyring files
+var binaryMagic = []byte("GnomeKeyring\n\r\x00\n")
+
+// ErrNotKeyring is returned if a file is not a gnome-keyring keyring
+var ErrNotKeyring = errors.New("not a gnome-keyring keyring")
+
+// ErrInvalidPassword is returned if keyring password is wrong
+var ErrInvalidPassword = errors.New("wrong keyring password")
+
+// Keyring is the content of a keyring file
+type Keyring struct {
+	// keyring display name
+	Name string
+	// keyring creation time
+	Created time.Time
+	// keyring modification time
+	Modified time.Time
+	// TRUE if keyring file is encrypted with a password
+	Encrypted bool
+	// items of keyring in file order
+	Items []*Item
+}
+
+// Item is a secret with its label and lookup attributes
+type Item struct {
+	// item identifier in keyring
+	ID uint32
+	// schema name of item type (i.e. 'org.gnome.keyring.NetworkPassword')
+	Type string
+	// item display name
+	Label string
+	// secret value
+	Secret []byte
+	// lookup attributes, uint32 attributes are decimal strings
+	Attributes map[string]string
+	// item creation time
+	Created time.Time
+	// item modification time
+	Modified time.Time
+}
+
+// item types of gnome-keyring and their schema names
+var itemTypes = map[uint32]string{
+	0:     "org.freedesktop.Secret.Generic",
+	1:     "org.gnome.keyring.NetworkPassword",
+	2:     "org.gnome.keyring.Note",
+	3:     "org.gnome.keyring.ChainedKeyring",
+	4:     "org.gnome.keyring.EncryptionKey",
+	0x100: "org.gnome.keyring.PkStorage",
+}
+
+// itemType returns schema name of an item type, flags (high bits) are ignored
+func itemType(value uint32) string {
+	if name, ok := itemTypes[value&0xFFFF]; ok {
+		return name
+	}
+	return itemTypes[0]
+}
+
+// IsEncrypted returns true if data is a binary (password protected) keyring
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, binaryMagic)
+}
+
+// Read reads a keyring file. Password is only used by encrypted keyrings.
+func Read(reader io.Reader, password string) (*Keyring, error) {
+
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if IsEncrypted(data) {
+		return readBinary(data[len(binaryMagic):], password)
+	}
+
+	return readTextual(data)
+}
+
+// epoch converts unix seconds to time, zero is zero time
+func epoch(seconds uint64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0).UTC()
+}
+
+// parseEpoch converts a decimal unix time to time, zero time if malformed
+func parseEpoch(text string) time.Time {
+	seconds, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return epoch(seconds)
+}
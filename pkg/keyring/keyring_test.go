@@ -0,0 +1,121 @@
+package keyring_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yousefvand/secret-service/pkg/keyring"
+)
+
+func readFixture(t *testing.T, name string, password string) (*keyring.Keyring, error) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Cannot read fixture. Error: %v", err)
+	}
+	return keyring.Read(bytes.NewReader(data), password)
+}
+
+func TestRead_encrypted(t *testing.T) {
+
+	login, err := readFixture(t, "login.keyring", "secretservice")
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	if login.Name != "Login" || !login.Encrypted || len(login.Items) != 3 ||
+		!login.Created.Equal(time.Unix(1400000000, 0)) {
+		t.Fatalf("Unexpected keyring: %+v", login)
+	}
+
+	github, imap, app := login.Items[0], login.Items[1], login.Items[2]
+
+	if github.Label != "GitHub token" || string(github.Secret) != "ghp_s3cr3t" ||
+		github.Type != "org.freedesktop.Secret.Generic" {
+		t.Errorf("Unexpected item: %+v", github)
+	}
+
+	if !github.Created.Equal(time.Unix(1600000000, 0)) || !github.Modified.Equal(time.Unix(1650000000, 0)) {
+		t.Errorf("Unexpected times: %v %v", github.Created, github.Modified)
+	}
+
+	expected := map[string]string{"xdg:schema": "org.gnome.Example", "service": "github", "user": "joe"}
+
+	if !reflect.DeepEqual(github.Attributes, expected) {
+		t.Errorf("Expected attributes %v, got: %v", expected, github.Attributes)
+	}
+
+	// uint32 attributes are decimal strings
+	if imap.Type != "org.gnome.keyring.NetworkPassword" || imap.Attributes["port"] != "993" ||
+		string(imap.Secret) != "imap pass" {
+		t.Errorf("Unexpected item: %+v", imap)
+	}
+
+	// item type flags are ignored, null secret is empty
+	if app.Type != "org.freedesktop.Secret.Generic" || len(app.Secret) != 0 || app.ID != 3 {
+		t.Errorf("Unexpected item: %+v", app)
+	}
+}
+
+func TestRead_textual(t *testing.T) {
+
+	plain, err := readFixture(t, "plain.keyring", "")
+
+	if err != nil {
+		t.Fatalf("Read failed. Error: %v", err)
+	}
+
+	if plain.Name != "Plain Keyring" || plain.Encrypted || len(plain.Items) != 3 ||
+		!plain.Modified.Equal(time.Unix(1310000000, 0)) {
+		t.Fatalf("Unexpected keyring: %+v", plain)
+	}
+
+	// items are sorted by id
+	smtp, generic, escaped := plain.Items[0], plain.Items[1], plain.Items[2]
+
+	if smtp.ID != 2 || !bytes.Equal(smtp.Secret, []byte{0x00, 0xff, 's', 'e', 'c', 'r', 'e'}) ||
+		smtp.Type != "org.gnome.keyring.NetworkPassword" || smtp.Attributes["port"] != "587" {
+		t.Errorf("Unexpected item: %+v", smtp)
+	}
+
+	if generic.Label != " spaced\tlabel" || string(generic.Secret) != "plain secret=with=equals" ||
+		!generic.Created.Equal(time.Unix(1300000000, 0)) {
+		t.Errorf("Unexpected item: %+v", generic)
+	}
+
+	expected := map[string]string{"xdg:schema": "org.freedesktop.Secret.Generic", "service": "my service"}
+
+	if !reflect.DeepEqual(generic.Attributes, expected) {
+		t.Errorf("Expected attributes %v, got: %v", expected, generic.Attributes)
+	}
+
+	// secrets are escaped like other strings
+	if secret := " leading space\\back\nslash\ttab\r"; string(escaped.Secret) != secret {
+		t.Errorf("Expected secret %q, got: %q", secret, escaped.Secret)
+	}
+}
+
+func TestRead_errors(t *testing.T) {
+
+	if _, err := readFixture(t, "login.keyring", "wrong"); err != keyring.ErrInvalidPassword {
+		t.Errorf("Expected '%v', got: %v", keyring.ErrInvalidPassword, err)
+	}
+
+	if _, err := keyring.Read(bytes.NewReader([]byte("[other]\nkey=value\n")), ""); err != keyring.ErrNotKeyring {
+		t.Errorf("Expected '%v', got: %v", keyring.ErrNotKeyring, err)
+	}
+
+	data, _ := ioutil.ReadFile(filepath.Join("testdata", "login.keyring"))
+
+	if _, err := keyring.Read(bytes.NewReader(data[:100]), "secretservice"); err == nil {
+		t.Error("Expected truncated keyring to fail")
+	}
+
+	if !keyring.IsEncrypted(data) {
+		t.Error("Expected login.keyring to be encrypted")
+	}
+}
@@ -0,0 +1,160 @@
+package keyring
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyFile is a parsed ini style (GKeyFile) document: group to key to value
+type keyFile map[string]map[string]string
+
+// parseKeyFile parses a GKeyFile document, values are not unescaped
+func parseKeyFile(data []byte) (keyFile, []string, error) {
+
+	file := keyFile{}
+	var groups []string
+	group := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+		text := strings.TrimLeft(scanner.Text(), " \t")
+
+		switch {
+
+		case text == "" || text[0] == '#':
+
+		case text[0] == '[':
+			end := strings.IndexByte(text, ']')
+			if end < 0 {
+				return nil, nil, fmt.Errorf("corrupted keyring: malformed group at line %d", line)
+			}
+			group = text[1:end]
+			if _, ok := file[group]; !ok {
+				file[group] = map[string]string{}
+				groups = append(groups, group)
+			}
+
+		default:
+			separator := strings.IndexByte(text, '=')
+			if separator < 0 || group == "" {
+				return nil, nil, fmt.Errorf("corrupted keyring: malformed line %d", line)
+			}
+			key := strings.TrimRight(text[:separator], " \t")
+			file[group][key] = strings.TrimLeft(text[separator+1:], " \t")
+		}
+	}
+
+	return file, groups, scanner.Err()
+}
+
+// unescape decodes GKeyFile string escapes (\s, \n, \t, \r and \\)
+func unescape(value string) string {
+
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			builder.WriteByte(' ')
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// readTextual parses a textual (unencrypted) keyring
+func readTextual(data []byte) (*Keyring, error) {
+
+	file, groups, err := parseKeyFile(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header, ok := file["keyring"]
+
+	if !ok {
+		return nil, ErrNotKeyring
+	}
+
+	keyring := &Keyring{
+		Name:     unescape(header["display-name"]),
+		Created:  parseEpoch(header["ctime"]),
+		Modified: parseEpoch(header["mtime"]),
+	}
+
+	for _, group := range groups {
+
+		// item groups are identifiers, attributes and acl are 'id:...'
+		id, err := strconv.ParseUint(group, 10, 32)
+
+		if err != nil {
+			continue
+		}
+
+		values := file[group]
+		item := &Item{
+			ID:         uint32(id),
+			Label:      unescape(values["display-name"]),
+			Secret:     []byte(unescape(values["secret"])),
+			Attributes: textualAttributes(file, group),
+			Created:    parseEpoch(values["ctime"]),
+			Modified:   parseEpoch(values["mtime"]),
+		}
+
+		itemTypeValue, _ := strconv.ParseUint(values["item-type"], 10, 32)
+		item.Type = itemType(uint32(itemTypeValue))
+
+		if binarySecret, ok := values["binary-secret"]; ok {
+			if item.Secret, err = hex.DecodeString(binarySecret); err != nil {
+				return nil, fmt.Errorf("corrupted keyring: invalid secret of item %d", id)
+			}
+		}
+
+		keyring.Items = append(keyring.Items, item)
+	}
+
+	sort.SliceStable(keyring.Items, func(i, j int) bool { return keyring.Items[i].ID < keyring.Items[j].ID })
+
+	return keyring, nil
+}
+
+// textualAttributes returns attributes of an item ('id:attributeN' groups)
+func textualAttributes(file keyFile, id string) map[string]string {
+
+	attributes := make(map[string]string)
+
+	for index := 0; ; index++ {
+		values, ok := file[fmt.Sprintf("%s:attribute%d", id, index)]
+		if !ok {
+			return attributes
+		}
+		attributes[unescape(values["name"])] = unescape(values["value"])
+	}
+}
@@ -0,0 +1,157 @@
+// Package otp generates time-based one-time passwords (RFC 6238) from
+// 'otpauth://totp/...' key URIs (Google Authenticator key uri format).
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType marks secrets which are otpauth key URIs
+const ContentType = "application/x-otpauth"
+
+// Scheme of otpauth key URIs
+const Scheme = "otpauth://"
+
+// ErrNotOtp is returned if a secret is not an otpauth key URI
+var ErrNotOtp = errors.New("secret is not an otpauth:// URI")
+
+// Key is a TOTP key parsed from an otpauth key URI
+type Key struct {
+	// issuer of the key (provider or service)
+	Issuer string
+	// account name of the key
+	Account string
+	// shared secret (seed)
+	Secret []byte
+	// hash algorithm: SHA1, SHA256 or SHA512
+	Algorithm string
+	// number of digits of codes: 6 to 10
+	Digits int
+	// validity of each code
+	Period time.Duration
+}
+
+// IsURI reports if secret looks like an otpauth key URI
+func IsURI(secret string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(secret)), Scheme)
+}
+
+// Parse parses an 'otpauth://totp/label?secret=...' key URI. Missing
+// parameters default to SHA1, 6 digits and 30 seconds.
+func Parse(uri string) (*Key, error) {
+
+	if !IsURI(uri) {
+		return nil, ErrNotOtp
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URI: %v", err)
+	}
+
+	if !strings.EqualFold(parsed.Host, "totp") {
+		return nil, fmt.Errorf("unsupported otp type '%s', only 'totp' is supported", parsed.Host)
+	}
+
+	query := parsed.Query()
+	key := &Key{
+		Issuer:    query.Get("issuer"),
+		Account:   strings.TrimPrefix(parsed.Path, "/"),
+		Algorithm: "SHA1",
+		Digits:    6,
+		Period:    30 * time.Second,
+	}
+
+	// label is 'issuer:account' or 'account'
+	if index := strings.Index(key.Account, ":"); index >= 0 {
+		if key.Issuer == "" {
+			key.Issuer = key.Account[:index]
+		}
+		key.Account = strings.TrimSpace(key.Account[index+1:])
+	}
+
+	secret := strings.ToUpper(strings.ReplaceAll(query.Get("secret"), " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	key.Secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+
+	if err != nil || len(key.Secret) == 0 {
+		return nil, errors.New("invalid otpauth URI: missing or malformed base32 'secret'")
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(algorithm)
+		if key.hash() == nil {
+			return nil, fmt.Errorf("invalid otpauth URI: unsupported algorithm '%s'", algorithm)
+		}
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		key.Digits, err = strconv.Atoi(digits)
+		if err != nil || key.Digits < 6 || key.Digits > 10 {
+			return nil, fmt.Errorf("invalid otpauth URI: digits must be 6 to 10, got '%s'", digits)
+		}
+	}
+
+	if period := query.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid otpauth URI: invalid period '%s'", period)
+		}
+		key.Period = time.Duration(seconds) * time.Second
+	}
+
+	return key, nil
+}
+
+// Code returns the code of key at given time and its remaining validity
+func (key *Key) Code(at time.Time) (string, time.Duration) {
+
+	period := int64(key.Period / time.Second)
+	counter := at.Unix() / period
+	remaining := time.Duration(period-at.Unix()%period) * time.Second
+
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(key.hash(), key.Secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation (RFC 4226)
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	modulo := uint64(1)
+	for i := 0; i < key.Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", key.Digits, value%modulo), remaining
+}
+
+// hash returns hash function of key algorithm, nil if it is unknown
+func (key *Key) hash() func() hash.Hash {
+	switch key.Algorithm {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package otp_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/yousefvand/secret-service/pkg/otp"
+)
+
+// RFC 6238 Appendix B test vectors
+func TestKey_Code(t *testing.T) {
+
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	for _, tc := range []struct {
+		time      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	} {
+		secret := base32.StdEncoding.EncodeToString([]byte(seeds[tc.algorithm]))
+		key, err := otp.Parse("otpauth://totp/Test:joe?secret=" + secret +
+			"&algorithm=" + tc.algorithm + "&digits=8")
+
+		if err != nil {
+			t.Fatalf("Parse failed. Error: %v", err)
+		}
+
+		code, remaining := key.Code(time.Unix(tc.time, 0))
+
+		if code != tc.code {
+			t.Errorf("%s at %d: expected '%s', got: '%s'", tc.algorithm, tc.time, tc.code, code)
+		}
+
+		if expected := time.Duration(30-tc.time%30) * time.Second; remaining != expected {
+			t.Errorf("%s at %d: expected remaining %v, got: %v", tc.algorithm, tc.time, expected, remaining)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	key, err := otp.Parse("otpauth://totp/ACME%20Co:john.doe@email.com?" +
+		"secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&period=60")
+
+	if err != nil {
+		t.Fatalf("Parse failed. Error: %v", err)
+	}
+
+	if key.Issuer != "ACME Co" || key.Account != "john.doe@email.com" {
+		t.Errorf("Unexpected issuer/account: '%s' '%s'", key.Issuer, key.Account)
+	}
+
+	if key.Algorithm != "SHA1" || key.Digits != 6 || key.Period != time.Minute {
+		t.Errorf("Unexpected defaults: %s %d %v", key.Algorithm, key.Digits, key.Period)
+	}
+
+	// lowercase and padded secrets are accepted
+	if _, err := otp.Parse("otpauth://totp/joe?secret=gezdgnbvgy3tqojq"); err != nil {
+		t.Errorf("Expected lowercase secret to be accepted. Error: %v", err)
+	}
+
+	for _, uri := range []string{
+		"P@ssw0rd",
+		"otpauth://hotp/joe?secret=GEZDGNBV&counter=1",
+		"otpauth://totp/joe",
+		"otpauth://totp/joe?secret=1!",
+		"otpauth://totp/joe?secret=GEZDGNBV&algorithm=MD5",
+		"otpauth://totp/joe?secret=GEZDGNBV&digits=4",
+		"otpauth://totp/joe?secret=GEZDGNBV&period=0",
+	} {
+		if _, err := otp.Parse(uri); err == nil {
+			t.Errorf("Expected '%s' to fail", uri)
+		}
+	}
+
+	if _, err := otp.Parse("secret"); err != otp.ErrNotOtp {
+		t.Errorf("Expected '%v', got: %v", otp.ErrNotOtp, err)
+	}
+}
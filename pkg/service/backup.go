@@ -0,0 +1,637 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/yousefvand/secret-service/pkg/crypto"
+)
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Entities >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// BackupVersion is the version of backup file format
+const BackupVersion string = "1"
+
+// Backup file: a manifest and the database (json) with plain secrets,
+// database is encrypted as a whole by master password if manifest says so
+type Backup struct {
+	// Backup manifest
+	Manifest BackupManifest `json:"manifest"`
+	// Database json (base64 cipher text if encrypted)
+	Database string `json:"database"`
+}
+
+// BackupManifest describes a backup
+type BackupManifest struct {
+	// Backup file format version
+	Version string `json:"version"`
+	// secretserviced version which created the backup
+	ServiceVersion string `json:"serviceVersion"`
+	// Backup creation time (epoch)
+	Created uint64 `json:"created"`
+	// TRUE if database is encrypted by master password
+	Encrypted bool `json:"encrypted"`
+	// Number of collections
+	Collections int `json:"collections"`
+	// Number of items
+	Items int `json:"items"`
+	// sha256 checksum of database json (before encryption)
+	Checksum string `json:"checksum"`
+}
+
+// BackupFile is a backup found in backup directory
+type BackupFile struct {
+	// Backup file path
+	Path string
+	// Backup file size
+	Size int64
+	// Backup manifest
+	Manifest BackupManifest
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Entities <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+// errRestoring is returned by Marshal while a backup is being restored
+var errRestoring = errors.New("restoring a backup")
+
+// dbusErrorRestoring is returned by methods creating or deleting
+// collections and items while a backup is being restored
+func dbusErrorRestoring() *dbus.Error {
+	return DbusErrorCallFailed("A backup is being restored")
+}
+
+// isRestoring returns true while a backup is being restored
+func (service *Service) isRestoring() bool {
+	return atomic.LoadInt32(&service.restoring) == 1
+}
+
+// BackupDirectory returns backup directory of service
+func (service *Service) BackupDirectory() string {
+
+	if directory := service.Settings().BackupDirectory; directory != "" {
+		return directory
+	}
+
+	return filepath.Join(service.Config.Home, "backups")
+}
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> CreateBackup >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// createBackup writes a backup of current collections and items to backup
+// directory and returns its path. Backup is encrypted by master password,
+// plain backups need database export to be allowed.
+func (service *Service) createBackup(plain bool) (string, error) {
+
+	masterPassword := ""
+
+	if plain {
+		if !service.Settings().AllowDbExport {
+			return "", errors.New("plain backups need 'allowDbExport' to be enabled")
+		}
+	} else {
+		masterPassword = os.Getenv("MASTERPASSWORD")
+		if len(masterPassword) != 32 {
+			return "", errors.New("cannot encrypt backup without a 32 character MASTERPASSWORD")
+		}
+	}
+
+	db, err := service.snapshot(false, "")
+
+	if err != nil {
+		return "", err
+	}
+
+	content, err := NewBackup(db, masterPassword, time.Now())
+
+	if err != nil {
+		return "", err
+	}
+
+	directory := service.BackupDirectory()
+
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return "", fmt.Errorf("cannot create backup directory. Error: %v", err)
+	}
+
+	// backups of the same second get a counter
+	name := "backup-" + time.Now().Format("20060102-150405")
+	backupFile := filepath.Join(directory, name+".json")
+
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backupFile); os.IsNotExist(err) {
+			break
+		}
+		backupFile = filepath.Join(directory, fmt.Sprintf("%s-%d.json", name, i))
+	}
+
+	if err := writeFileAtomic(backupFile, content); err != nil {
+		return "", fmt.Errorf("cannot write backup. Error: %v", err)
+	}
+
+	log.Infof("Backup created: %s", backupFile)
+
+	return backupFile, nil
+}
+
+// NewBackup returns content of a backup file of given database (with plain
+// secrets), database is encrypted if a master password is given
+func NewBackup(db *Database, masterPassword string, created time.Time) ([]byte, error) {
+
+	content, err := json.Marshal(db)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal database. Error: %v", err)
+	}
+
+	checksum := sha256.Sum256(content)
+
+	backup := Backup{
+		Manifest: BackupManifest{
+			Version:        BackupVersion,
+			ServiceVersion: Version,
+			Created:        uint64(created.Unix()),
+			Encrypted:      masterPassword != "",
+			Collections:    len(db.Collections),
+			Checksum:       "sha256:" + hex.EncodeToString(checksum[:]),
+		},
+		Database: string(content),
+	}
+
+	for _, collection := range db.Collections {
+		backup.Manifest.Items += len(collection.Items)
+	}
+
+	if backup.Manifest.Encrypted {
+		backup.Database, err = crypto.EncryptAESCBC256(masterPassword, string(content))
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt backup. Error: %v", err)
+		}
+	}
+
+	return json.MarshalIndent(backup, "", " ")
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< CreateBackup <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ReadBackup >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// ReadBackupManifest reads manifest of a backup file
+func ReadBackupManifest(backupFile string) (*BackupManifest, error) {
+
+	backup, err := readBackupFile(backupFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup.Manifest, nil
+}
+
+// ReadBackup reads and validates a backup file (checksum and counts of
+// manifest) and returns its database with plain secrets
+func ReadBackup(backupFile string, masterPassword string) (*Database, error) {
+
+	backup, err := readBackupFile(backupFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content := backup.Database
+
+	if backup.Manifest.Encrypted {
+		if len(masterPassword) != 32 {
+			return nil, errors.New("backup is encrypted but there is no 32 character master password")
+		}
+		content, err = crypto.DecryptAESCBC256(masterPassword, content)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt backup (wrong master password?). Error: %v", err)
+		}
+	}
+
+	checksum := sha256.Sum256([]byte(content))
+
+	if "sha256:"+hex.EncodeToString(checksum[:]) != backup.Manifest.Checksum {
+		return nil, errors.New("backup is corrupted: checksum mismatch")
+	}
+
+	var db Database
+
+	if err := json.Unmarshal([]byte(content), &db); err != nil {
+		return nil, fmt.Errorf("backup is corrupted: malformed database. Error: %v", err)
+	}
+
+	items := 0
+
+	for _, collection := range db.Collections {
+		items += len(collection.Items)
+	}
+
+	if len(db.Collections) != backup.Manifest.Collections || items != backup.Manifest.Items {
+		return nil, errors.New("backup is corrupted: collection or item count mismatch")
+	}
+
+	if db.Encrypted {
+		return nil, errors.New("backup is corrupted: database secrets are encrypted")
+	}
+
+	return &db, nil
+}
+
+// readBackupFile reads a backup file and checks its format version
+func readBackupFile(backupFile string) (*Backup, error) {
+
+	content, err := ioutil.ReadFile(backupFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backup. Error: %v", err)
+	}
+
+	var backup Backup
+
+	if err := json.Unmarshal(content, &backup); err != nil || backup.Manifest.Version == "" {
+		return nil, fmt.Errorf("'%s' is not a backup file", backupFile)
+	}
+
+	if backup.Manifest.Version != BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version '%s'", backup.Manifest.Version)
+	}
+
+	return &backup, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ReadBackup <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> RestoreBackup >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// restoreBackup validates a backup and replaces all collections and items
+// with the ones in backup. A backup of current data is created first.
+// Collections of backup are built aside and swapped in at once, creating and
+// deleting collections and items is refused meanwhile. Database is not saved
+// until restore is finished and then it is written atomically.
+func (service *Service) restoreBackup(backupFile string) error {
+
+	if service.IsLocked() {
+		return errServiceLocked
+	}
+
+	db, err := ReadBackup(backupFile, os.Getenv("MASTERPASSWORD"))
+
+	if err != nil {
+		return err
+	}
+
+	collections, err := backupCollections(service, db)
+
+	if err != nil {
+		return err
+	}
+
+	// creating and deleting collections and items is refused from now on so
+	// safety backup has everything which is swapped out
+	if !atomic.CompareAndSwapInt32(&service.restoring, 0, 1) {
+		return errors.New("another backup is being restored")
+	}
+
+	// current data is backed up encrypted, or plain if there is no master
+	// password but database export is allowed
+	plain := len(os.Getenv("MASTERPASSWORD")) != 32
+
+	if plain && !service.Settings().AllowDbExport {
+		atomic.StoreInt32(&service.restoring, 0)
+		return errors.New("current data is backed up before restore which needs a 32 character MASTERPASSWORD or 'allowDbExport' to be enabled")
+	}
+
+	safetyBackup, err := service.createBackup(plain)
+
+	if err != nil {
+		atomic.StoreInt32(&service.restoring, 0)
+		return fmt.Errorf("cannot backup current data before restore. Error: %v", err)
+	}
+
+	log.Infof("Restoring backup '%s', current data is kept in '%s'", backupFile, safetyBackup)
+
+	// secrets of backup are plain so service must not be locked meanwhile
+	service.LockMutex.RLock()
+
+	if service.Locked {
+		service.LockMutex.RUnlock()
+		atomic.StoreInt32(&service.restoring, 0)
+		return errServiceLocked
+	}
+
+	service.CollectionsMutex.Lock()
+	previous := service.Collections
+	service.Collections = collections
+	service.CollectionsMutex.Unlock()
+
+	service.LockMutex.RUnlock()
+
+	dbusSwapCollections(service, previous, collections)
+
+	atomic.StoreInt32(&service.restoring, 0)
+	service.SaveData()
+
+	log.Infof("Backup restored: %s", backupFile)
+
+	return nil
+}
+
+// backupCollections returns collections and items of a backup database,
+// they are neither in service nor on dbus
+func backupCollections(service *Service, db *Database) (map[string]*Collection, error) {
+
+	collections := make(map[string]*Collection)
+	items := make(map[dbus.ObjectPath]bool)
+	defaultPath := dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+
+	for _, collectionValue := range db.Collections {
+
+		if collectionValue.Alias == "default" {
+			collectionValue.ObjectPath = defaultPath
+		}
+
+		if _, ok := collections[string(collectionValue.ObjectPath)]; ok || !collectionValue.ObjectPath.IsValid() {
+			return nil, fmt.Errorf("invalid collection path '%s' in backup", collectionValue.ObjectPath)
+		}
+
+		collection := newCollectionFromDb(service, collectionValue)
+
+		for _, itemValue := range collectionValue.Items {
+
+			if items[itemValue.ObjectPath] || !itemValue.ObjectPath.IsValid() ||
+				!strings.HasPrefix(string(itemValue.ObjectPath), string(collection.ObjectPath)+"/") {
+				return nil, fmt.Errorf("invalid item path '%s' in backup", itemValue.ObjectPath)
+			}
+
+			items[itemValue.ObjectPath] = true
+			item := newItemFromDb(collection, itemValue, itemValue.Secret.SecretText)
+			collection.Items[string(item.ObjectPath)] = item
+		}
+
+		collections[string(collection.ObjectPath)] = collection
+	}
+
+	// there is always a default collection
+	if _, ok := collections[string(defaultPath)]; !ok {
+		epoch := Epoch()
+		collection := NewCollection(service)
+		collection.Alias = "default"
+		collection.ObjectPath = defaultPath
+		collection.Properties = map[string]dbus.Variant{"Label": dbus.MakeVariant("default")}
+		collection.Created = epoch
+		collection.Modified = epoch
+		collections[string(defaultPath)] = collection
+	}
+
+	return collections, nil
+}
+
+// dbusSwapCollections replaces dbus objects of previous collections and
+// items with current ones and emits signals of the changes
+func dbusSwapCollections(service *Service, previous map[string]*Collection, current map[string]*Collection) {
+
+	for path, collection := range previous {
+		currentCollection, ok := current[path]
+		collection.ItemsMutex.RLock()
+		for itemPath, item := range collection.Items {
+			if !ok || currentCollection.Items[itemPath] == nil {
+				dbusRemoveItem(item)
+			}
+		}
+		collection.ItemsMutex.RUnlock()
+		if !ok {
+			dbusRemoveCollection(collection)
+		}
+	}
+
+	for _, collection := range current {
+		if collection.Alias == "default" {
+			dbusDefaultCollection(collection, collection.Locked, collection.Created, collection.Modified)
+		} else {
+			dbusAddCollection(collection, collection.Locked, collection.Created, collection.Modified)
+		}
+		for _, item := range collection.Items {
+			dbusAddItem(collection, item, item.Locked, item.Created, item.Modified)
+		}
+		collection.UpdatePropertyCollectionItems()
+	}
+
+	dbusUpdateCollections(service)
+	service.UpdatePropertyCollections()
+
+	for path, collection := range previous {
+		currentCollection, ok := current[path]
+		if !ok {
+			collection.SignalCollectionDeleted()
+			continue
+		}
+		collection.ItemsMutex.RLock()
+		for itemPath, item := range collection.Items {
+			if currentCollection.Items[itemPath] == nil {
+				item.SignalItemDeleted()
+			}
+		}
+		collection.ItemsMutex.RUnlock()
+	}
+
+	for path, collection := range current {
+		previousCollection, ok := previous[path]
+		if !ok {
+			collection.SignalCollectionCreated()
+		} else {
+			collection.SignalCollectionChanged()
+		}
+		for itemPath, item := range collection.Items {
+			if ok && previousCollection.Items[itemPath] != nil {
+				item.SignalItemChanged()
+			} else {
+				item.SignalItemCreated()
+			}
+		}
+	}
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< RestoreBackup <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> PruneBackups >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// ListBackups returns backups of given directory, newest first. Files
+// which are not backups are ignored.
+func ListBackups(directory string) ([]BackupFile, error) {
+
+	entries, err := ioutil.ReadDir(directory)
+
+	if os.IsNotExist(err) {
+		return []BackupFile{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backup directory. Error: %v", err)
+	}
+
+	backups := []BackupFile{}
+
+	for _, entry := range entries {
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		backupFile := filepath.Join(directory, entry.Name())
+		manifest, err := ReadBackupManifest(backupFile)
+
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, BackupFile{Path: backupFile, Size: entry.Size(), Manifest: *manifest})
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Manifest.Created != backups[j].Manifest.Created {
+			return backups[i].Manifest.Created > backups[j].Manifest.Created
+		}
+		return backups[i].Path > backups[j].Path
+	})
+
+	return backups, nil
+}
+
+// ExpiredBackups returns backups (newest first) not kept by retention: the
+// newest 'KeepLast' backups and the newest backup of each of the latest
+// 'KeepDaily' days and 'KeepWeekly' weeks (local time) are kept. The newest
+// backup is always kept.
+func ExpiredBackups(backups []BackupFile, retention BackupRetention) []BackupFile {
+
+	expired := []BackupFile{}
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+
+	for i, backup := range backups {
+
+		created := time.Unix(int64(backup.Manifest.Created), 0)
+		day := created.Format("2006-01-02")
+		year, week := created.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+		keep := i == 0 || i < retention.KeepLast
+
+		if !days[day] && len(days) < retention.KeepDaily {
+			days[day] = true
+			keep = true
+		}
+
+		if !weeks[weekKey] && len(weeks) < retention.KeepWeekly {
+			weeks[weekKey] = true
+			keep = true
+		}
+
+		if !keep {
+			expired = append(expired, backup)
+		}
+	}
+
+	return expired
+}
+
+// PruneBackups removes backups of given directory which are not kept by
+// retention and returns removed files
+func PruneBackups(directory string, retention BackupRetention) ([]string, error) {
+
+	backups, err := ListBackups(directory)
+
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+
+	for _, backup := range ExpiredBackups(backups, retention) {
+		if err := os.Remove(backup.Path); err != nil {
+			return removed, fmt.Errorf("cannot remove backup. Error: %v", err)
+		}
+		removed = append(removed, backup.Path)
+	}
+
+	return removed, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< PruneBackups <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ScheduleBackups >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// ScheduleBackups creates a backup (and prunes old ones) every backup
+// interval counting from the newest backup. Configuration changes are
+// applied on the next check. Scheduled backups are encrypted so they are
+// skipped without a master password.
+func ScheduleBackups(ctx context.Context, service *Service) {
+
+	hasMasterPassword := len(os.Getenv("MASTERPASSWORD")) == 32
+	warned := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+
+		settings := service.Settings()
+		interval := settings.BackupInterval
+
+		if interval <= 0 || service.IsLocked() {
+			continue
+		}
+
+		if !hasMasterPassword {
+			if !warned {
+				log.Warn("Scheduled backups are skipped, they need a 32 character MASTERPASSWORD")
+				warned = true
+			}
+			continue
+		}
+
+		directory := service.BackupDirectory()
+		backups, err := ListBackups(directory)
+
+		if err != nil {
+			log.Errorf("Cannot list backups. Error: %v", err)
+			continue
+		}
+
+		if len(backups) > 0 &&
+			time.Since(time.Unix(int64(backups[0].Manifest.Created), 0)) < interval {
+			continue
+		}
+
+		if _, err := service.createBackup(false); err != nil {
+			log.Errorf("Cannot create scheduled backup. Error: %v", err)
+			continue
+		}
+
+		removed, err := PruneBackups(directory, settings.BackupRetention)
+
+		if err != nil {
+			log.Errorf("Cannot prune backups. Error: %v", err)
+		}
+
+		for _, backupFile := range removed {
+			log.Infof("Backup pruned: %s", backupFile)
+		}
+	}
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ScheduleBackups <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+func Test_Backup(t *testing.T) {
+
+	const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+	backupFile := filepath.Join(t.TempDir(), "backup.json")
+	db := &service.Database{
+		Version: "0.1.0",
+		Collections: []service.DbCollection{{
+			ObjectPath: "/org/freedesktop/secrets/aliases/default",
+			Alias:      "default",
+			Items: []service.DbItem{
+				{ObjectPath: "/org/freedesktop/secrets/aliases/default/a",
+					Secret: service.DbSecret{SecretText: "Victoria"}},
+			},
+		}},
+	}
+
+	content, err := service.NewBackup(db, masterPassword, time.Now())
+
+	if err != nil {
+		t.Fatalf("NewBackup failed. Error: %v", err)
+	}
+
+	ioutil.WriteFile(backupFile, content, 0600)
+	manifest, err := service.ReadBackupManifest(backupFile)
+
+	if err != nil {
+		t.Fatalf("ReadBackupManifest failed. Error: %v", err)
+	}
+
+	if !manifest.Encrypted || manifest.Collections != 1 || manifest.Items != 1 {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+
+	restored, err := service.ReadBackup(backupFile, masterPassword)
+
+	if err != nil {
+		t.Fatalf("ReadBackup failed. Error: %v", err)
+	}
+
+	if secret := restored.Collections[0].Items[0].Secret.SecretText; secret != "Victoria" {
+		t.Errorf("Expected secret 'Victoria', got: '%s'", secret)
+	}
+
+	if _, err := service.ReadBackup(backupFile, "0123456789abcdefghijklmnopqrstuv"); err == nil {
+		t.Error("Expected ReadBackup with wrong master password to fail")
+	}
+
+	if _, err := service.ReadBackup(backupFile, ""); err == nil {
+		t.Error("Expected ReadBackup of encrypted backup without master password to fail")
+	}
+}
+
+func Test_ExpiredBackups(t *testing.T) {
+
+	// one backup every 12 hours for 30 days, newest first
+	now := time.Date(2024, 3, 31, 23, 0, 0, 0, time.Local)
+	backups := []service.BackupFile{}
+
+	for i := 0; i < 60; i++ {
+		created := now.Add(-time.Duration(i) * 12 * time.Hour)
+		backups = append(backups, service.BackupFile{
+			Path:     created.Format(time.RFC3339),
+			Manifest: service.BackupManifest{Created: uint64(created.Unix())},
+		})
+	}
+
+	for _, tc := range []struct {
+		retention service.BackupRetention
+		kept      int
+	}{
+		{service.BackupRetention{KeepLast: 3}, 3},
+		{service.BackupRetention{KeepLast: 1, KeepDaily: 7}, 7},
+		{service.BackupRetention{KeepLast: 3, KeepDaily: 2}, 3},
+		{service.BackupRetention{KeepLast: 1, KeepWeekly: 4}, 4},
+		// last 7 days overlap the newest backup of the current week
+		{service.BackupRetention{KeepLast: 7, KeepDaily: 7, KeepWeekly: 4}, 7 + 3 + 3},
+		{service.BackupRetention{KeepLast: 100}, 60},
+	} {
+		expired := service.ExpiredBackups(backups, tc.retention)
+
+		if kept := len(backups) - len(expired); kept != tc.kept {
+			t.Errorf("%+v: expected %d kept, got: %d", tc.retention, tc.kept, kept)
+		}
+
+		if len(expired) > 0 && expired[0].Path == backups[0].Path {
+			t.Errorf("%+v: newest backup expired", tc.retention)
+		}
+	}
+}
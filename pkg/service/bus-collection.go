@@ -130,6 +130,11 @@ func introspectCollectionByPath(collection *Collection, locked bool, created uin
 				Writable: true,
 				Emit:     prop.EmitTrue,
 				Callback: func(p *prop.Change) *dbus.Error {
+					// service cannot be locked while collection changes
+					if !collection.Parent.rLockUnlocked() {
+						return ApiErrorIsLocked()
+					}
+					defer collection.Parent.LockMutex.RUnlock()
 					if p.Name == "Label" {
 						collection.DataMutex.Lock()
 						collection.Label = p.Value.(string)
@@ -173,7 +178,7 @@ func introspectCollectionByPath(collection *Collection, locked bool, created uin
 			READ Array<ObjectPath> Items ;
 		*/
 		props["Items"] = &prop.Prop{
-			Value:    []string{},
+			Value:    []dbus.ObjectPath{},
 			Writable: false,
 			Emit:     prop.EmitTrue,
 		}
@@ -308,3 +313,13 @@ func dbusUpdateCollections(service *Service) {
 	}), "/org/freedesktop/secrets/collection", "org.freedesktop.DBus.Introspectable")
 
 }
+
+// dbusRemoveCollection removes collection object from dbus
+func dbusRemoveCollection(collection *Collection) {
+
+	connection := collection.Parent.Connection
+
+	connection.Export(nil, collection.ObjectPath, "org.freedesktop.Secret.Collection")
+	connection.Export(nil, collection.ObjectPath, "org.freedesktop.DBus.Properties")
+	connection.Export(nil, collection.ObjectPath, "org.freedesktop.DBus.Introspectable")
+}
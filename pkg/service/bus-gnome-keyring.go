@@ -0,0 +1,119 @@
+package service
+
+import (
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+// dbusGnomeKeyring exports gnome-keyring private interface on service dbus
+// path ('/org/freedesktop/secrets') and returns its introspection data
+func dbusGnomeKeyring(service *Service) introspect.Interface {
+
+	////////////////////////////// Methods //////////////////////////////
+
+	/*
+		ChangeWithPrompt ( IN  ObjectPath collection,
+		                   OUT ObjectPath prompt);
+	*/
+	changeWithPrompt := []introspect.Arg{
+		{
+			Name:      "collection",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "prompt",
+			Type:      "o",
+			Direction: "out",
+		},
+	}
+
+	/*
+		CreateWithMasterPassword ( IN  Dict<String,Variant> properties,
+		                           IN  Secret master,
+		                           OUT ObjectPath collection);
+	*/
+	createWithMasterPassword := []introspect.Arg{
+		{
+			Name:      "attributes",
+			Type:      "a{sv}",
+			Direction: "in",
+		},
+		{
+			Name:      "master",
+			Type:      "(oayays)",
+			Direction: "in",
+		},
+		{
+			Name:      "collection",
+			Type:      "o",
+			Direction: "out",
+		},
+	}
+
+	/*
+		ChangeWithMasterPassword ( IN  ObjectPath collection,
+		                           IN  Secret original,
+		                           IN  Secret master);
+	*/
+	changeWithMasterPassword := []introspect.Arg{
+		{
+			Name:      "collection",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "original",
+			Type:      "(oayays)",
+			Direction: "in",
+		},
+		{
+			Name:      "master",
+			Type:      "(oayays)",
+			Direction: "in",
+		},
+	}
+
+	/*
+		UnlockWithMasterPassword ( IN  ObjectPath collection,
+		                           IN  Secret master);
+	*/
+	unlockWithMasterPassword := []introspect.Arg{
+		{
+			Name:      "collection",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "master",
+			Type:      "(oayays)",
+			Direction: "in",
+		},
+	}
+
+	/////////////////////////////////// dbus ///////////////////////////////////
+
+	service.Connection.Export(service.GnomeKeyring, "/org/freedesktop/secrets",
+		GnomeKeyringInterface)
+
+	return introspect.Interface{
+		Name: GnomeKeyringInterface, // interface name
+		Methods: []introspect.Method{
+			{
+				Name: "ChangeWithPrompt",
+				Args: changeWithPrompt,
+			},
+			{
+				Name: "CreateWithMasterPassword",
+				Args: createWithMasterPassword,
+			},
+			{
+				Name: "ChangeWithMasterPassword",
+				Args: changeWithMasterPassword,
+			},
+			{
+				Name: "UnlockWithMasterPassword",
+				Args: unlockWithMasterPassword,
+			},
+		},
+	}
+}
@@ -72,11 +72,38 @@ func introspectItemByPath(item *Item, locked bool, created uint64, modified uint
 				Writable: true,
 				Emit:     prop.EmitTrue,
 				Callback: func(p *prop.Change) *dbus.Error {
+					// service cannot be locked while item changes
+					if !item.Parent.Parent.rLockUnlocked() {
+						return ApiErrorIsLocked()
+					}
+					defer item.Parent.Parent.LockMutex.RUnlock()
 					if p.Name == "Label" {
 						item.DataMutex.Lock()
 						item.Label = p.Value.(string)
 						item.DataMutex.Unlock()
 					}
+					if p.Name == "Type" {
+						itemType, ok := p.Value.(string)
+						if !ok {
+							return DbusErrorInvalidArgs("'Type' property should be of type 'string'")
+						}
+						schemaName := itemType
+						if schemaName == "" {
+							schemaName = item.GetLookupAttribute(SchemaAttribute)
+						}
+						if schema := item.Parent.Parent.GetSchema(schemaName); schema != nil {
+							item.LookupAttributesMutex.RLock()
+							err := schema.Validate(item.LookupAttributes)
+							item.LookupAttributesMutex.RUnlock()
+							if err != nil {
+								log.Warnf("Rejected 'Type' of item '%v'. Error: %v", item.ObjectPath, err)
+								return DbusErrorInvalidArgs(err.Error())
+							}
+						}
+						item.DataMutex.Lock()
+						item.Type = itemType
+						item.DataMutex.Unlock()
+					}
 					item.Properties[p.Name] = dbus.MakeVariant(p.Value)
 					log.Infof("Property '%v' of item '%v' changed to: %v",
 						p.Name, item.ObjectPath, p.Value)
@@ -102,17 +129,43 @@ func introspectItemByPath(item *Item, locked bool, created uint64, modified uint
 		item.DataMutex.Unlock()
 
 		/*
-			READ Array<ObjectPath> Items ;
+			READWRITE Dict<String,String> Attributes ;
 		*/
+		propertyAttributes := map[string]string{}
+		item.LookupAttributesMutex.RLock()
+		for k, v := range item.LookupAttributes {
+			propertyAttributes[k] = v
+		}
+		item.LookupAttributesMutex.RUnlock()
+
 		props["Attributes"] = &prop.Prop{
-			Value:    map[string]string{},
+			Value:    propertyAttributes,
 			Writable: true,
 			Emit:     prop.EmitTrue,
 			Callback: func(p *prop.Change) *dbus.Error {
+				// service cannot be locked while item changes
+				if !item.Parent.Parent.rLockUnlocked() {
+					return ApiErrorIsLocked()
+				}
+				defer item.Parent.Parent.LockMutex.RUnlock()
 				if attributes, ok := p.Value.(map[string]string); ok {
+					item.DataMutex.RLock()
+					schemaName := item.Type
+					item.DataMutex.RUnlock()
+					if schemaName == "" {
+						schemaName = attributes[SchemaAttribute]
+					}
+					if schema := item.Parent.Parent.GetSchema(schemaName); schema != nil {
+						if err := schema.Validate(attributes); err != nil {
+							log.Warnf("Rejected 'Attributes' of item '%v'. Error: %v", item.ObjectPath, err)
+							return DbusErrorInvalidArgs(err.Error())
+						}
+					}
 					item.DataMutex.Lock()
 					item.LookupAttributes = attributes
 					item.DataMutex.Unlock()
+					// new value is stored into the property map, old keys must go
+					clearMap(propertyAttributes)
 					log.Infof("Property '%v' of item '%v' changed to: %v",
 						p.Name, item.ObjectPath, p.Value)
 
@@ -239,3 +292,13 @@ func dbusUpdateItems(collection *Collection, locked bool, created uint64, modifi
 		collection.ObjectPath, "org.freedesktop.DBus.Introspectable")
 
 }
+
+// dbusRemoveItem removes item object from dbus
+func dbusRemoveItem(item *Item) {
+
+	connection := item.Parent.Parent.Connection
+
+	connection.Export(nil, item.ObjectPath, "org.freedesktop.Secret.Item")
+	connection.Export(nil, item.ObjectPath, "org.freedesktop.DBus.Properties")
+	connection.Export(nil, item.ObjectPath, "org.freedesktop.DBus.Introspectable")
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	log "github.com/sirupsen/logrus"
+)
+
+// kwalletMethods maps KWallet method names to their dbus names
+var kwalletMethods = map[string]string{
+	"IsEnabled":     "isEnabled",
+	"Open":          "open",
+	"Close":         "close",
+	"IsOpen":        "isOpen",
+	"Wallets":       "wallets",
+	"LocalWallet":   "localWallet",
+	"NetworkWallet": "networkWallet",
+	"FolderList":    "folderList",
+	"HasFolder":     "hasFolder",
+	"CreateFolder":  "createFolder",
+	"EntryList":     "entryList",
+	"HasEntry":      "hasEntry",
+	"EntryType":     "entryType",
+	"ReadPassword":  "readPassword",
+	"WritePassword": "writePassword",
+	"ReadMap":       "readMap",
+	"WriteMap":      "writeMap",
+	"RemoveEntry":   "removeEntry",
+}
+
+// kwalletArgs makes introspection arguments from 'name:type' pairs,
+// all arguments are inputs except the last one which is output
+func kwalletArgs(args ...string) []introspect.Arg {
+
+	result := []introspect.Arg{}
+
+	for i, arg := range args {
+		nameType := strings.SplitN(arg, ":", 2)
+		direction := "in"
+		if i == len(args)-1 {
+			direction = "out"
+		}
+		result = append(result, introspect.Arg{
+			Name:      nameType[0],
+			Type:      nameType[1],
+			Direction: direction,
+		})
+	}
+
+	return result
+}
+
+// dbusKWallet owns KWallet names on dbus and creates KWallet objects and interfaces
+func dbusKWallet(service *Service) {
+
+	////////////////////////////// Methods //////////////////////////////
+
+	methods := []introspect.Method{
+		{Name: "isEnabled", Args: kwalletArgs("enabled:b")},
+		{Name: "open", Args: kwalletArgs("wallet:s", "wId:x", "appid:s", "handle:i")},
+		{Name: "close", Args: kwalletArgs("handle:i", "force:b", "appid:s", "result:i")},
+		{Name: "isOpen", Args: kwalletArgs("wallet:s", "open:b")},
+		{Name: "wallets", Args: kwalletArgs("wallets:as")},
+		{Name: "localWallet", Args: kwalletArgs("wallet:s")},
+		{Name: "networkWallet", Args: kwalletArgs("wallet:s")},
+		{Name: "folderList", Args: kwalletArgs("handle:i", "appid:s", "folders:as")},
+		{Name: "hasFolder", Args: kwalletArgs("handle:i", "folder:s", "appid:s", "exists:b")},
+		{Name: "createFolder", Args: kwalletArgs("handle:i", "folder:s", "appid:s", "created:b")},
+		{Name: "entryList", Args: kwalletArgs("handle:i", "folder:s", "appid:s", "entries:as")},
+		{Name: "hasEntry", Args: kwalletArgs("handle:i", "folder:s", "key:s", "appid:s", "exists:b")},
+		{Name: "entryType", Args: kwalletArgs("handle:i", "folder:s", "key:s", "appid:s", "type:i")},
+		{Name: "readPassword", Args: kwalletArgs("handle:i", "folder:s", "key:s", "appid:s", "password:s")},
+		{Name: "writePassword", Args: kwalletArgs("handle:i", "folder:s", "key:s", "value:s", "appid:s", "result:i")},
+		{Name: "readMap", Args: kwalletArgs("handle:i", "folder:s", "key:s", "appid:s", "value:ay")},
+		{Name: "writeMap", Args: kwalletArgs("handle:i", "folder:s", "key:s", "value:ay", "appid:s", "result:i")},
+		{Name: "removeEntry", Args: kwalletArgs("handle:i", "folder:s", "key:s", "appid:s", "result:i")},
+	}
+
+	////////////////////////////// Signals //////////////////////////////
+
+	signals := []introspect.Signal{
+		{Name: "walletOpened", Args: []introspect.Arg{{Name: "wallet", Type: "s"}}},
+		{Name: "walletClosed", Args: []introspect.Arg{{Name: "wallet", Type: "s"}}},
+		{Name: "folderListUpdated", Args: []introspect.Arg{{Name: "wallet", Type: "s"}}},
+		{Name: "folderUpdated", Args: []introspect.Arg{{Name: "wallet", Type: "s"}, {Name: "folder", Type: "s"}}},
+	}
+
+	/////////////////////////////////// dbus ///////////////////////////////////
+
+	for name, path := range KWalletBusNames {
+
+		reply, err := service.Connection.RequestName(name, dbus.NameFlagDoNotQueue)
+
+		if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+			log.Warnf("Cannot acquire '%s' name on dbus (is kwalletd running?). Error: %v", name, err)
+			continue
+		}
+
+		introKWallet := &introspect.Node{
+			Name: string(path),
+			Interfaces: []introspect.Interface{
+				introspect.IntrospectData,
+				{
+					Name:    KWalletInterface, // interface name
+					Methods: methods,
+					Signals: signals,
+				},
+			},
+		}
+
+		service.Connection.ExportWithMap(service.KWallet, kwalletMethods, path, KWalletInterface)
+
+		service.Connection.Export(introspect.NewIntrospectable(introKWallet),
+			path, "org.freedesktop.DBus.Introspectable")
+
+		log.Infof("KWallet compatibility layer at: %s %s", name, path)
+	}
+}
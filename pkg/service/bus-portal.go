@@ -0,0 +1,101 @@
+package service
+
+import (
+	"log"
+
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// dbusPortal creates Flatpak secret portal backend objects and interfaces on dbus
+func dbusPortal(service *Service) {
+
+	////////////////////////////// Methods //////////////////////////////
+
+	/*
+		RetrieveSecret ( IN  ObjectPath handle,
+		                 IN  String app_id,
+		                 IN  UnixFD fd,
+		                 IN  Dict<String,Variant> options,
+		                 OUT UInt32 response,
+		                 OUT Dict<String,Variant> results);
+	*/
+	retrieveSecret := []introspect.Arg{
+		{
+			Name:      "handle",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "app_id",
+			Type:      "s",
+			Direction: "in",
+		},
+		{
+			Name:      "fd",
+			Type:      "h",
+			Direction: "in",
+		},
+		{
+			Name:      "options",
+			Type:      "a{sv}",
+			Direction: "in",
+		},
+		{
+			Name:      "response",
+			Type:      "u",
+			Direction: "out",
+		},
+		{
+			Name:      "results",
+			Type:      "a{sv}",
+			Direction: "out",
+		},
+	}
+
+	////////////////////////////// Properties //////////////////////////////
+
+	/*
+		READ UInt32 version ;
+	*/
+
+	propsSpec := map[string]map[string]*prop.Prop{
+		PortalInterface: {
+			"version": {
+				Value:    uint32(1),
+				Writable: false,
+				Emit:     prop.EmitFalse,
+			},
+		},
+	}
+
+	props, err := prop.Export(service.Connection, PortalPath, propsSpec)
+	if err != nil {
+		log.Panicf("export 'Portal' propsSpec failed: %v", err)
+	}
+
+	/////////////////////////////////// dbus ///////////////////////////////////
+
+	introPortal := &introspect.Node{
+		Name: string(PortalPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData, prop.IntrospectData,
+			{
+				Name: PortalInterface, // interface name
+				Methods: []introspect.Method{
+					{
+						Name: "RetrieveSecret",
+						Args: retrieveSecret,
+					},
+				},
+				Properties: props.Introspection(PortalInterface),
+			},
+		},
+	}
+
+	service.Connection.Export(service.Portal, PortalPath, PortalInterface)
+
+	service.Connection.Export(introspect.NewIntrospectable(introPortal),
+		PortalPath, "org.freedesktop.DBus.Introspectable")
+
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/introspect"
 	"github.com/godbus/dbus/v5/prop"
 )
@@ -33,26 +34,142 @@ func dbusSecretService(service *Service) {
 		},
 	}
 
-	////////////////////////////// Signals //////////////////////////////
+	////////////////////////////// Status //////////////////////////////
 
 	/*
-		ServiceLocked;
+		Status ( OUT Struct status );
 	*/
-	serviceLocked := []introspect.Arg{
+	status := []introspect.Arg{
 		{
-			Name: "collection",
+			Name:      "status",
+			Type:      dbus.SignatureOf(Status{}).String(),
+			Direction: "out",
 		},
 	}
 
+	////////////////////////////// Service Lock //////////////////////////////
+
 	/*
-		ServiceUnlocked;
+		ServiceLock ( void );
+	*/
+	serviceLock := []introspect.Arg{}
+
+	/*
+		ServiceUnlock ( IN Secret master );
+	*/
+	serviceUnlock := []introspect.Arg{
+		{
+			Name:      "master",
+			Type:      "(oayays)",
+			Direction: "in",
+		},
+	}
+
+	////////////////////////////// Aliases //////////////////////////////
+
+	/*
+		ListAliases ( OUT Dict<String,ObjectPath> aliases );
+	*/
+	listAliases := []introspect.Arg{
+		{
+			Name:      "aliases",
+			Type:      "a{so}",
+			Direction: "out",
+		},
+	}
+
+	////////////////////////////// OTP //////////////////////////////
+
+	/*
+		OtpCode ( IN  ObjectPath item,
+							OUT String code,
+							OUT UInt32 remaining);
+	*/
+	otpCode := []introspect.Arg{
+		{
+			Name:      "item",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "code",
+			Type:      "s",
+			Direction: "out",
+		},
+		{
+			Name:      "remaining",
+			Type:      "u",
+			Direction: "out",
+		},
+	}
+
+	////////////////////////////// Backup //////////////////////////////
+
+	/*
+		CreateBackup ( IN  Boolean plain,
+									 OUT String file);
+	*/
+	createBackup := []introspect.Arg{
+		{
+			Name:      "plain",
+			Type:      "b",
+			Direction: "in",
+		},
+		{
+			Name:      "file",
+			Type:      "s",
+			Direction: "out",
+		},
+	}
+
+	/*
+		RestoreBackup ( IN String file);
 	*/
-	serviceUnlocked := []introspect.Arg{
+	restoreBackup := []introspect.Arg{
 		{
-			Name: "collection",
+			Name:      "file",
+			Type:      "s",
+			Direction: "in",
 		},
 	}
 
+	////////////////////////////// Items //////////////////////////////
+
+	/*
+		SetItemTimes ( IN ObjectPath item,
+									 IN UInt64 created,
+									 IN UInt64 modified);
+	*/
+	setItemTimes := []introspect.Arg{
+		{
+			Name:      "item",
+			Type:      "o",
+			Direction: "in",
+		},
+		{
+			Name:      "created",
+			Type:      "t",
+			Direction: "in",
+		},
+		{
+			Name:      "modified",
+			Type:      "t",
+			Direction: "in",
+		},
+	}
+
+	////////////////////////////// Signals //////////////////////////////
+
+	/*
+		ServiceLocked;
+	*/
+	serviceLocked := []introspect.Arg{}
+
+	/*
+		ServiceUnlocked;
+	*/
+	serviceUnlocked := []introspect.Arg{}
+
 	/////////////////////////////////// dbus ///////////////////////////////////
 
 	introSecretService := &introspect.Node{
@@ -66,6 +183,38 @@ func dbusSecretService(service *Service) {
 						Name: "Command",
 						Args: command,
 					},
+					{
+						Name: "Status",
+						Args: status,
+					},
+					{
+						Name: "ServiceLock",
+						Args: serviceLock,
+					},
+					{
+						Name: "ServiceUnlock",
+						Args: serviceUnlock,
+					},
+					{
+						Name: "ListAliases",
+						Args: listAliases,
+					},
+					{
+						Name: "OtpCode",
+						Args: otpCode,
+					},
+					{
+						Name: "CreateBackup",
+						Args: createBackup,
+					},
+					{
+						Name: "RestoreBackup",
+						Args: restoreBackup,
+					},
+					{
+						Name: "SetItemTimes",
+						Args: setItemTimes,
+					},
 				},
 				Signals: []introspect.Signal{
 					{
@@ -73,7 +222,7 @@ func dbusSecretService(service *Service) {
 						Args: serviceLocked,
 					},
 					{
-						Name: "serviceUnlocked",
+						Name: "ServiceUnlocked",
 						Args: serviceUnlocked,
 					},
 				},
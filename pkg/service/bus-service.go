@@ -321,6 +321,8 @@ func dbusService(service *Service) {
 				},
 				Properties: PropsService.Introspection("org.freedesktop.Secret.Service"),
 			},
+			// gnome-keyring private interface (collection master passwords)
+			dbusGnomeKeyring(service),
 		},
 	}
 
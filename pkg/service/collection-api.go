@@ -33,7 +33,10 @@ func (c *Collection) Delete() (dbus.ObjectPath, *dbus.Error) {
 		return dbus.ObjectPath("/"), DbusErrorCallFailed("Cannot delete default collection")
 	}
 
-	c.Parent.RemoveCollection(c)
+	if err := c.Parent.RemoveCollection(c); err != nil {
+		log.Warn("A backup is being restored")
+		return dbus.ObjectPath("/"), dbusErrorRestoring()
+	}
 	c.SignalCollectionDeleted()
 	c.Parent.UpdatePropertyCollections()
 
@@ -63,7 +66,13 @@ func (c *Collection) SearchItems(
 
 	var items []dbus.ObjectPath
 
+	// 'xdg:schema' scopes the search to items of that schema (Type)
+	schema, attributes := SplitSchema(attributes)
+
 	for _, item := range c.Items {
+		if !item.MatchSchema(schema) {
+			continue
+		}
 		if IsMapSubsetSingleMatch(item.LookupAttributes, attributes, c.ItemsMutex) {
 			items = append(items, item.ObjectPath)
 		}
@@ -98,6 +107,16 @@ func (c *Collection) CreateItem(properties map[string]dbus.Variant,
 		"replace":         replace,
 	}).Trace("Method called by client")
 
+	if c.Parent.IsLocked() {
+		log.Warn("Service is locked")
+		return "/", "/", ApiErrorIsLocked()
+	}
+
+	if c.Locked && c.IsProtected() {
+		log.Warnf("Collection is locked with master password: %v", c.ObjectPath)
+		return "/", "/", ApiErrorIsLocked()
+	}
+
 	if len(properties) == 0 {
 		log.Warn("Client asked to create an item with empty 'properties' (no Label, no Attributes)")
 		// DOcumentation is silent about this situation so let it be allowed:
@@ -106,12 +125,22 @@ func (c *Collection) CreateItem(properties map[string]dbus.Variant,
 	item := NewItem(c)
 	item.SetProperties(properties)
 
+	if err := c.Parent.ValidateItemSchema(item); err != nil {
+		log.Warnf("Rejected new item in collection '%v'. Error: %v", c.ObjectPath, err)
+		return dbus.ObjectPath("/"), dbus.ObjectPath("/"), DbusErrorInvalidArgs(err.Error())
+	}
+
 	item.Secret.SecretApi = &secretApi
 	item.ObjectPath = dbus.ObjectPath(string(c.ObjectPath) + "/" + UUID())
 
 	epoch := Epoch()
 	err := c.AddItem(item, replace, true, false, epoch, epoch, false)
 
+	if err == errRestoring {
+		log.Warn("A backup is being restored")
+		return dbus.ObjectPath("/"), dbus.ObjectPath("/"), dbusErrorRestoring()
+	}
+
 	if err != nil {
 		return dbus.ObjectPath("/"), dbus.ObjectPath("/"), ApiErrorNoSession()
 	}
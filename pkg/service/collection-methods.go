@@ -54,6 +54,19 @@ func DefaultCollection(parent *Service, locked bool, created uint64, modified ui
 func (collection *Collection) AddItem(item *Item, replace bool, saveData bool,
 	locked bool, created uint64, modified uint64, inPlace bool) error {
 
+	if !collection.Parent.rLockUnlocked() {
+		return errors.New("service is locked")
+	}
+
+	// collections cannot be swapped by restoring a backup meanwhile
+	collection.Parent.CollectionsMutex.RLock()
+
+	if collection.Parent.isRestoring() {
+		collection.Parent.CollectionsMutex.RUnlock()
+		collection.Parent.LockMutex.RUnlock()
+		return errRestoring
+	}
+
 	collection.ItemsMutex.Lock()
 	if replace {
 		for _, collectionItem := range collection.Items {
@@ -74,6 +87,8 @@ func (collection *Collection) AddItem(item *Item, replace bool, saveData bool,
 	} else {
 		session := collection.Parent.GetSessionByPath(item.Secret.SecretApi.Session)
 		if session == nil {
+			collection.Parent.CollectionsMutex.RUnlock()
+			collection.Parent.LockMutex.RUnlock()
 			log.Warn("Secret session is missing")
 			return errors.New("Secret session is missing")
 		}
@@ -84,6 +99,8 @@ func (collection *Collection) AddItem(item *Item, replace bool, saveData bool,
 			iv := item.Secret.SecretApi.Parameters
 			secret, err := crypto.AesCBCDecrypt(iv, item.Secret.SecretApi.Value, session.SymmetricKey)
 			if err != nil {
+				collection.Parent.CollectionsMutex.RUnlock()
+				collection.Parent.LockMutex.RUnlock()
 				log.Errorf("Cannot add item due to decryption error. Error: %v", err)
 				return errors.New("Decryption error: " + err.Error())
 			}
@@ -95,6 +112,8 @@ func (collection *Collection) AddItem(item *Item, replace bool, saveData bool,
 	collection.ItemsMutex.Lock()
 	collection.Items[string(item.ObjectPath)] = item
 	collection.ItemsMutex.Unlock()
+	collection.Parent.CollectionsMutex.RUnlock()
+	collection.Parent.LockMutex.RUnlock()
 
 	// add item object to dbus
 	dbusAddItem(collection, item, locked, created, modified)
@@ -106,21 +125,55 @@ func (collection *Collection) AddItem(item *Item, replace bool, saveData bool,
 	return nil
 }
 
-// RemoveItem removes an item from collection's item map
-func (collection *Collection) RemoveItem(item *Item) {
+// CreatePlainItem creates a new item in collection from given
+// properties and plain secret (no session is involved)
+func (collection *Collection) CreatePlainItem(properties map[string]dbus.Variant,
+	plainSecret string, contentType string) (*Item, error) {
+
+	item := NewItem(collection)
+	item.SetProperties(properties)
+	item.ObjectPath = dbus.ObjectPath(string(collection.ObjectPath) + "/" + UUID())
+	item.Secret.PlainSecret = plainSecret
+	item.Secret.SecretApi.ContentType = contentType
+
+	epoch := Epoch()
+	if err := collection.AddItem(item, false, true, false, epoch, epoch, true); err != nil {
+		return nil, err
+	}
+
+	collection.UpdateModified()
+	item.SignalItemCreated()
+	collection.UpdatePropertyCollectionItems()
+
+	return item, nil
+}
+
+// RemoveItem removes an item from collection's item map, restoring a backup
+// is checked under the lock which guards swapping collections
+func (collection *Collection) RemoveItem(item *Item) error {
+	collection.Parent.CollectionsMutex.RLock()
+	if collection.Parent.isRestoring() {
+		collection.Parent.CollectionsMutex.RUnlock()
+		return errRestoring
+	}
 	collection.ItemsMutex.Lock()
 	_, ok := collection.Items[string(item.ObjectPath)]
 	if !ok {
+		collection.ItemsMutex.Unlock()
+		collection.Parent.CollectionsMutex.RUnlock()
 		log.Errorf("Item doesn't exist to be removed: %v",
 			item.ObjectPath)
-		return
+		return nil
 	}
 	delete(collection.Items, string(item.ObjectPath))
 	collection.ItemsMutex.Unlock()
+	collection.Parent.CollectionsMutex.RUnlock()
 	epoch := Epoch()
 	dbusUpdateItems(collection, false, epoch, epoch)
 	log.Infof("Item removed: %v", item.ObjectPath)
 	collection.SaveData()
+
+	return nil
 }
 
 // GetItemByPath returns the collection with given dbus object path, otherwise null
@@ -136,6 +189,19 @@ func (collection *Collection) GetItemByPath(itemPath dbus.ObjectPath) *Item {
 	return nil
 }
 
+// GetItemByAttributes returns the first item having all given lookup attributes, otherwise null
+func (collection *Collection) GetItemByAttributes(attributes map[string]string) *Item {
+	collection.ItemsMutex.RLock()
+	defer collection.ItemsMutex.RUnlock()
+
+	for _, item := range collection.Items {
+		if IsMapSubsetFullMatch(item.LookupAttributes, attributes, item.LookupAttributesMutex) {
+			return item
+		}
+	}
+	return nil
+}
+
 // CreateMethodFromPath returns a.b.c.Foo when
 // collection path is /a/b/c/xyz and passed method is 'Foo'
 func (collection *Collection) CreateMethodFromPath(method string) string {
@@ -198,7 +264,7 @@ func (collection *Collection) GetProperty(name string) (dbus.Variant, error) {
 	variant, err := busObject.GetProperty("org.freedesktop.Secret.Collection." + name)
 
 	if err != nil {
-		return dbus.MakeVariant(nil), fmt.Errorf("error getting property '%s'. Error: %v", name, err)
+		return dbus.Variant{}, fmt.Errorf("error getting property '%s'. Error: %v", name, err)
 	}
 
 	collection.Properties[name] = variant
@@ -295,3 +361,52 @@ func (collection *Collection) Unlock() {
 	collection.Locked = false
 	collection.SetProperty("Locked", false)
 }
+
+// IsProtected returns true if collection is protected by a master password
+func (collection *Collection) IsProtected() bool {
+	collection.DataMutex.RLock()
+	defer collection.DataMutex.RUnlock()
+	return collection.PasswordHash != ""
+}
+
+// SetPassword sets collection master password. Empty password removes protection
+func (collection *Collection) SetPassword(password string) error {
+
+	hash := ""
+
+	if password != "" {
+		var err error
+		hash, err = crypto.HashPassword(password)
+		if err != nil {
+			return err
+		}
+	}
+
+	collection.DataMutex.Lock()
+	collection.PasswordHash = hash
+	collection.DataMutex.Unlock()
+
+	return nil
+}
+
+// VerifyPassword returns true if given password is collection master
+// password. Collections without master password accept any password.
+func (collection *Collection) VerifyPassword(password string) bool {
+
+	collection.DataMutex.RLock()
+	hash := collection.PasswordHash
+	collection.DataMutex.RUnlock()
+
+	if hash == "" {
+		return true
+	}
+
+	ok, err := crypto.VerifyPassword(password, hash)
+	if err != nil {
+		log.Errorf("Cannot verify master password of collection '%v'. Error: %v",
+			collection.ObjectPath, err)
+		return false
+	}
+
+	return ok
+}
@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"syscall"
 
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
@@ -38,6 +42,8 @@ type DbCollection struct {
 	Label string `json:"label"`
 	// Is collection locked?
 	Locked bool `json:"locked"`
+	// Collection master password hash (argon2id), empty if not password protected
+	PasswordHash string `json:"passwordHash"`
 	// Collection creation time (epoch)
 	Created uint64 `json:"created"`
 	// Collection modification time (epoch)
@@ -60,6 +66,8 @@ type DbItem struct {
 	LookupAttributes map[string]string `json:"lookupAttributes"`
 	// Item label
 	Label string `json:"label"`
+	// Item type (libsecret schema name)
+	Type string `json:"type"`
 	// Is item locked?
 	Locked bool `json:"locked"`
 	// Item creation time (epoch)
@@ -76,6 +84,8 @@ type DbSecret struct {
 	Parent dbus.ObjectPath `json:"parent"`
 	// Secret without encryption
 	SecretText string `json:"secretText"`
+	// Content type of secret, empty means 'text/plain'
+	ContentType string `json:"contentType,omitempty"`
 }
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Entities <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -106,6 +116,16 @@ func RestoreData(service *Service) {
 		log.Panicf("Database is encrypted but cannot find a 32 character MASTERPASSWORD")
 	}
 
+	restoreCollections(service, db, encrypted, masterPassword)
+
+	close(service.DbLoadedChan) // Singal database has loaded
+	log.Info("Loading data finished successfully")
+}
+
+// restoreCollections creates collections and items of database (secrets
+// are decrypted by master password if database is encrypted)
+func restoreCollections(service *Service, db *Database, encrypted bool, masterPassword string) {
+
 	// Iterating db Collections
 	for _, collectionValue := range db.Collections {
 
@@ -114,21 +134,14 @@ func RestoreData(service *Service) {
 		// ignore creating default collection
 		if collectionValue.Alias == "default" {
 			collection = service.GetCollectionByAlias("default")
-		} else {
-			collection = NewCollection(service)
-			// collection.SetProperties(properties)
-			collection.Alias = collectionValue.Alias
-			collection.ObjectPath = collectionValue.ObjectPath
-			collection.Label = collectionValue.Label
-
-			// Set Collection properties
-			for k, v := range collectionValue.Properties {
-				collection.Properties[k] = dbus.MakeVariant(v)
+			// empty folders of default KWallet wallet
+			if folders, ok := collectionValue.Properties[KWalletFoldersProperty]; ok {
+				collection.DataMutex.Lock()
+				collection.Properties[KWalletFoldersProperty] = dbus.MakeVariant(folders)
+				collection.DataMutex.Unlock()
 			}
-
-			collection.Locked = collectionValue.Locked
-			collection.Created = collectionValue.Created
-			collection.Modified = collectionValue.Modified
+		} else {
+			collection = newCollectionFromDb(service, collectionValue)
 			service.AddCollection(collection, collection.Locked,
 				collection.Created, collection.Modified, false)
 			service.UpdatePropertyCollections()
@@ -136,24 +149,8 @@ func RestoreData(service *Service) {
 
 		// Collection Items
 		for _, ItemValue := range collectionValue.Items {
-			item := NewItem(collection)
-			item.ObjectPath = ItemValue.ObjectPath
 
-			// Set Item properties
-			for k, v := range ItemValue.Properties {
-				item.Properties[k] = dbus.MakeVariant(v)
-			}
-
-			// Set Item LookupAttributes
-			for k, v := range ItemValue.LookupAttributes {
-				item.LookupAttributes[k] = v
-			}
-
-			item.Locked = ItemValue.Locked
-			item.Created = ItemValue.Created
-			item.Modified = ItemValue.Modified
-
-			item.Secret.SecretApi.ContentType = "text/plain"
+			plainSecret := ItemValue.Secret.SecretText
 
 			if encrypted {
 				decrypted, err := crypto.DecryptAESCBC256(masterPassword, ItemValue.Secret.SecretText)
@@ -162,19 +159,79 @@ func RestoreData(service *Service) {
 						log.Panicf("Cannot decrypt database. Error: %v", err)
 					}
 				}
-				item.Secret.PlainSecret = decrypted
-			} else {
-				item.Secret.PlainSecret = ItemValue.Secret.SecretText
+				plainSecret = decrypted
 			}
 
+			item := newItemFromDb(collection, ItemValue, plainSecret)
 			collection.AddItem(item, false, false, item.Locked, item.Created, item.Modified, true)
 			collection.UpdatePropertyCollectionItems()
 		}
 
 	}
+}
 
-	close(service.DbLoadedChan) // Singal database has loaded
-	log.Info("Loading data finished successfully")
+// newCollectionFromDb returns a collection of database without its items,
+// collection is not on dbus
+func newCollectionFromDb(service *Service, collectionValue DbCollection) *Collection {
+
+	collection := NewCollection(service)
+	collection.Alias = collectionValue.Alias
+	collection.ObjectPath = collectionValue.ObjectPath
+	collection.Label = collectionValue.Label
+
+	// Set Collection properties
+	for k, v := range collectionValue.Properties {
+		collection.Properties[k] = dbus.MakeVariant(v)
+	}
+
+	collection.Locked = collectionValue.Locked
+	collection.PasswordHash = collectionValue.PasswordHash
+	// password protected collections stay locked until master password is given
+	if collection.PasswordHash != "" {
+		collection.Locked = true
+	}
+	collection.Created = collectionValue.Created
+	collection.Modified = collectionValue.Modified
+
+	return collection
+}
+
+// newItemFromDb returns an item of database with given plain secret, item
+// is not added to collection nor on dbus
+func newItemFromDb(collection *Collection, ItemValue DbItem, plainSecret string) *Item {
+
+	item := NewItem(collection)
+	item.ObjectPath = ItemValue.ObjectPath
+
+	// Set Item properties
+	for k, v := range ItemValue.Properties {
+		item.Properties[k] = dbus.MakeVariant(v)
+	}
+
+	// Set Item LookupAttributes
+	for k, v := range ItemValue.LookupAttributes {
+		item.LookupAttributes[k] = v
+	}
+
+	// Databases older than Type property use 'xdg:schema'
+	item.Type = ItemValue.Type
+	if item.Type == "" {
+		item.Type = item.LookupAttributes[SchemaAttribute]
+	}
+	item.Properties["Type"] = dbus.MakeVariant(item.Type)
+
+	item.Locked = ItemValue.Locked
+	item.Created = ItemValue.Created
+	item.Modified = ItemValue.Modified
+
+	item.Secret.SecretApi.ContentType = ItemValue.Secret.ContentType
+	if item.Secret.SecretApi.ContentType == "" {
+		item.Secret.SecretApi.ContentType = "text/plain"
+	}
+
+	item.Secret.PlainSecret = plainSecret
+
+	return item
 }
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< RestoreData <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -197,7 +254,19 @@ func PersistData(ctx context.Context, service *Service) {
 			dbLock.Lock()
 
 			log.Infof("Saving database at: '%s'", dbFile)
-			Marshal(service, dbFile)
+			err := Marshal(service, dbFile)
+
+			if err == errServiceLocked {
+				// secrets are sealed, database is saved after unlock
+				log.Debug("Service is locked, database will be saved after unlock")
+			} else if err == errRestoring {
+				log.Debug("Restoring a backup, database will be saved after restore")
+			} else {
+				if err != nil {
+					log.Errorf("Cannot save database. Error: %v", err)
+				}
+				service.setSaveStatus(err)
+			}
 
 			dbLock.Unlock()
 
@@ -209,10 +278,18 @@ func PersistData(ctx context.Context, service *Service) {
 
 /* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Marshal >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
 
-// Marshal converts dbus objects to JSON
-func Marshal(service *Service, dbFile string) {
+// errServiceLocked is returned by Marshal while secrets are sealed
+var errServiceLocked = errors.New("service is locked")
 
-	encrypt := service.Config.EncryptDatabase
+// Marshal converts dbus objects to JSON and writes them to given file
+func Marshal(service *Service, dbFile string) error {
+
+	// database is saved once restoring a backup is finished
+	if service.isRestoring() {
+		return errRestoring
+	}
+
+	encrypt := service.Settings().EncryptDatabase
 	masterPassword := os.Getenv("MASTERPASSWORD")
 
 	if len(masterPassword) > 0 && len(masterPassword) != 32 {
@@ -221,13 +298,37 @@ func Marshal(service *Service, dbFile string) {
 	}
 
 	if encrypt && len(masterPassword) != 32 {
-		log.Panicf("Cannot encrypt database with a non 32 character MASTERPASSWORD")
+		return errors.New("cannot encrypt database with a non 32 character MASTERPASSWORD")
+	}
+
+	db, err := service.snapshot(encrypt, masterPassword)
+
+	if err != nil {
+		return err
+	}
+
+	// save db to file
+	return WriteDatabase(db, dbFile)
+}
+
+// snapshot returns current collections and items as a database, secrets
+// are encrypted by master password if asked so
+func (service *Service) snapshot(encrypt bool, masterPassword string) (*Database, error) {
+
+	// secrets are sealed while service is locked, save after unlock. Lock is
+	// held so service cannot be locked while secrets are read.
+	service.LockMutex.RLock()
+	defer service.LockMutex.RUnlock()
+
+	if service.Locked {
+		return nil, errServiceLocked
 	}
 
 	db := Database{}
 	db.Version = "0.1.0"
 	db.Encrypted = encrypt
 	db.Collections = []DbCollection{}
+	var encryptionError error
 
 	service.CollectionsMutex.RLock()
 
@@ -264,12 +365,15 @@ func Marshal(service *Service, dbFile string) {
 
 			secret := DbSecret{}
 			secret.Parent = itemValue.ObjectPath
+			if itemValue.Secret.SecretApi.ContentType != "text/plain" {
+				secret.ContentType = itemValue.Secret.SecretApi.ContentType
+			}
 
 			if encrypt {
 				encrypted, err := crypto.EncryptAESCBC256(masterPassword, itemValue.Secret.PlainSecret)
 
 				if err != nil {
-					log.Panicf("Database encryption failed. Error: %v", err)
+					encryptionError = err
 				}
 
 				secret.SecretText = encrypted
@@ -283,6 +387,7 @@ func Marshal(service *Service, dbFile string) {
 
 			item.LookupAttributes = itemValue.LookupAttributes
 			item.Label = itemValue.Label
+			item.Type = itemValue.Type
 			itemValue.LockMutex.Lock()
 			item.Locked = itemValue.Locked
 			itemValue.LockMutex.Unlock()
@@ -306,6 +411,7 @@ func Marshal(service *Service, dbFile string) {
 		collectionValue.LockMutex.Lock()
 		collection.Locked = collectionValue.Locked
 		collectionValue.LockMutex.Unlock()
+		collection.PasswordHash = collectionValue.PasswordHash
 		collection.Created = collectionValue.Created
 		collection.Modified = collectionValue.Modified
 
@@ -316,17 +422,11 @@ func Marshal(service *Service, dbFile string) {
 
 	service.CollectionsMutex.RUnlock()
 
-	// save db to file
-
-	content, err := json.MarshalIndent(db, "", " ")
-	if err != nil {
-		log.Panicf("Cannot marshal database. Error: %v", err)
+	if encryptionError != nil {
+		return nil, fmt.Errorf("database encryption failed. Error: %v", encryptionError)
 	}
 
-	err = ioutil.WriteFile(dbFile, content, 0600)
-	if err != nil {
-		log.Panicf("Cannot write to database. Error: %v", err)
-	}
+	return &db, nil
 }
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Marshal <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -369,6 +469,186 @@ func Unmarshal(dbFile string) *Database {
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Unmarshal <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
 
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Offline >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// ErrDatabaseInUse is returned if another process holds the database lock
+var ErrDatabaseInUse = errors.New("database is in use by another process")
+
+// LockDatabase takes an exclusive lock ('db.lock' file) on database of given
+// home directory which is held until returned file is closed (or process
+// exits). secretserviced and offline tools use it to never run together.
+func LockDatabase(home string) (*os.File, error) {
+
+	lockFile, err := os.OpenFile(filepath.Join(home, "db.lock"), os.O_RDWR|os.O_CREATE, 0600)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database lock file. Error: %v", err)
+	}
+
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lockFile.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrDatabaseInUse
+		}
+		return nil, fmt.Errorf("cannot lock database. Error: %v", err)
+	}
+
+	// pid of lock holder helps finding it
+	if err := lockFile.Truncate(0); err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("cannot write database lock file. Error: %v", err)
+	}
+
+	if _, err := lockFile.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("cannot write database lock file. Error: %v", err)
+	}
+
+	return lockFile, nil
+}
+
+// ReadDatabase reads a database file as is (secrets are not decrypted)
+func ReadDatabase(dbFile string) (*Database, error) {
+
+	content, err := ioutil.ReadFile(dbFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read database. Error: %v", err)
+	}
+
+	var db Database
+
+	if err := json.Unmarshal(content, &db); err != nil {
+		return nil, fmt.Errorf("malformed database '%s'. Error: %v", dbFile, err)
+	}
+
+	return &db, nil
+}
+
+// CheckDatabase returns an error if database file cannot be read or its
+// secrets cannot be decrypted by given master password. A missing database
+// is fine (it is not saved yet).
+func CheckDatabase(dbFile string, masterPassword string) error {
+
+	if _, err := os.Stat(dbFile); os.IsNotExist(err) {
+		return nil
+	}
+
+	db, err := ReadDatabase(dbFile)
+
+	if err != nil {
+		return err
+	}
+
+	if !db.Encrypted {
+		return nil
+	}
+
+	if len(masterPassword) != 32 {
+		return errors.New("database is encrypted but there is no 32 character MASTERPASSWORD")
+	}
+
+	for _, collection := range db.Collections {
+		for _, item := range collection.Items {
+			if _, err := crypto.DecryptAESCBC256(masterPassword, item.Secret.SecretText); err != nil {
+				return errors.New("cannot decrypt database with MASTERPASSWORD")
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteDatabase writes database to file atomically with 0600 permissions
+func WriteDatabase(db *Database, dbFile string) error {
+
+	content, err := json.MarshalIndent(db, "", " ")
+
+	if err != nil {
+		return fmt.Errorf("cannot marshal database. Error: %v", err)
+	}
+
+	if err := writeFileAtomic(dbFile, content); err != nil {
+		return fmt.Errorf("cannot write to database. Error: %v", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes content to a temporary file (0600) in the same
+// directory and renames it to given file, so file is never half written
+func writeFileAtomic(file string, content []byte) error {
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+"-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), file)
+}
+
+// DecryptSecrets decrypts all secrets of an encrypted database in place
+func (db *Database) DecryptSecrets(masterPassword string) error {
+	return db.convertSecrets(true, masterPassword, crypto.DecryptAESCBC256)
+}
+
+// EncryptSecrets encrypts all secrets of a non-encrypted database in place
+func (db *Database) EncryptSecrets(masterPassword string) error {
+	return db.convertSecrets(false, masterPassword, crypto.EncryptAESCBC256)
+}
+
+// convertSecrets converts all secrets of a database which is encrypted
+// (or not) by given function and flips its encryption state
+func (db *Database) convertSecrets(encrypted bool, masterPassword string,
+	convert func(string, string) (string, error)) error {
+
+	if db.Encrypted != encrypted {
+		if encrypted {
+			return errors.New("database is not encrypted")
+		}
+		return errors.New("database is already encrypted")
+	}
+
+	if len(masterPassword) != 32 {
+		return fmt.Errorf("master password should be exactly 32 characters, got %d", len(masterPassword))
+	}
+
+	// convert a copy so database stays intact on failure
+	collections := make([]DbCollection, len(db.Collections))
+
+	for i, collection := range db.Collections {
+		collections[i] = collection
+		collections[i].Items = make([]DbItem, len(collection.Items))
+		for j, item := range collection.Items {
+			text, err := convert(masterPassword, item.Secret.SecretText)
+			if err != nil {
+				return fmt.Errorf("cannot convert secret of '%s'. Error: %v", item.ObjectPath, err)
+			}
+			collections[i].Items[j] = item
+			collections[i].Items[j].Secret.SecretText = text
+		}
+	}
+
+	db.Collections = collections
+	db.Encrypted = !encrypted
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Offline <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
 // fileOrFolderExists returns true if a file/folder exist otherwise false
 func fileOrFolderExists(path string) (bool, error) {
 	_, err := os.Stat(path)
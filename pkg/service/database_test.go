@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+func Test_LockDatabase(t *testing.T) {
+
+	home, _ := ioutil.TempDir("", "secret-service")
+	defer os.RemoveAll(home)
+
+	lockFile, err := service.LockDatabase(home)
+
+	if err != nil {
+		t.Fatalf("LockDatabase failed. Error: %v", err)
+	}
+
+	if _, err := service.LockDatabase(home); err != service.ErrDatabaseInUse {
+		t.Errorf("Expected '%v', got: %v", service.ErrDatabaseInUse, err)
+	}
+
+	lockFile.Close()
+
+	lockFile, err = service.LockDatabase(home)
+
+	if err != nil {
+		t.Fatalf("Expected lock to be released. Error: %v", err)
+	}
+
+	lockFile.Close()
+
+	// running daemon of tests holds its database
+	if _, err := service.LockDatabase(Service.Config.Home); err != service.ErrDatabaseInUse {
+		t.Errorf("Expected daemon to hold database lock, got: %v", err)
+	}
+}
+
+func Test_DatabaseSecrets(t *testing.T) {
+
+	const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+	home, _ := ioutil.TempDir("", "secret-service")
+	defer os.RemoveAll(home)
+	dbFile := filepath.Join(home, "db.json")
+
+	db := &service.Database{
+		Version: "0.1.0",
+		Collections: []service.DbCollection{{
+			ObjectPath: "/org/freedesktop/secrets/aliases/default",
+			Alias:      "default",
+			Items: []service.DbItem{
+				{ObjectPath: "/org/freedesktop/secrets/aliases/default/a",
+					Secret: service.DbSecret{SecretText: "Victoria"}},
+				{ObjectPath: "/org/freedesktop/secrets/aliases/default/b",
+					Secret: service.DbSecret{SecretText: ""}},
+			},
+		}},
+	}
+
+	if err := db.DecryptSecrets(masterPassword); err == nil {
+		t.Error("Expected decrypting a non-encrypted database to fail")
+	}
+
+	if err := db.EncryptSecrets("short"); err == nil {
+		t.Error("Expected a short master password to fail")
+	}
+
+	if err := db.EncryptSecrets(masterPassword); err != nil {
+		t.Fatalf("EncryptSecrets failed. Error: %v", err)
+	}
+
+	if !db.Encrypted || db.Collections[0].Items[0].Secret.SecretText == "Victoria" {
+		t.Fatal("Expected database to be encrypted")
+	}
+
+	if err := service.WriteDatabase(db, dbFile); err != nil {
+		t.Fatalf("WriteDatabase failed. Error: %v", err)
+	}
+
+	if info, _ := os.Stat(dbFile); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected database permissions 0600, got: %v", info.Mode().Perm())
+	}
+
+	db, err := service.ReadDatabase(dbFile)
+
+	if err != nil {
+		t.Fatalf("ReadDatabase failed. Error: %v", err)
+	}
+
+	if err := db.DecryptSecrets("0123456789abcdefghijklmnopqrstuv"); err == nil {
+		t.Error("Expected a wrong master password to fail")
+	}
+
+	if !db.Encrypted {
+		t.Error("Expected database to stay encrypted after a failure")
+	}
+
+	if err := db.DecryptSecrets(masterPassword); err != nil {
+		t.Fatalf("DecryptSecrets failed. Error: %v", err)
+	}
+
+	if db.Encrypted || db.Collections[0].Items[0].Secret.SecretText != "Victoria" ||
+		db.Collections[0].Items[1].Secret.SecretText != "" {
+		t.Errorf("Unexpected decrypted database: %+v", db.Collections[0].Items)
+	}
+
+	ioutil.WriteFile(dbFile, []byte("{"), 0600)
+
+	if _, err := service.ReadDatabase(dbFile); err == nil {
+		t.Error("Expected a malformed database to fail")
+	}
+}
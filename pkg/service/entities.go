@@ -4,6 +4,7 @@ package service
 
 import (
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/prop"
@@ -11,6 +12,10 @@ import (
 
 /* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Service >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
 
+// Version of secretserviced, set at build time from 'VERSION' file:
+// -ldflags "-X github.com/yousefvand/secret-service/pkg/service.Version=..."
+var Version string = "dev"
+
 // SaveData is a function used by a child
 // to inform parent of a change in data
 type SaveData func()
@@ -20,15 +25,25 @@ type Service struct {
 	// dbus session connection
 	Connection *dbus.Conn
 
-	// TODO: Remove
 	// Mutex for lock/unlock service
-	// LockMutex *sync.Mutex
-	// true if service is locked otherwise false
-	// Locked bool
+	LockMutex *sync.RWMutex
+	// true if service is locked (secrets are sealed) otherwise false
+	Locked bool
+	// collections locked by service lock, to be unlocked by service unlock
+	serviceLockedCollections []dbus.ObjectPath
 
+	// Mutex for lock/unlock Config settings which change by reloading configurations
+	ConfigMutex *sync.RWMutex
+	// service configurations
 	Config *ServiceConfig
 	// SecretService session
 	SecretService *SecretService
+	// Flatpak secret portal backend
+	Portal *Portal
+	// gnome-keyring private interface (collection master passwords)
+	GnomeKeyring *GnomeKeyring
+	// KWallet compatibility layer
+	KWallet *KWallet
 	// Mutex for lock/unlock Sessions map
 	SessionsMutex *sync.RWMutex
 	// Cli Session
@@ -49,6 +64,18 @@ type Service struct {
 	ServiceShutdownChan chan struct{}
 	// inform database has loaded
 	DbLoadedChan chan struct{}
+	// time service started
+	Started time.Time
+	// Mutex for lock/unlock database save status
+	SaveMutex *sync.RWMutex
+	// time of last successful database save
+	LastSave time.Time
+	// error of last database save, empty if it succeeded
+	LastSaveError string
+	// reloads configurations from config file (set by app), nil if not supported
+	Reload func() error
+	// 1 while a backup is being restored (database is not saved), otherwise 0
+	restoring int32
 }
 
 type ServiceConfig struct {
@@ -58,6 +85,38 @@ type ServiceConfig struct {
 	EncryptDatabase bool
 	// allow database to be exported without encryption
 	AllowDbExport bool
+	// implement 'org.freedesktop.impl.portal.Secret' (Flatpak secret portal)
+	Portal bool
+	// implement 'org.kde.KWallet' on 'org.kde.kwalletd5' and 'org.kde.kwalletd6'
+	KWallet bool
+	// Mutex for lock/unlock Schemas map
+	SchemasMutex *sync.RWMutex
+	// registered schemas. key: schema name (xdg:schema), value: Schema object
+	Schemas map[string]*Schema
+	// directory of backups
+	BackupDirectory string
+	// interval of scheduled backups, zero disables them
+	BackupInterval time.Duration
+	// backups kept when old backups are pruned
+	BackupRetention BackupRetention
+}
+
+// BackupRetention tells which backups are kept when backups are pruned
+type BackupRetention struct {
+	// number of newest backups to keep
+	KeepLast int
+	// number of days to keep the newest backup of
+	KeepDaily int
+	// number of weeks to keep the newest backup of
+	KeepWeekly int
+}
+
+// Schema data structure (libsecret 'xdg:schema')
+type Schema struct {
+	// schema name i.e. 'org.gnome.keyring.NetworkPassword'
+	Name string
+	// lookup attributes every item of this schema must have
+	Required []string
 }
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Service <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -85,8 +144,108 @@ type PasswordFile struct {
 	PasswordHash string `yaml:"passwordHash"`
 }
 
+// daemon status and statistics returned by 'Status' method
+type Status struct {
+	// secretserviced version
+	Version string
+	// service start time (epoch)
+	Started uint64
+	// seconds since service start
+	Uptime uint64
+	// database encryption mode: 'AES-CBC-256' or 'none'
+	Encryption string
+	// database can be exported without encryption
+	AllowDbExport bool
+	// Flatpak secret portal backend is enabled
+	Portal bool
+	// KWallet compatibility layer is enabled
+	KWallet bool
+	// number of registered schemas
+	Schemas uint32
+	// service is locked (ServiceLock)
+	Locked bool
+	// database file path
+	DbPath string
+	// database file size in bytes (0 if not saved yet)
+	DbSize uint64
+	// number of collections
+	Collections uint32
+	// number of items in all collections
+	Items uint32
+	// number of open sessions
+	Sessions uint32
+	// time of last successful database save (epoch), 0 if never saved
+	LastSave uint64
+	// error of last database save, empty if it succeeded
+	LastSaveError string
+	// memory obtained from OS in MB
+	Memory uint64
+	// number of goroutines
+	Goroutines uint32
+	// MASTERPASSWORD of daemon: 'valid', 'missing' or 'invalid' (not 32 characters)
+	MasterPassword string
+	// error of reading database file or decrypting it by MASTERPASSWORD, empty if it is fine
+	DbError string
+}
+
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< SecretService <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
 
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Portal >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// Flatpak secret portal backend data structure
+type Portal struct {
+	// reference to parent (service)
+	Parent *Service
+	// Mutex to serialize creation of per-application secrets
+	Mutex *sync.Mutex
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Portal <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> GnomeKeyring >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+// gnome-keyring private interface data structure
+// (org.gnome.keyring.InternalUnsupportedGuiltRiddenInterface)
+type GnomeKeyring struct {
+	// reference to parent (service)
+	Parent *Service
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< GnomeKeyring <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> KWallet >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+
+KWallet             Secret Service
+├── wallet     ->   collection
+├── folder     ->   item lookup attribute 'kwallet:folder'
+└── key        ->   item lookup attribute 'kwallet:key'
+
+*/
+
+// KWallet compatibility layer data structure (org.kde.KWallet)
+type KWallet struct {
+	// reference to parent (service)
+	Parent *Service
+	// Mutex for lock/unlock Handles map
+	Mutex *sync.Mutex
+	// open wallets. key: handle, value: wallet handle object
+	Handles map[int32]*KWalletHandle
+	// last handle given to a client
+	LastHandle int32
+}
+
+// KWalletHandle is an open wallet
+type KWalletHandle struct {
+	// wallet name i.e. 'kdewallet'
+	Wallet string
+	// application id which opened the wallet
+	AppID string
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< KWallet <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
 /* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Session >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
 
 // encryption algorithm type
@@ -162,6 +321,8 @@ type Collection struct {
 	Label string
 	// true if collection is locked otherwise false
 	Locked bool
+	// argon2id hash of collection master password (empty: not password protected)
+	PasswordHash string
 	// Unix time collection created
 	Created uint64
 	// Unix time collection modified
@@ -200,6 +361,8 @@ type Item struct {
 	LookupAttributes map[string]string
 	// label of this item
 	Label string
+	// item type (org.freedesktop.Secret.Item.Type) aka libsecret schema name
+	Type string
 	// Mutex to lock/unlock Locked status of item
 	LockMutex *sync.Mutex
 	// true if item is locked otherwise false
@@ -225,6 +388,8 @@ type Secret struct {
 	Parent *Item
 	// Unencrypted secret
 	PlainSecret string
+	// Secret sealed with master password while service is locked
+	SealedSecret string
 	// Secret type needed by API
 	SecretApi *SecretApi
 	// inform parent data has happened
@@ -0,0 +1,202 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	API implementation of:
+	org.gnome.keyring.InternalUnsupportedGuiltRiddenInterface
+*/
+
+/////////////////////////////////// Methods ///////////////////////////////////
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ChangeWithPrompt >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	ChangeWithPrompt ( IN  ObjectPath collection,
+	                   OUT ObjectPath prompt);
+*/
+
+// ChangeWithPrompt changes collection master password by prompting the user
+func (gnomeKeyring *GnomeKeyring) ChangeWithPrompt(
+	collection dbus.ObjectPath) (dbus.ObjectPath, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface":  GnomeKeyringInterface,
+		"method":     "ChangeWithPrompt",
+		"collection": collection,
+	}).Trace("Method called by client")
+
+	// Current version of this service doesn't use prompting at all
+	log.Warn("Changing master password with prompt is not supported. Use 'ChangeWithMasterPassword'")
+
+	return dbus.ObjectPath("/"), ApiErrorNotSupported()
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ChangeWithPrompt <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> CreateWithMasterPassword >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	CreateWithMasterPassword ( IN  Dict<String,Variant> properties,
+	                           IN  Secret master,
+	                           OUT ObjectPath collection);
+*/
+
+// CreateWithMasterPassword creates a new collection protected by given master password
+func (gnomeKeyring *GnomeKeyring) CreateWithMasterPassword(properties map[string]dbus.Variant,
+	master SecretApi) (dbus.ObjectPath, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface":  GnomeKeyringInterface,
+		"method":     "CreateWithMasterPassword",
+		"properties": properties,
+	}).Trace("Method called by client")
+
+	password, err := gnomeKeyring.MasterPassword(master)
+
+	if err != nil {
+		log.Warnf("CreateWithMasterPassword failed. Error: %v", err)
+		return dbus.ObjectPath("/"), ApiErrorNoSession()
+	}
+
+	service := gnomeKeyring.Parent
+
+	collectionPath, _, dbusErr := service.CreateCollection(properties, "")
+
+	if dbusErr != nil {
+		return dbus.ObjectPath("/"), dbusErr
+	}
+
+	collection := service.GetCollectionByPath(collectionPath)
+
+	if err := collection.SetPassword(password); err != nil {
+		log.Errorf("Cannot set master password of collection '%v'. Error: %v", collectionPath, err)
+		return dbus.ObjectPath("/"), DbusErrorCallFailed("Cannot set master password. Error: " + err.Error())
+	}
+
+	service.SaveData()
+	log.Infof("New password protected collection at: %v", collectionPath)
+
+	return collectionPath, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< CreateWithMasterPassword <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ChangeWithMasterPassword >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	ChangeWithMasterPassword ( IN  ObjectPath collection,
+	                           IN  Secret original,
+	                           IN  Secret master);
+*/
+
+// ChangeWithMasterPassword changes collection master password. Empty master removes protection
+func (gnomeKeyring *GnomeKeyring) ChangeWithMasterPassword(collectionPath dbus.ObjectPath,
+	original SecretApi, master SecretApi) *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface":  GnomeKeyringInterface,
+		"method":     "ChangeWithMasterPassword",
+		"collection": collectionPath,
+	}).Trace("Method called by client")
+
+	service := gnomeKeyring.Parent
+	collection := service.GetCollectionByPath(collectionPath)
+
+	if collection == nil {
+		return ApiErrorNoSuchObject()
+	}
+
+	originalPassword, err := gnomeKeyring.MasterPassword(original)
+
+	if err != nil {
+		log.Warnf("ChangeWithMasterPassword failed. Error: %v", err)
+		return ApiErrorNoSession()
+	}
+
+	password, err := gnomeKeyring.MasterPassword(master)
+
+	if err != nil {
+		log.Warnf("ChangeWithMasterPassword failed. Error: %v", err)
+		return ApiErrorNoSession()
+	}
+
+	if !collection.VerifyPassword(originalPassword) {
+		log.Warnf("Wrong original master password for collection: %v", collectionPath)
+		return DbusErrorAccessDenied("The original password was invalid")
+	}
+
+	if err := collection.SetPassword(password); err != nil {
+		log.Errorf("Cannot set master password of collection '%v'. Error: %v", collectionPath, err)
+		return DbusErrorCallFailed("Cannot set master password. Error: " + err.Error())
+	}
+
+	collection.UpdateModified()
+	collection.SignalCollectionChanged()
+	service.SaveData()
+	log.Infof("Master password changed for collection: %v", collectionPath)
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ChangeWithMasterPassword <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> UnlockWithMasterPassword >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	UnlockWithMasterPassword ( IN  ObjectPath collection,
+	                           IN  Secret master);
+*/
+
+// UnlockWithMasterPassword unlocks a collection using its master password
+func (gnomeKeyring *GnomeKeyring) UnlockWithMasterPassword(collectionPath dbus.ObjectPath,
+	master SecretApi) *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface":  GnomeKeyringInterface,
+		"method":     "UnlockWithMasterPassword",
+		"collection": collectionPath,
+	}).Trace("Method called by client")
+
+	service := gnomeKeyring.Parent
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return ApiErrorIsLocked()
+	}
+
+	collection := service.GetCollectionByPath(collectionPath)
+
+	if collection == nil {
+		return ApiErrorNoSuchObject()
+	}
+
+	password, err := gnomeKeyring.MasterPassword(master)
+
+	if err != nil {
+		log.Warnf("UnlockWithMasterPassword failed. Error: %v", err)
+		return ApiErrorNoSession()
+	}
+
+	if !collection.VerifyPassword(password) {
+		log.Warnf("Wrong master password for collection: %v", collectionPath)
+		return DbusErrorAccessDenied("The password was invalid")
+	}
+
+	if collection.Locked {
+		collection.Unlock()
+		collection.UpdateModified()
+		collection.SignalCollectionChanged()
+		service.SaveData()
+		log.Infof("Collection unlocked with master password: %v", collectionPath)
+	}
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< UnlockWithMasterPassword <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -0,0 +1,159 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+// masterSecret encrypts a master password over given session
+func masterSecret(t *testing.T, session *client.Session, password string) client.SecretApi {
+
+	iv, cipherData, err := crypto.AesCBCEncrypt([]byte(password), session.SymmetricKey)
+
+	if err != nil {
+		t.Fatalf("encryption error: %v", err)
+	}
+
+	secretApi := client.NewSecretApi()
+	secretApi.ContentType = "text/plain"
+	secretApi.Session = session.ObjectPath
+	secretApi.Parameters = iv
+	secretApi.Value = cipherData
+
+	return *secretApi
+}
+
+func TestGnomeKeyring_MasterPassword(t *testing.T) {
+
+	ssClient, _ := client.New()
+	session, err := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+
+	if err != nil {
+		t.Fatalf("OpenSession failed. Error: %v", err)
+	}
+
+	obj := ssClient.Connection.Object("org.freedesktop.secrets", "/org/freedesktop/secrets")
+
+	var collectionPath dbus.ObjectPath
+
+	t.Run("CreateWithMasterPassword", func(t *testing.T) {
+
+		properties := map[string]dbus.Variant{
+			"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant("guilt ridden"),
+		}
+
+		call := obj.Call(service.GnomeKeyringInterface+".CreateWithMasterPassword", 0,
+			properties, masterSecret(t, session, "Victoria"))
+
+		if call.Err != nil {
+			t.Fatalf("CreateWithMasterPassword failed. Error: %v", call.Err)
+		}
+
+		if err := call.Store(&collectionPath); err != nil {
+			t.Fatal(err)
+		}
+
+		collection := Service.GetCollectionByPath(collectionPath)
+
+		if collection == nil {
+			t.Fatalf("No such collection at service side: %v", collectionPath)
+		}
+
+		if !collection.IsProtected() {
+			t.Error("Expected collection to be password protected")
+		}
+	})
+
+	t.Run("Unlock refuses protected collection", func(t *testing.T) {
+
+		collection := Service.GetCollectionByPath(collectionPath)
+		secretApi := service.SecretApi(masterSecret(t, session, "P@ssw0rd"))
+		itemPath, _, err := collection.CreateItem(map[string]dbus.Variant{}, secretApi, false)
+
+		if err != nil {
+			t.Fatalf("CreateItem failed. Error: %v", err)
+		}
+
+		collection.Lock()
+
+		unlocked, _, _ := Service.Unlock([]dbus.ObjectPath{collectionPath})
+
+		if len(unlocked) != 0 || !collection.Locked {
+			t.Error("Expected password protected collection to stay locked")
+		}
+
+		// writes are refused like reads
+		if _, _, err := collection.CreateItem(map[string]dbus.Variant{}, secretApi, false); err == nil {
+			t.Error("Expected CreateItem in locked protected collection to fail")
+		}
+
+		if err := collection.GetItemByPath(itemPath).SetSecret(secretApi); err == nil {
+			t.Error("Expected SetSecret of item in locked protected collection to fail")
+		}
+	})
+
+	t.Run("UnlockWithMasterPassword", func(t *testing.T) {
+
+		call := obj.Call(service.GnomeKeyringInterface+".UnlockWithMasterPassword", 0,
+			collectionPath, masterSecret(t, session, "wrong"))
+
+		if call.Err == nil {
+			t.Error("Expected wrong master password to be rejected")
+		}
+
+		collection := Service.GetCollectionByPath(collectionPath)
+
+		if !collection.Locked {
+			t.Error("Expected collection to stay locked with wrong master password")
+		}
+
+		call = obj.Call(service.GnomeKeyringInterface+".UnlockWithMasterPassword", 0,
+			collectionPath, masterSecret(t, session, "Victoria"))
+
+		if call.Err != nil {
+			t.Errorf("UnlockWithMasterPassword failed. Error: %v", call.Err)
+		}
+
+		if collection.Locked {
+			t.Error("Expected collection to be unlocked")
+		}
+	})
+
+	t.Run("ChangeWithMasterPassword", func(t *testing.T) {
+
+		call := obj.Call(service.GnomeKeyringInterface+".ChangeWithMasterPassword", 0,
+			collectionPath, masterSecret(t, session, "wrong"), masterSecret(t, session, "Victoria2"))
+
+		if call.Err == nil {
+			t.Error("Expected wrong original password to be rejected")
+		}
+
+		call = obj.Call(service.GnomeKeyringInterface+".ChangeWithMasterPassword", 0,
+			collectionPath, masterSecret(t, session, "Victoria"), masterSecret(t, session, "Victoria2"))
+
+		if call.Err != nil {
+			t.Errorf("ChangeWithMasterPassword failed. Error: %v", call.Err)
+		}
+
+		collection := Service.GetCollectionByPath(collectionPath)
+
+		if collection.VerifyPassword("Victoria") || !collection.VerifyPassword("Victoria2") {
+			t.Error("Expected master password to be changed")
+		}
+	})
+
+	t.Run("ChangeWithPrompt", func(t *testing.T) {
+
+		call := obj.Call(service.GnomeKeyringInterface+".ChangeWithPrompt", 0, collectionPath)
+
+		if call.Err == nil {
+			t.Error("Expected ChangeWithPrompt to be not supported")
+		}
+	})
+}
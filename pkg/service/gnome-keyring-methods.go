@@ -0,0 +1,43 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"fmt"
+)
+
+/*
+	gnome-keyring exposes a private interface on '/org/freedesktop/secrets'
+	which tools like Seahorse use to manage collection (keyring) master
+	passwords without prompting. Master passwords are transferred as
+	'Secret' structs over an open session and only their argon2id hash
+	is kept (and persisted) by the service.
+*/
+
+// GnomeKeyringInterface is the gnome-keyring private interface name
+const GnomeKeyringInterface string = "org.gnome.keyring.InternalUnsupportedGuiltRiddenInterface"
+
+// NewGnomeKeyring creates and initialize gnome-keyring private interface
+func NewGnomeKeyring(parent *Service) *GnomeKeyring {
+	gnomeKeyring := &GnomeKeyring{}
+	gnomeKeyring.Parent = parent
+	return gnomeKeyring
+}
+
+// MasterPassword returns the plain master password transferred in secretApi
+func (gnomeKeyring *GnomeKeyring) MasterPassword(secretApi SecretApi) (string, error) {
+
+	session := gnomeKeyring.Parent.GetSessionByPath(secretApi.Session)
+
+	if session == nil {
+		return "", fmt.Errorf("secret session is missing: %v", secretApi.Session)
+	}
+
+	password, err := session.Decrypt(&secretApi)
+
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt master password. Error: %v", err)
+	}
+
+	return string(password), nil
+}
@@ -25,7 +25,10 @@ func (item *Item) Delete() (dbus.ObjectPath, *dbus.Error) {
 		"item path": item.ObjectPath,
 	}).Trace("Method called by client")
 
-	item.Parent.RemoveItem(item)
+	if err := item.Parent.RemoveItem(item); err != nil {
+		log.Warn("A backup is being restored")
+		return dbus.ObjectPath("/"), dbusErrorRestoring()
+	}
 
 	item.SignalItemDeleted()
 	item.Parent.UpdatePropertyCollectionItems()
@@ -56,6 +59,16 @@ func (item *Item) GetSecret(session dbus.ObjectPath) (*SecretApi, *dbus.Error) {
 
 	secretApi := &SecretApi{}
 	service := item.Parent.Parent
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return nil, ApiErrorIsLocked()
+	}
+
+	if item.Parent.Locked && item.Parent.IsProtected() {
+		log.Warnf("Collection is locked with master password: %v", item.Parent.ObjectPath)
+		return nil, ApiErrorIsLocked()
+	}
 	sessionInUse := service.GetSessionByPath(session)
 
 	if sessionInUse == nil {
@@ -64,9 +77,9 @@ func (item *Item) GetSecret(session dbus.ObjectPath) (*SecretApi, *dbus.Error) {
 	}
 
 	secretApi.Session = session
+	secretApi.ContentType = item.Secret.SecretApi.ContentType
 	if sessionInUse.EncryptionAlgorithm == Plain {
 		secretApi.Value = []byte(item.Secret.PlainSecret)
-		secretApi.ContentType = "text/plain; charset=utf8"
 		secretApi.Parameters = []byte("")
 	} else { // dh-ietf1024-sha256-aes128-cbc-pkcs7
 		iv, cipherData, err := crypto.AesCBCEncrypt([]byte(item.Secret.PlainSecret),
@@ -101,10 +114,23 @@ func (item *Item) SetSecret(secretApi SecretApi) *dbus.Error {
 		"secretApi": secretApi,
 	}).Trace("Method called by client")
 
+	service := item.Parent.Parent
+
+	if item.Parent.Locked && item.Parent.IsProtected() {
+		log.Warnf("Collection is locked with master password: %v", item.Parent.ObjectPath)
+		return ApiErrorIsLocked()
+	}
+
+	if !service.rLockUnlocked() {
+		log.Warn("Service is locked")
+		return ApiErrorIsLocked()
+	}
+
 	secret := NewSecret(item)
 	session := item.Parent.Parent.GetSessionByPath(secretApi.Session)
 
 	if session == nil {
+		service.LockMutex.RUnlock()
 		log.Warn("Secret session is missing")
 		return ApiErrorNoSession()
 	}
@@ -117,6 +143,7 @@ func (item *Item) SetSecret(secretApi SecretApi) *dbus.Error {
 		iv := secret.SecretApi.Parameters
 		plainSecret, err := crypto.AesCBCDecrypt(iv, secret.SecretApi.Value, session.SymmetricKey)
 		if err != nil {
+			service.LockMutex.RUnlock()
 			log.Errorf("Cannot SetSecret due to decryption error. Error: %v", err)
 			return DbusErrorCallFailed("Cannot SetSecret due to decryption error. Error: " + err.Error())
 		}
@@ -126,6 +153,7 @@ func (item *Item) SetSecret(secretApi SecretApi) *dbus.Error {
 	item.DataMutex.Lock()
 	item.Secret = secret
 	item.DataMutex.Unlock()
+	service.LockMutex.RUnlock()
 	item.SignalItemChanged()
 	item.Parent.UpdateModified()
 	log.Tracef("SetSecret received: %s", item.Secret.PlainSecret)
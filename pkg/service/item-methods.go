@@ -3,6 +3,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -98,7 +99,7 @@ func (item *Item) GetProperty(name string) (dbus.Variant, error) {
 	variant, err := busObject.GetProperty("org.freedesktop.Secret.Item." + name)
 
 	if err != nil {
-		return dbus.MakeVariant(nil), fmt.Errorf("error getting property '%s'. Error: %v", name, err)
+		return dbus.Variant{}, fmt.Errorf("error getting property '%s'. Error: %v", name, err)
 	}
 
 	item.Properties[name] = variant
@@ -167,9 +168,42 @@ func (item *Item) SetProperties(properties map[string]dbus.Variant) {
 		processedProperties["Label"] = dbus.MakeVariant("")
 	}
 
+	// Type defaults to libsecret schema name (xdg:schema) if not provided
+	if itemType, ok := processedProperties["Type"]; ok {
+		if itemType, ok := itemType.Value().(string); ok {
+			processedProperties["Type"] = dbus.MakeVariant(strings.TrimSpace(itemType))
+		} else { // Type is not string!
+			processedProperties["Type"] = dbus.MakeVariant("")
+		}
+	} else {
+		processedProperties["Type"] = dbus.MakeVariant(item.GetLookupAttribute(SchemaAttribute))
+	}
+
 	item.Properties = processedProperties
 	item.Label = item.Properties["Label"].Value().(string)
+	item.Type = item.Properties["Type"].Value().(string)
+
+}
+
+// SchemaName returns item type if it is set otherwise 'xdg:schema' lookup attribute
+func (item *Item) SchemaName() string {
+
+	item.DataMutex.RLock()
+	itemType := item.Type
+	item.DataMutex.RUnlock()
+
+	if itemType != "" {
+		return itemType
+	}
+
+	return item.GetLookupAttribute(SchemaAttribute)
+}
 
+// MatchSchema returns true if item's type or its 'xdg:schema' attribute
+// (like libsecret) is given schema. Empty schema matches all items.
+func (item *Item) MatchSchema(schema string) bool {
+	return schema == "" || item.SchemaName() == schema ||
+		item.GetLookupAttribute(SchemaAttribute) == schema
 }
 
 // UpdateModified updated 'Modified' dbus property of this collection
@@ -198,3 +232,29 @@ func (item *Item) Unlock() {
 	item.Locked = false
 	item.SetProperty("Locked", false)
 }
+
+// SetPlainSecret replaces item's secret with given plain secret
+func (item *Item) SetPlainSecret(plainSecret string, contentType string) error {
+
+	service := item.Parent.Parent
+
+	if !service.rLockUnlocked() {
+		return errors.New("service is locked")
+	}
+
+	secret := NewSecret(item)
+	secret.PlainSecret = plainSecret
+	secret.SecretApi.ContentType = contentType
+
+	item.DataMutex.Lock()
+	item.Secret = secret
+	item.DataMutex.Unlock()
+	service.LockMutex.RUnlock()
+
+	item.UpdateModified()
+	item.SignalItemChanged()
+	item.Parent.UpdateModified()
+	item.SaveData()
+
+	return nil
+}
@@ -27,6 +27,7 @@ func TestItem_SetProperties(t *testing.T) {
 			out: map[string]dbus.Variant{
 				"Correct": dbus.MakeVariant("Yes"),
 				"Label":   dbus.MakeVariant(""),
+				"Type":    dbus.MakeVariant(""),
 			},
 			attributes: map[string]string{},
 		},
@@ -45,6 +46,7 @@ func TestItem_SetProperties(t *testing.T) {
 			out: map[string]dbus.Variant{
 				"Correct": dbus.MakeVariant("Yes"),
 				"Label":   dbus.MakeVariant(""),
+				"Type":    dbus.MakeVariant(""),
 			},
 			attributes: map[string]string{"a": "b"},
 		},
@@ -60,6 +62,7 @@ func TestItem_SetProperties(t *testing.T) {
 			},
 			out: map[string]dbus.Variant{
 				"Label": dbus.MakeVariant(""),
+				"Type":  dbus.MakeVariant(""),
 			},
 			attributes: map[string]string{
 				"a": "b",
@@ -78,6 +81,7 @@ func TestItem_SetProperties(t *testing.T) {
 			},
 			out: map[string]dbus.Variant{
 				"Label": dbus.MakeVariant("MyItem"),
+				"Type":  dbus.MakeVariant(""),
 			},
 			attributes: map[string]string{
 				"a": "b",
@@ -85,6 +89,39 @@ func TestItem_SetProperties(t *testing.T) {
 				"e": "f",
 			},
 		},
+		{
+			name: "Type from xdg:schema",
+			in: map[string]dbus.Variant{
+				"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("MyItem"),
+				"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(map[string]string{
+					"xdg:schema": "org.gnome.keyring.Note",
+				}),
+			},
+			out: map[string]dbus.Variant{
+				"Label": dbus.MakeVariant("MyItem"),
+				"Type":  dbus.MakeVariant("org.gnome.keyring.Note"),
+			},
+			attributes: map[string]string{
+				"xdg:schema": "org.gnome.keyring.Note",
+			},
+		},
+		{
+			name: "explicit Type",
+			in: map[string]dbus.Variant{
+				"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("MyItem"),
+				"org.freedesktop.Secret.Item.Type":  dbus.MakeVariant(" org.git.Password "),
+				"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(map[string]string{
+					"xdg:schema": "org.gnome.keyring.Note",
+				}),
+			},
+			out: map[string]dbus.Variant{
+				"Label": dbus.MakeVariant("MyItem"),
+				"Type":  dbus.MakeVariant("org.git.Password"),
+			},
+			attributes: map[string]string{
+				"xdg:schema": "org.gnome.keyring.Note",
+			},
+		},
 	}
 
 	collection := service.NewCollection(Service)
@@ -0,0 +1,636 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	API implementation of:
+	org.kde.KWallet
+
+	KWallet reports failures by return values (i.e. -1 or empty) instead
+	of dbus errors, so do these methods. Method names on dbus start with
+	a lowercase letter (see 'kwalletMethods' in bus-kwallet.go).
+*/
+
+/////////////////////////////////// Methods ///////////////////////////////////
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> isEnabled >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	isEnabled ( OUT Boolean enabled);
+*/
+
+// IsEnabled returns true since wallet subsystem is always enabled
+func (kwallet *KWallet) IsEnabled() (bool, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "isEnabled",
+	}).Trace("Method called by client")
+
+	return true, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< isEnabled <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> open >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	open ( IN  String wallet,
+	       IN  Int64 wId,
+	       IN  String appid,
+	       OUT Int32 handle);
+*/
+
+// Open opens a wallet (creates it if it doesn't exist) and returns a handle, -1 on failure
+func (kwallet *KWallet) Open(wallet string, wId int64, appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "open",
+		"wallet":    wallet,
+		"wId":       wId,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	handle, err := kwallet.OpenWallet(wallet, appID)
+
+	if err != nil {
+		log.Warnf("Cannot open wallet '%s' for '%s'. Error: %v", wallet, appID, err)
+		return -1, nil
+	}
+
+	kwallet.Emit("walletOpened", wallet)
+
+	return handle, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< open <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> close >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	close ( IN  Int32 handle,
+	        IN  Boolean force,
+	        IN  String appid,
+	        OUT Int32 result);
+*/
+
+// Close closes a wallet handle. Returns 0 on success, -1 on failure
+func (kwallet *KWallet) Close(handle int32, force bool, appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "close",
+		"handle":    handle,
+		"force":     force,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	wallet, ok := kwallet.CloseHandle(handle, appID)
+
+	if !ok {
+		return -1, nil
+	}
+
+	if !kwallet.IsWalletOpen(wallet) {
+		kwallet.Emit("walletClosed", wallet)
+	}
+
+	return 0, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< close <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> isOpen >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	isOpen ( IN  String wallet,
+	         OUT Boolean open);
+*/
+
+// IsOpen returns true if wallet is opened by any application
+func (kwallet *KWallet) IsOpen(wallet string) (bool, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "isOpen",
+		"wallet":    wallet,
+	}).Trace("Method called by client")
+
+	return kwallet.IsWalletOpen(wallet), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< isOpen <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> wallets >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	wallets ( OUT Array<String> wallets);
+*/
+
+// Wallets returns all wallet names
+func (kwallet *KWallet) Wallets() ([]string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "wallets",
+	}).Trace("Method called by client")
+
+	return kwallet.WalletNames(), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< wallets <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> localWallet >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	localWallet ( OUT String wallet);
+*/
+
+// LocalWallet returns the name of default local wallet
+func (kwallet *KWallet) LocalWallet() (string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "localWallet",
+	}).Trace("Method called by client")
+
+	return KWalletDefault, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< localWallet <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> networkWallet >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	networkWallet ( OUT String wallet);
+*/
+
+// NetworkWallet returns the name of default network wallet
+func (kwallet *KWallet) NetworkWallet() (string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "networkWallet",
+	}).Trace("Method called by client")
+
+	return KWalletDefault, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< networkWallet <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> folderList >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	folderList ( IN  Int32 handle,
+	             IN  String appid,
+	             OUT Array<String> folders);
+*/
+
+// FolderList returns folders of an open wallet
+func (kwallet *KWallet) FolderList(handle int32, appID string) ([]string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "folderList",
+		"handle":    handle,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("folderList failed. Error: %v", err)
+		return []string{}, nil
+	}
+
+	return kwallet.WalletFolders(collection), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< folderList <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> hasFolder >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	hasFolder ( IN  Int32 handle,
+	            IN  String folder,
+	            IN  String appid,
+	            OUT Boolean exists);
+*/
+
+// HasFolder returns true if folder exists in an open wallet
+func (kwallet *KWallet) HasFolder(handle int32, folder string, appID string) (bool, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "hasFolder",
+		"handle":    handle,
+		"folder":    folder,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("hasFolder failed. Error: %v", err)
+		return false, nil
+	}
+
+	folders := kwallet.WalletFolders(collection)
+	index := sort.SearchStrings(folders, folder)
+
+	return index < len(folders) && folders[index] == folder, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< hasFolder <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> createFolder >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	createFolder ( IN  Int32 handle,
+	               IN  String folder,
+	               IN  String appid,
+	               OUT Boolean created);
+*/
+
+// CreateFolder creates a folder in an open wallet
+func (kwallet *KWallet) CreateFolder(handle int32, folder string, appID string) (bool, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "createFolder",
+		"handle":    handle,
+		"folder":    folder,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	wallet, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("createFolder failed. Error: %v", err)
+		return false, nil
+	}
+
+	if err := kwallet.AddFolder(collection, folder); err != nil {
+		log.Warnf("createFolder failed. Error: %v", err)
+		return false, nil
+	}
+
+	kwallet.Emit("folderListUpdated", wallet)
+
+	return true, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< createFolder <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> entryList >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	entryList ( IN  Int32 handle,
+	            IN  String folder,
+	            IN  String appid,
+	            OUT Array<String> entries);
+*/
+
+// EntryList returns keys of a folder in an open wallet
+func (kwallet *KWallet) EntryList(handle int32, folder string, appID string) ([]string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "entryList",
+		"handle":    handle,
+		"folder":    folder,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	entries := []string{}
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("entryList failed. Error: %v", err)
+		return entries, nil
+	}
+
+	for _, item := range kwallet.Entries(collection, folder) {
+		entries = append(entries, item.GetLookupAttribute(KWalletKeyAttribute))
+	}
+
+	sort.Strings(entries)
+	return entries, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< entryList <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> hasEntry >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	hasEntry ( IN  Int32 handle,
+	           IN  String folder,
+	           IN  String key,
+	           IN  String appid,
+	           OUT Boolean exists);
+*/
+
+// HasEntry returns true if key exists in a folder of an open wallet
+func (kwallet *KWallet) HasEntry(handle int32, folder string, key string,
+	appID string) (bool, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "hasEntry",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("hasEntry failed. Error: %v", err)
+		return false, nil
+	}
+
+	return kwallet.Entry(collection, folder, key) != nil, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< hasEntry <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> entryType >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	entryType ( IN  Int32 handle,
+	            IN  String folder,
+	            IN  String key,
+	            IN  String appid,
+	            OUT Int32 type);
+*/
+
+// EntryType returns type of an entry (0: unknown, 1: password, 2: stream, 3: map)
+func (kwallet *KWallet) EntryType(handle int32, folder string, key string,
+	appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "entryType",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("entryType failed. Error: %v", err)
+		return KWalletEntryUnknown, nil
+	}
+
+	item := kwallet.Entry(collection, folder, key)
+
+	if item == nil {
+		return KWalletEntryUnknown, nil
+	}
+
+	return entryType(item), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< entryType <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> readPassword >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	readPassword ( IN  Int32 handle,
+	               IN  String folder,
+	               IN  String key,
+	               IN  String appid,
+	               OUT String password);
+*/
+
+// ReadPassword returns a password entry, empty if it doesn't exist
+func (kwallet *KWallet) ReadPassword(handle int32, folder string, key string,
+	appID string) (string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "readPassword",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("readPassword failed. Error: %v", err)
+		return "", nil
+	}
+
+	item := kwallet.Entry(collection, folder, key)
+
+	if item == nil || entryType(item) != KWalletEntryPassword {
+		return "", nil
+	}
+
+	item.DataMutex.RLock()
+	defer item.DataMutex.RUnlock()
+
+	return item.Secret.PlainSecret, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< readPassword <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> writePassword >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	writePassword ( IN  Int32 handle,
+	                IN  String folder,
+	                IN  String key,
+	                IN  String value,
+	                IN  String appid,
+	                OUT Int32 result);
+*/
+
+// WritePassword creates or updates a password entry. Returns 0 on success, -1 on failure
+func (kwallet *KWallet) WritePassword(handle int32, folder string, key string,
+	value string, appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "writePassword",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	wallet, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("writePassword failed. Error: %v", err)
+		return -1, nil
+	}
+
+	if err := kwallet.WriteEntry(collection, folder, key, KWalletTypePassword, value); err != nil {
+		log.Errorf("writePassword failed. Error: %v", err)
+		return -1, nil
+	}
+
+	kwallet.Emit("folderUpdated", wallet, folder)
+
+	return 0, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< writePassword <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> readMap >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	readMap ( IN  Int32 handle,
+	          IN  String folder,
+	          IN  String key,
+	          IN  String appid,
+	          OUT Array<Byte> value);
+*/
+
+// ReadMap returns a map entry serialized as QMap<QString,QString>, empty if it doesn't exist
+func (kwallet *KWallet) ReadMap(handle int32, folder string, key string,
+	appID string) ([]byte, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "readMap",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	_, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("readMap failed. Error: %v", err)
+		return []byte{}, nil
+	}
+
+	item := kwallet.Entry(collection, folder, key)
+
+	if item == nil || entryType(item) != KWalletEntryMap {
+		return []byte{}, nil
+	}
+
+	item.DataMutex.RLock()
+	value, err := EncodeMap(item.Secret.PlainSecret)
+	item.DataMutex.RUnlock()
+
+	if err != nil {
+		log.Errorf("readMap failed. Error: %v", err)
+		return []byte{}, nil
+	}
+
+	return value, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< readMap <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> writeMap >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	writeMap ( IN  Int32 handle,
+	           IN  String folder,
+	           IN  String key,
+	           IN  Array<Byte> value,
+	           IN  String appid,
+	           OUT Int32 result);
+*/
+
+// WriteMap creates or updates a map entry (QMap<QString,QString>). Returns 0 on success, -1 on failure
+func (kwallet *KWallet) WriteMap(handle int32, folder string, key string,
+	value []byte, appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "writeMap",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	wallet, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("writeMap failed. Error: %v", err)
+		return -1, nil
+	}
+
+	entries, err := DecodeMap(value)
+
+	if err != nil {
+		log.Warnf("writeMap received malformed map. Error: %v", err)
+		return -1, nil
+	}
+
+	if err := kwallet.WriteEntry(collection, folder, key, KWalletTypeMap, entries); err != nil {
+		log.Errorf("writeMap failed. Error: %v", err)
+		return -1, nil
+	}
+
+	kwallet.Emit("folderUpdated", wallet, folder)
+
+	return 0, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< writeMap <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> removeEntry >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	removeEntry ( IN  Int32 handle,
+	              IN  String folder,
+	              IN  String key,
+	              IN  String appid,
+	              OUT Int32 result);
+*/
+
+// RemoveEntry removes an entry. Returns 0 on success, -1 on failure
+func (kwallet *KWallet) RemoveEntry(handle int32, folder string, key string,
+	appID string) (int32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": KWalletInterface,
+		"method":    "removeEntry",
+		"handle":    handle,
+		"folder":    folder,
+		"key":       key,
+		"appid":     appID,
+	}).Trace("Method called by client")
+
+	wallet, collection, err := kwallet.HandleWallet(handle, appID)
+
+	if err != nil {
+		log.Warnf("removeEntry failed. Error: %v", err)
+		return -1, nil
+	}
+
+	item := kwallet.Entry(collection, folder, key)
+
+	if item == nil {
+		return -1, nil
+	}
+
+	item.Delete()
+	kwallet.Emit("folderUpdated", wallet, folder)
+
+	return 0, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< removeEntry <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -0,0 +1,228 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+func TestKWallet(t *testing.T) {
+
+	connection, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatalf("Cannot connect to session bus. Error: %v", err)
+	}
+	defer connection.Close()
+
+	obj := connection.Object("org.kde.kwalletd5", "/modules/kwalletd5")
+	const appID = "kwallet-test"
+
+	call := func(method string, args ...interface{}) *dbus.Call {
+		call := obj.Call(service.KWalletInterface+"."+method, 0, args...)
+		if call.Err != nil {
+			t.Fatalf("'%s' failed. Error: %v", method, call.Err)
+		}
+		return call
+	}
+
+	var handle int32
+	call("open", service.KWalletDefault, int64(0), appID).Store(&handle)
+
+	if handle < 0 {
+		t.Fatalf("Cannot open wallet '%s'", service.KWalletDefault)
+	}
+
+	t.Run("password entry", func(t *testing.T) {
+
+		var result int32
+		call("writePassword", handle, "Passwords", "github", "Victoria", appID).Store(&result)
+
+		if result != 0 {
+			t.Errorf("Expected writePassword to return 0, got: %d", result)
+		}
+
+		var password string
+		call("readPassword", handle, "Passwords", "github", appID).Store(&password)
+
+		if password != "Victoria" {
+			t.Errorf("Expected password 'Victoria', got: '%s'", password)
+		}
+
+		// overwrite
+		call("writePassword", handle, "Passwords", "github", "Victoria2", appID).Store(&result)
+		call("readPassword", handle, "Passwords", "github", appID).Store(&password)
+
+		if password != "Victoria2" {
+			t.Errorf("Expected password 'Victoria2', got: '%s'", password)
+		}
+
+		var entries []string
+		call("entryList", handle, "Passwords", appID).Store(&entries)
+
+		if !reflect.DeepEqual(entries, []string{"github"}) {
+			t.Errorf("Unexpected entries: %v", entries)
+		}
+
+		item := Service.GetCollectionByAlias("default").GetItemByAttributes(map[string]string{
+			service.KWalletFolderAttribute: "Passwords",
+			service.KWalletKeyAttribute:    "github",
+		})
+
+		if item == nil || item.Secret.PlainSecret != "Victoria2" {
+			t.Error("Expected KWallet entry to be an item of default collection")
+		}
+	})
+
+	t.Run("map entry", func(t *testing.T) {
+
+		value, err := service.EncodeMap(`{"login":"joe","password":"Victoria"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result int32
+		call("writeMap", handle, "Form Data", "site", value, appID).Store(&result)
+
+		if result != 0 {
+			t.Errorf("Expected writeMap to return 0, got: %d", result)
+		}
+
+		var read []byte
+		call("readMap", handle, "Form Data", "site", appID).Store(&read)
+
+		if !bytes.Equal(read, value) {
+			t.Errorf("Expected readMap to return the same map")
+		}
+
+		var entryType int32
+		call("entryType", handle, "Form Data", "site", appID).Store(&entryType)
+
+		if entryType != service.KWalletEntryMap {
+			t.Errorf("Expected map entry type, got: %d", entryType)
+		}
+	})
+
+	t.Run("folders", func(t *testing.T) {
+
+		var created bool
+		call("createFolder", handle, "Empty", appID).Store(&created)
+
+		if !created {
+			t.Error("Expected createFolder to succeed")
+		}
+
+		var folders []string
+		call("folderList", handle, appID).Store(&folders)
+
+		if !reflect.DeepEqual(folders, []string{"Empty", "Form Data", "Passwords"}) {
+			t.Errorf("Unexpected folders: %v", folders)
+		}
+
+		// empty folders are saved with wallet collection
+		collection := Service.GetCollectionByAlias("default")
+		collection.DataMutex.RLock()
+		property := collection.Properties[service.KWalletFoldersProperty].Value()
+		collection.DataMutex.RUnlock()
+
+		if property != `["Empty"]` {
+			t.Errorf("Expected empty folder in '%s' property, got: %v", service.KWalletFoldersProperty, property)
+		}
+	})
+
+	t.Run("handle of another application", func(t *testing.T) {
+
+		var folders []string
+		call("folderList", handle, "intruder").Store(&folders)
+
+		if len(folders) != 0 {
+			t.Errorf("Expected no folders for another application, got: %v", folders)
+		}
+
+		var password string
+		call("readPassword", handle, "Passwords", "github", "intruder").Store(&password)
+
+		if password != "" {
+			t.Error("Expected handle to be unusable by another application")
+		}
+
+		var result int32
+		call("close", handle, false, "intruder").Store(&result)
+
+		if result != -1 {
+			t.Errorf("Expected close by another application to fail, got: %d", result)
+		}
+	})
+
+	t.Run("removeEntry", func(t *testing.T) {
+
+		var result int32
+		call("removeEntry", handle, "Passwords", "github", appID).Store(&result)
+
+		if result != 0 {
+			t.Errorf("Expected removeEntry to return 0, got: %d", result)
+		}
+
+		var exists bool
+		call("hasEntry", handle, "Passwords", "github", appID).Store(&exists)
+
+		if exists {
+			t.Error("Expected entry to be removed")
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+
+		var result int32
+		call("close", handle, false, appID).Store(&result)
+
+		if result != 0 {
+			t.Errorf("Expected close to return 0, got: %d", result)
+		}
+
+		var password string
+		call("readPassword", handle, "Form Data", "site", appID).Store(&password)
+
+		if password != "" {
+			t.Error("Expected closed handle to be unusable")
+		}
+	})
+}
+
+func TestKWallet_Map(t *testing.T) {
+
+	// QDataStream serialized QMap<QString,QString>{"a": "b"}
+	qmap := []byte{
+		0, 0, 0, 1, // count
+		0, 0, 0, 2, 0, 'a', // key
+		0, 0, 0, 2, 0, 'b', // value
+	}
+
+	decoded, err := service.DecodeMap(qmap)
+
+	if err != nil {
+		t.Fatalf("DecodeMap failed. Error: %v", err)
+	}
+
+	if decoded != `{"a":"b"}` {
+		t.Errorf("Unexpected decoded map: %s", decoded)
+	}
+
+	encoded, err := service.EncodeMap(decoded)
+
+	if err != nil {
+		t.Fatalf("EncodeMap failed. Error: %v", err)
+	}
+
+	if !bytes.Equal(encoded, qmap) {
+		t.Errorf("Expected: %v, got: %v", qmap, encoded)
+	}
+
+	if _, err := service.DecodeMap([]byte{0, 0, 0, 1, 0, 0, 0, 9}); err == nil {
+		t.Error("Expected error for truncated map")
+	}
+}
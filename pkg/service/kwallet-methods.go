@@ -0,0 +1,452 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	KDE applications talk to kwalletd instead of secret service. Wallets
+	are mapped to collections (default wallet 'kdewallet' is the default
+	collection and other wallets are collections with the wallet name as
+	alias), folders and keys are mapped to item lookup attributes. Folders
+	without any entry are kept in 'KWalletFolders' collection property.
+*/
+
+// KWalletInterface is the KWallet dbus interface name
+const KWalletInterface string = "org.kde.KWallet"
+
+// KWalletDefault is the name of KDE default (local and network) wallet
+const KWalletDefault string = "kdewallet"
+
+// KWalletBusNames maps KWallet dbus names to their object paths
+var KWalletBusNames = map[string]dbus.ObjectPath{
+	"org.kde.kwalletd5": "/modules/kwalletd5",
+	"org.kde.kwalletd6": "/modules/kwalletd6",
+}
+
+// KWallet item lookup attributes
+const (
+	KWalletFolderAttribute string = "kwallet:folder"
+	KWalletKeyAttribute    string = "kwallet:key"
+	KWalletTypeAttribute   string = "kwallet:type"
+)
+
+// KWalletFoldersProperty is the collection property keeping folders
+// created without any entry yet (json array of folder names)
+const KWalletFoldersProperty string = "KWalletFolders"
+
+// KWallet entry types (value of 'kwallet:type' lookup attribute)
+const (
+	KWalletTypePassword string = "password"
+	KWalletTypeMap      string = "map"
+)
+
+// KWallet entry types as reported by 'entryType'
+const (
+	KWalletEntryUnknown  int32 = 0
+	KWalletEntryPassword int32 = 1
+	KWalletEntryStream   int32 = 2
+	KWalletEntryMap      int32 = 3
+)
+
+// NewKWallet creates and initialize KWallet compatibility layer
+func NewKWallet(parent *Service) *KWallet {
+	kwallet := &KWallet{}
+	kwallet.Parent = parent
+	kwallet.Mutex = new(sync.Mutex)
+	kwallet.Handles = make(map[int32]*KWalletHandle)
+	return kwallet
+}
+
+// WalletCollection returns the collection of given wallet. If create is
+// true and collection doesn't exist, a new collection would be created.
+func (kwallet *KWallet) WalletCollection(wallet string, create bool) (*Collection, error) {
+
+	service := kwallet.Parent
+	alias := wallet
+
+	if wallet == KWalletDefault {
+		alias = "default"
+	}
+
+	if collection := service.GetCollectionByAlias(alias); collection != nil || !create {
+		return collection, nil
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant(wallet),
+	}
+
+	if _, _, err := service.CreateCollection(properties, alias); err != nil {
+		return nil, fmt.Errorf("cannot create collection for wallet '%s'. Error: %v", wallet, err)
+	}
+
+	return service.GetCollectionByAlias(alias), nil
+}
+
+// WalletNames returns wallet names, one per collection having an alias
+func (kwallet *KWallet) WalletNames() []string {
+
+	service := kwallet.Parent
+	wallets := []string{}
+
+	service.CollectionsMutex.RLock()
+	for _, collection := range service.Collections {
+		switch collection.Alias {
+		case "":
+			continue
+		case "default":
+			wallets = append(wallets, KWalletDefault)
+		default:
+			wallets = append(wallets, collection.Alias)
+		}
+	}
+	service.CollectionsMutex.RUnlock()
+
+	sort.Strings(wallets)
+	return wallets
+}
+
+// OpenWallet opens given wallet for application and returns a new handle
+func (kwallet *KWallet) OpenWallet(wallet string, appID string) (int32, error) {
+
+	if wallet == "" {
+		return -1, errors.New("empty wallet name")
+	}
+
+	collection, err := kwallet.WalletCollection(wallet, true)
+	if err != nil {
+		return -1, err
+	}
+
+	if collection.Locked {
+		return -1, fmt.Errorf("collection of wallet '%s' is locked", wallet)
+	}
+
+	kwallet.Mutex.Lock()
+	defer kwallet.Mutex.Unlock()
+
+	kwallet.LastHandle++
+	kwallet.Handles[kwallet.LastHandle] = &KWalletHandle{Wallet: wallet, AppID: appID}
+
+	return kwallet.LastHandle, nil
+}
+
+// CloseHandle closes given handle of application and returns the name of its wallet
+func (kwallet *KWallet) CloseHandle(handle int32, appID string) (string, bool) {
+
+	kwallet.Mutex.Lock()
+	defer kwallet.Mutex.Unlock()
+
+	walletHandle, ok := kwallet.Handles[handle]
+	if !ok || walletHandle.AppID != appID {
+		return "", false
+	}
+
+	delete(kwallet.Handles, handle)
+	return walletHandle.Wallet, true
+}
+
+// IsWalletOpen returns true if given wallet is opened by any application
+func (kwallet *KWallet) IsWalletOpen(wallet string) bool {
+
+	kwallet.Mutex.Lock()
+	defer kwallet.Mutex.Unlock()
+
+	for _, walletHandle := range kwallet.Handles {
+		if walletHandle.Wallet == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleWallet returns wallet name and collection of a handle opened by
+// given application
+func (kwallet *KWallet) HandleWallet(handle int32, appID string) (string, *Collection, error) {
+
+	kwallet.Mutex.Lock()
+	walletHandle, ok := kwallet.Handles[handle]
+	kwallet.Mutex.Unlock()
+
+	if !ok {
+		return "", nil, fmt.Errorf("invalid wallet handle: %d", handle)
+	}
+
+	if walletHandle.AppID != appID {
+		return "", nil, fmt.Errorf("wallet handle %d is not opened by '%s'", handle, appID)
+	}
+
+	collection, err := kwallet.WalletCollection(walletHandle.Wallet, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if collection == nil {
+		return "", nil, fmt.Errorf("wallet '%s' doesn't exist anymore", walletHandle.Wallet)
+	}
+
+	if collection.Locked {
+		return "", nil, fmt.Errorf("collection of wallet '%s' is locked", walletHandle.Wallet)
+	}
+
+	return walletHandle.Wallet, collection, nil
+}
+
+// WalletFolders returns folders of given wallet (collection)
+func (kwallet *KWallet) WalletFolders(collection *Collection) []string {
+
+	folders := make(map[string]bool)
+
+	collection.DataMutex.RLock()
+	for _, folder := range emptyFolders(collection) {
+		folders[folder] = true
+	}
+	collection.DataMutex.RUnlock()
+
+	for _, item := range kwallet.Entries(collection, "") {
+		folders[item.GetLookupAttribute(KWalletFolderAttribute)] = true
+	}
+
+	result := []string{}
+	for folder := range folders {
+		result = append(result, folder)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// AddFolder creates an (empty) folder in given wallet (collection)
+func (kwallet *KWallet) AddFolder(collection *Collection, folder string) error {
+
+	collection.DataMutex.Lock()
+
+	folders := emptyFolders(collection)
+
+	for _, emptyFolder := range folders {
+		if emptyFolder == folder {
+			collection.DataMutex.Unlock()
+			return nil
+		}
+	}
+
+	value, err := json.Marshal(append(folders, folder))
+
+	if err != nil {
+		collection.DataMutex.Unlock()
+		return err
+	}
+
+	collection.Properties[KWalletFoldersProperty] = dbus.MakeVariant(string(value))
+	collection.DataMutex.Unlock()
+
+	collection.SaveData()
+
+	return nil
+}
+
+// emptyFolders returns folders kept in collection property, caller holds
+// collection's 'DataMutex'
+func emptyFolders(collection *Collection) []string {
+
+	var folders []string
+
+	if value, ok := collection.Properties[KWalletFoldersProperty].Value().(string); ok {
+		if err := json.Unmarshal([]byte(value), &folders); err != nil {
+			log.Warnf("Malformed '%s' property of collection '%v'. Error: %v",
+				KWalletFoldersProperty, collection.ObjectPath, err)
+		}
+	}
+
+	return folders
+}
+
+// Entries returns KWallet items of given folder. Empty folder means all folders
+func (kwallet *KWallet) Entries(collection *Collection, folder string) []*Item {
+
+	var items []*Item
+
+	collection.ItemsMutex.RLock()
+	defer collection.ItemsMutex.RUnlock()
+
+	for _, item := range collection.Items {
+		item.LookupAttributesMutex.RLock()
+		itemFolder, isEntry := item.LookupAttributes[KWalletFolderAttribute]
+		item.LookupAttributesMutex.RUnlock()
+
+		if isEntry && (folder == "" || itemFolder == folder) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Entry returns the item of given folder and key, otherwise nil
+func (kwallet *KWallet) Entry(collection *Collection, folder string, key string) *Item {
+	return collection.GetItemByAttributes(map[string]string{
+		KWalletFolderAttribute: folder,
+		KWalletKeyAttribute:    key,
+	})
+}
+
+// WriteEntry creates or updates the entry of given folder and key
+func (kwallet *KWallet) WriteEntry(collection *Collection, folder string,
+	key string, entryType string, value string) error {
+
+	attributes := map[string]string{
+		KWalletFolderAttribute: folder,
+		KWalletKeyAttribute:    key,
+		KWalletTypeAttribute:   entryType,
+	}
+
+	if item := kwallet.Entry(collection, folder, key); item != nil {
+		if item.GetLookupAttribute(KWalletTypeAttribute) != entryType {
+			item.LookupAttributesMutex.Lock()
+			item.LookupAttributes = attributes
+			item.LookupAttributesMutex.Unlock()
+			item.DbusProperties.SetMust("org.freedesktop.Secret.Item", "Attributes", attributes)
+		}
+		return item.SetPlainSecret(value, "text/plain")
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(folder + "/" + key),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(attributes),
+	}
+
+	item, err := collection.CreatePlainItem(properties, value, "text/plain")
+	if err != nil {
+		return err
+	}
+
+	log.Infof("New KWallet entry '%s/%s' at: %v", folder, key, item.ObjectPath)
+	return nil
+}
+
+// entryType returns KWallet entry type of given item
+func entryType(item *Item) int32 {
+	switch item.GetLookupAttribute(KWalletTypeAttribute) {
+	case KWalletTypePassword, "":
+		return KWalletEntryPassword
+	case KWalletTypeMap:
+		return KWalletEntryMap
+	default:
+		return KWalletEntryUnknown
+	}
+}
+
+// EncodeMap encodes a map entry (stored as json) to QDataStream
+// serialized QMap<QString,QString> which is KWallet map format
+func EncodeMap(value string) ([]byte, error) {
+
+	entries := make(map[string]string)
+
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &entries); err != nil {
+			return nil, fmt.Errorf("malformed map entry. Error: %v", err)
+		}
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	// Qt writes QMap in reverse key order
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.BigEndian, uint32(len(keys)))
+	for _, k := range keys {
+		writeQString(buffer, k)
+		writeQString(buffer, entries[k])
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// DecodeMap decodes a QDataStream serialized QMap<QString,QString>
+// to json which is the format map entries are stored in
+func DecodeMap(data []byte) (string, error) {
+
+	entries := make(map[string]string)
+	reader := bytes.NewReader(data)
+
+	var count uint32
+	if err := binary.Read(reader, binary.BigEndian, &count); err != nil {
+		return "", fmt.Errorf("cannot read map size. Error: %v", err)
+	}
+
+	for i := uint32(0); i < count; i++ {
+		k, err := readQString(reader)
+		if err != nil {
+			return "", err
+		}
+		v, err := readQString(reader)
+		if err != nil {
+			return "", err
+		}
+		entries[k] = v
+	}
+
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// writeQString writes a string as QDataStream QString (byte length + UTF-16BE)
+func writeQString(buffer *bytes.Buffer, s string) {
+	encoded := utf16.Encode([]rune(s))
+	binary.Write(buffer, binary.BigEndian, uint32(len(encoded)*2))
+	binary.Write(buffer, binary.BigEndian, encoded)
+}
+
+// readQString reads a QDataStream QString (byte length + UTF-16BE)
+func readQString(reader *bytes.Reader) (string, error) {
+
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return "", fmt.Errorf("cannot read string length. Error: %v", err)
+	}
+
+	if length == 0xFFFFFFFF { // null QString
+		return "", nil
+	}
+
+	if length%2 != 0 || int(length) > reader.Len() {
+		return "", fmt.Errorf("invalid string length: %d", length)
+	}
+
+	encoded := make([]uint16, length/2)
+	if err := binary.Read(reader, binary.BigEndian, encoded); err != nil {
+		return "", fmt.Errorf("cannot read string. Error: %v", err)
+	}
+
+	return string(utf16.Decode(encoded)), nil
+}
+
+// Emit emits a KWallet signal on all KWallet object paths
+func (kwallet *KWallet) Emit(signal string, args ...interface{}) {
+
+	for _, path := range KWalletBusNames {
+		kwallet.Parent.Connection.Emit(path, KWalletInterface+"."+signal, args...)
+	}
+
+	log.Infof("Emitted KWallet '%s' signal: %v", signal, args)
+}
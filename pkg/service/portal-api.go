@@ -0,0 +1,67 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"os"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Portal response codes:
+	0: Success, the request is carried out
+	1: The user cancelled the interaction
+	2: The user interaction was ended in some other way
+*/
+
+/////////////////////////////////// Methods ///////////////////////////////////
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> RetrieveSecret >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	RetrieveSecret ( IN  ObjectPath handle,
+	                 IN  String app_id,
+	                 IN  UnixFD fd,
+	                 IN  Dict<String,Variant> options,
+	                 OUT UInt32 response,
+	                 OUT Dict<String,Variant> results);
+*/
+
+// RetrieveSecret writes the secret of given application to the given file descriptor
+func (portal *Portal) RetrieveSecret(handle dbus.ObjectPath, appID string,
+	fd dbus.UnixFD, options map[string]dbus.Variant) (uint32, map[string]dbus.Variant, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": PortalInterface,
+		"method":    "RetrieveSecret",
+		"handle":    handle,
+		"app_id":    appID,
+		"options":   options,
+	}).Trace("Method called by client")
+
+	results := map[string]dbus.Variant{}
+
+	file := os.NewFile(uintptr(fd), "portal-"+appID)
+	if file == nil {
+		log.Errorf("Invalid file descriptor for application '%s'", appID)
+		return 2, results, nil
+	}
+	defer file.Close()
+
+	secret, err := portal.Secret(appID)
+	if err != nil {
+		log.Errorf("Cannot retrieve portal secret for application '%s'. Error: %v", appID, err)
+		return 2, results, nil
+	}
+
+	if _, err := file.Write(secret); err != nil {
+		log.Errorf("Cannot write portal secret for application '%s'. Error: %v", appID, err)
+		return 2, results, nil
+	}
+
+	return 0, results, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< RetrieveSecret <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -0,0 +1,115 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+// retrieveSecret calls portal 'RetrieveSecret' on a private bus connection
+// and returns response code and whatever has been written to the pipe
+func retrieveSecret(t *testing.T, connection *dbus.Conn, appID string) (uint32, []byte) {
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Cannot create pipe. Error: %v", err)
+	}
+	defer reader.Close()
+
+	var response uint32
+	var results map[string]dbus.Variant
+
+	obj := connection.Object("org.freedesktop.secrets", service.PortalPath)
+	call := obj.Call(service.PortalInterface+".RetrieveSecret", 0,
+		dbus.ObjectPath("/org/freedesktop/portal/desktop/request/1_1/test"),
+		appID, dbus.UnixFD(writer.Fd()), map[string]dbus.Variant{})
+	writer.Close() // service has its own copy of the file descriptor
+
+	if call.Err != nil {
+		t.Fatalf("RetrieveSecret failed. Error: %v", call.Err)
+	}
+
+	if err := call.Store(&response, &results); err != nil {
+		t.Fatalf("Cannot read RetrieveSecret result. Error: %v", err)
+	}
+
+	secret, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Cannot read secret from pipe. Error: %v", err)
+	}
+
+	return response, secret
+}
+
+func TestPortal_RetrieveSecret(t *testing.T) {
+
+	connection, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatalf("Cannot connect to session bus. Error: %v", err)
+	}
+	defer connection.Close()
+
+	if !connection.SupportsUnixFDs() {
+		t.Skip("Session bus doesn't support passing unix file descriptors")
+	}
+
+	t.Run("stable per-application secret", func(t *testing.T) {
+
+		response, secret1 := retrieveSecret(t, connection, "org.example.First")
+
+		if response != 0 {
+			t.Errorf("Expected response 0, got: %d", response)
+		}
+
+		if len(secret1) != service.PortalSecretSize {
+			t.Errorf("Expected %d bytes secret, got: %d", service.PortalSecretSize, len(secret1))
+		}
+
+		_, secret2 := retrieveSecret(t, connection, "org.example.First")
+
+		if !bytes.Equal(secret1, secret2) {
+			t.Error("Expected the same secret for the same application")
+		}
+
+		_, secret3 := retrieveSecret(t, connection, "org.example.Second")
+
+		if bytes.Equal(secret1, secret3) {
+			t.Error("Expected different secrets for different applications")
+		}
+
+		collection := Service.GetCollectionByAlias(service.PortalAlias)
+
+		if collection == nil {
+			t.Fatal("Portal collection doesn't exist")
+		}
+
+		item := collection.GetItemByAttributes(map[string]string{"app_id": "org.example.First"})
+
+		if item == nil {
+			t.Fatal("Portal item of 'org.example.First' doesn't exist")
+		}
+
+		if item.Type != service.PortalSchema {
+			t.Errorf("Expected portal item type '%s', got: '%s'", service.PortalSchema, item.Type)
+		}
+	})
+
+	t.Run("empty application id", func(t *testing.T) {
+
+		response, secret := retrieveSecret(t, connection, "")
+
+		if response != 2 {
+			t.Errorf("Expected response 2, got: %d", response)
+		}
+
+		if len(secret) != 0 {
+			t.Errorf("Expected no secret, got %d bytes", len(secret))
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Sandboxed (Flatpak) applications cannot talk to secret service directly.
+	xdg-desktop-portal asks the backend for a secret unique to each
+	application (RetrieveSecret) and applications use that secret to
+	encrypt their own keyrings. Secrets are kept in a dedicated collection
+	(alias: 'portal') with one item per application id.
+*/
+
+// PortalPath is the dbus object path xdg-desktop-portal expects backends at
+const PortalPath dbus.ObjectPath = "/org/freedesktop/portal/desktop"
+
+// PortalInterface is the Flatpak secret portal backend interface name
+const PortalInterface string = "org.freedesktop.impl.portal.Secret"
+
+// PortalAlias is the alias of collection holding per-application secrets
+const PortalAlias string = "portal"
+
+// PortalSchema is the schema (item Type) of per-application secrets
+const PortalSchema string = "org.freedesktop.impl.portal.Secret"
+
+// PortalSecretSize is the size of per-application secrets in bytes
+const PortalSecretSize int = 64
+
+// NewPortal creates and initialize a new portal backend
+func NewPortal(parent *Service) *Portal {
+	portal := &Portal{}
+	portal.Parent = parent
+	portal.Mutex = new(sync.Mutex)
+	return portal
+}
+
+// Collection returns portal collection, creates it if it doesn't exist
+func (portal *Portal) Collection() (*Collection, error) {
+
+	service := portal.Parent
+
+	if collection := service.GetCollectionByAlias(PortalAlias); collection != nil {
+		return collection, nil
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Collection.Label": dbus.MakeVariant(PortalAlias),
+	}
+
+	_, _, err := service.CreateCollection(properties, PortalAlias)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create portal collection. Error: %v", err)
+	}
+
+	return service.GetCollectionByAlias(PortalAlias), nil
+}
+
+// Secret returns the stable secret of given application id. Secret
+// is generated and persisted the first time an application asks for it.
+func (portal *Portal) Secret(appID string) ([]byte, error) {
+
+	if appID == "" {
+		return nil, errors.New("empty application id")
+	}
+
+	portal.Mutex.Lock()
+	defer portal.Mutex.Unlock()
+
+	collection, err := portal.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Locked {
+		return nil, errors.New("portal collection is locked")
+	}
+
+	attributes := map[string]string{
+		SchemaAttribute: PortalSchema,
+		"app_id":        appID,
+	}
+
+	if item := collection.GetItemByAttributes(attributes); item != nil {
+		secret, err := base64.StdEncoding.DecodeString(item.Secret.PlainSecret)
+		if err != nil {
+			return nil, fmt.Errorf("malformed secret for application '%s'. Error: %v", appID, err)
+		}
+		return secret, nil
+	}
+
+	secret := make([]byte, PortalSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("cannot generate secret. Error: %v", err)
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("Portal secret for " + appID),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(attributes),
+	}
+
+	// binary secret is stored base64 encoded to survive database (json) round trip
+	item, err := collection.CreatePlainItem(properties,
+		base64.StdEncoding.EncodeToString(secret), "text/plain")
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("New portal secret for application '%s' at: %v", appID, item.ObjectPath)
+
+	return secret, nil
+}
@@ -0,0 +1,110 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+	libsecret stores the schema name of an item in the 'xdg:schema'
+	lookup attribute and newer revisions of the API expose the same
+	value as 'org.freedesktop.Secret.Item.Type' property. Registered
+	schemas (config.yaml or schemas.d drop-in directory) declare the
+	lookup attributes items of that schema are required to have.
+*/
+
+// SchemaAttribute is the lookup attribute libsecret uses to store schema name
+const SchemaAttribute string = "xdg:schema"
+
+// NewSchema creates and initialize a new schema
+func NewSchema(name string, required []string) *Schema {
+	schema := &Schema{}
+	schema.Name = strings.TrimSpace(name)
+	schema.Required = []string{}
+	for _, attribute := range required {
+		if attribute = strings.TrimSpace(attribute); attribute != "" {
+			schema.Required = append(schema.Required, attribute)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// SetSchemas replaces registered schemas with given ones
+func (s *Service) SetSchemas(schemas []*Schema) {
+	s.Config.SchemasMutex.Lock()
+	defer s.Config.SchemasMutex.Unlock()
+
+	s.Config.Schemas = make(map[string]*Schema)
+	for _, schema := range schemas {
+		if schema == nil || schema.Name == "" {
+			continue
+		}
+		s.Config.Schemas[schema.Name] = schema
+	}
+}
+
+// GetSchema returns registered schema with given name otherwise nil
+func (s *Service) GetSchema(name string) *Schema {
+	s.Config.SchemasMutex.RLock()
+	defer s.Config.SchemasMutex.RUnlock()
+	return s.Config.Schemas[name]
+}
+
+// Validate returns an error if given lookup attributes lack any required attribute
+func (schema *Schema) Validate(attributes map[string]string) error {
+
+	var missing []string
+
+	for _, attribute := range schema.Required {
+		if _, ok := attributes[attribute]; !ok {
+			missing = append(missing, attribute)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("schema '%s' requires missing attribute(s): %s",
+			schema.Name, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// ValidateItemSchema validates item lookup attributes against its registered
+// schema. Items without schema or with an unknown schema are always valid.
+func (s *Service) ValidateItemSchema(item *Item) error {
+
+	schema := s.GetSchema(item.SchemaName())
+
+	if schema == nil {
+		return nil
+	}
+
+	item.LookupAttributesMutex.RLock()
+	defer item.LookupAttributesMutex.RUnlock()
+
+	return schema.Validate(item.LookupAttributes)
+}
+
+// SplitSchema separates 'xdg:schema' from given lookup attributes
+// and returns schema name (empty if there is none) and the rest
+func SplitSchema(attributes map[string]string) (string, map[string]string) {
+
+	schema, ok := attributes[SchemaAttribute]
+
+	if !ok {
+		return "", attributes
+	}
+
+	rest := make(map[string]string, len(attributes)-1)
+	for k, v := range attributes {
+		if k != SchemaAttribute {
+			rest[k] = v
+		}
+	}
+
+	return schema, rest
+}
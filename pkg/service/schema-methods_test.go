@@ -0,0 +1,99 @@
+// secret service implementation according to:
+// http://standards.freedesktop.org/secret-service
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yousefvand/secret-service/pkg/service"
+)
+
+func TestNewSchema(t *testing.T) {
+	schema := service.NewSchema(" org.git.Password ", []string{"user", " ", "host "})
+	if schema.Name != "org.git.Password" {
+		t.Errorf("Expected name 'org.git.Password', got: '%s'", schema.Name)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"host", "user"}) {
+		t.Errorf("Unexpected required attributes: %v", schema.Required)
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := service.NewSchema("org.git.Password", []string{"host", "user"})
+
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		valid      bool
+	}{
+		{
+			name:       "all required",
+			attributes: map[string]string{"host": "example.com", "user": "joe", "extra": "yes"},
+			valid:      true,
+		},
+		{
+			name:       "missing one",
+			attributes: map[string]string{"host": "example.com"},
+			valid:      false,
+		},
+		{
+			name:       "empty",
+			attributes: map[string]string{},
+			valid:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.attributes)
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid attributes, got: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestService_ValidateItemSchema(t *testing.T) {
+	Service.SetSchemas([]*service.Schema{
+		service.NewSchema("org.test.Schema", []string{"account"}),
+	})
+	defer Service.SetSchemas(nil)
+
+	collection := service.NewCollection(Service)
+
+	valid := service.NewItem(collection)
+	valid.Type = "org.test.Schema"
+	valid.LookupAttributes = map[string]string{"account": "joe"}
+	if err := Service.ValidateItemSchema(valid); err != nil {
+		t.Errorf("Expected valid item, got: %v", err)
+	}
+
+	invalid := service.NewItem(collection)
+	invalid.LookupAttributes = map[string]string{"xdg:schema": "org.test.Schema"}
+	if err := Service.ValidateItemSchema(invalid); err == nil {
+		t.Error("Expected validation error for missing 'account' attribute")
+	}
+
+	unknown := service.NewItem(collection)
+	unknown.Type = "org.unknown.Schema"
+	if err := Service.ValidateItemSchema(unknown); err != nil {
+		t.Errorf("Expected unknown schema to be valid, got: %v", err)
+	}
+}
+
+func TestSplitSchema(t *testing.T) {
+	schema, rest := service.SplitSchema(map[string]string{
+		"xdg:schema": "org.test.Schema",
+		"account":    "joe",
+	})
+	if schema != "org.test.Schema" {
+		t.Errorf("Expected schema 'org.test.Schema', got: '%s'", schema)
+	}
+	if !reflect.DeepEqual(rest, map[string]string{"account": "joe"}) {
+		t.Errorf("Unexpected attributes: %v", rest)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
+	"github.com/yousefvand/secret-service/pkg/otp"
 )
 
 /////////////////////////////////// Methods ///////////////////////////////////
@@ -34,14 +35,26 @@ func (service *Service) Command(command string, params string) (string, *dbus.Er
 	case "ping":
 		return "pong", nil
 	case "export database":
-		if !service.Config.AllowDbExport {
+		if !service.Settings().AllowDbExport {
 			return "verboten", nil
 		}
+		if service.IsLocked() {
+			return "locked", nil
+		}
 		dbFile := filepath.Join(service.Config.Home, time.Now().Format("2006.01.02-15:04:05")+"-"+"db.json")
-		store := service.Config.EncryptDatabase
-		service.Config.EncryptDatabase = false
-		service.Config.EncryptDatabase = store
-		Marshal(service, dbFile)
+		if err := Marshal(service, dbFile); err != nil {
+			log.Errorf("Cannot export database. Error: %v", err)
+			return "failed", nil
+		}
+		return "ok", nil
+	case "reload config":
+		if service.Reload == nil {
+			return "unknown", nil
+		}
+		if err := service.Reload(); err != nil {
+			log.Errorf("Cannot reload configurations. Error: %v", err)
+			return "failed", nil
+		}
 		return "ok", nil
 	default:
 		return "unknown", nil
@@ -50,3 +63,290 @@ func (service *Service) Command(command string, params string) (string, *dbus.Er
 }
 
 /* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Command <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> Status >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	Status ( OUT Struct status );
+*/
+
+// Status returns daemon status and statistics (uptime, version, database, counters...)
+func (service *Service) Status() (Status, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "Status",
+	}).Trace("Method called by client")
+
+	return service.GetStatus(), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< Status <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ServiceLock >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	ServiceLock ( void );
+*/
+
+// ServiceLock locks all collections and refuses secret retrieval until service is unlocked
+func (service *Service) ServiceLock() *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "ServiceLock",
+	}).Trace("Method called by client")
+
+	if err := service.LockService(); err != nil {
+		log.Warnf("Cannot lock service. Error: %v", err)
+		return DbusErrorNotSupported(err.Error())
+	}
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ServiceLock <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ServiceUnlock >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	ServiceUnlock ( IN Secret master );
+*/
+
+// ServiceUnlock unlocks the service using master password transferred over a session
+func (service *Service) ServiceUnlock(master SecretApi) *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "ServiceUnlock",
+		"session":   master.Session,
+	}).Trace("Method called by client")
+
+	session := service.GetSessionByPath(master.Session)
+
+	if session == nil {
+		log.Warn("Secret session is missing")
+		return ApiErrorNoSession()
+	}
+
+	password, err := session.Decrypt(&master)
+
+	if err != nil {
+		log.Errorf("Cannot decrypt master password. Error: %v", err)
+		return DbusErrorCallFailed("Cannot decrypt master password. Error: " + err.Error())
+	}
+
+	if err := service.UnlockService(string(password)); err != nil {
+		log.Warnf("Cannot unlock service. Error: %v", err)
+		return DbusErrorAccessDenied(err.Error())
+	}
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ServiceUnlock <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> ListAliases >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	ListAliases ( OUT Dict<String,ObjectPath> aliases );
+*/
+
+// ListAliases returns all aliases and their collections
+func (service *Service) ListAliases() (map[string]dbus.ObjectPath, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "ListAliases",
+	}).Trace("Method called by client")
+
+	aliases := make(map[string]dbus.ObjectPath)
+
+	service.CollectionsMutex.RLock()
+	for _, collection := range service.Collections {
+		collection.DataMutex.RLock()
+		if collection.Alias != "" {
+			aliases[collection.Alias] = collection.ObjectPath
+		}
+		collection.DataMutex.RUnlock()
+	}
+	service.CollectionsMutex.RUnlock()
+
+	return aliases, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< ListAliases <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> OtpCode >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	OtpCode ( IN  ObjectPath item,
+						OUT String code,
+						OUT UInt32 remaining);
+*/
+
+// OtpCode returns current TOTP code of an item whose secret is an otpauth
+// URI and seconds the code remains valid, without exposing the seed
+func (service *Service) OtpCode(itemPath dbus.ObjectPath) (string, uint32, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "OtpCode",
+		"item":      itemPath,
+	}).Trace("Method called by client")
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return "", 0, ApiErrorIsLocked()
+	}
+
+	item := service.GetItemByPath(itemPath)
+
+	if item == nil {
+		log.Warnf("Item doesn't exist: %v", itemPath)
+		return "", 0, ApiErrorNoSuchObject()
+	}
+
+	if item.Parent.Locked && item.Parent.IsProtected() {
+		log.Warnf("Collection is locked with master password: %v", item.Parent.ObjectPath)
+		return "", 0, ApiErrorIsLocked()
+	}
+
+	item.Secret.DataMutex.RLock()
+	key, err := otp.Parse(item.Secret.PlainSecret)
+	item.Secret.DataMutex.RUnlock()
+
+	if err != nil {
+		log.Warnf("Item is not an otp item: %v. Error: %v", itemPath, err)
+		return "", 0, DbusErrorInvalidArgs(err.Error())
+	}
+
+	code, remaining := key.Code(time.Now())
+
+	return code, uint32(remaining / time.Second), nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< OtpCode <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> CreateBackup >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	CreateBackup ( IN  Boolean plain,
+								 OUT String file);
+*/
+
+// CreateBackup writes a backup of all collections and items to backup
+// directory and returns backup file. Backup is encrypted by master password
+// unless plain is true, which is refused if database export is not allowed.
+func (service *Service) CreateBackup(plain bool) (string, *dbus.Error) {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "CreateBackup",
+		"plain":     plain,
+	}).Trace("Method called by client")
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return "", ApiErrorIsLocked()
+	}
+
+	backupFile, err := service.createBackup(plain)
+
+	if err != nil {
+		log.Errorf("Cannot create backup. Error: %v", err)
+		return "", DbusErrorCallFailed(err.Error())
+	}
+
+	return backupFile, nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< CreateBackup <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> RestoreBackup >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	RestoreBackup ( IN String file);
+*/
+
+// RestoreBackup validates given backup file and replaces all collections
+// and items with the ones in backup
+func (service *Service) RestoreBackup(backupFile string) *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "RestoreBackup",
+		"file":      backupFile,
+	}).Trace("Method called by client")
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return ApiErrorIsLocked()
+	}
+
+	if err := service.restoreBackup(backupFile); err != nil {
+		log.Errorf("Cannot restore backup. Error: %v", err)
+		return DbusErrorCallFailed(err.Error())
+	}
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< RestoreBackup <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
+
+/* >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> SetItemTimes >>>>>>>>>>>>>>>>>>>>>>>>>>>>>> */
+
+/*
+	SetItemTimes ( IN ObjectPath item,
+								 IN UInt64 created,
+								 IN UInt64 modified);
+*/
+
+// SetItemTimes sets creation and modification time (unix epoch) of an
+// item, importers use it to keep times of imported items
+func (service *Service) SetItemTimes(itemPath dbus.ObjectPath, created uint64, modified uint64) *dbus.Error {
+
+	log.WithFields(log.Fields{
+		"interface": "ir.remisa.SecretService",
+		"method":    "SetItemTimes",
+		"item":      itemPath,
+		"created":   created,
+		"modified":  modified,
+	}).Trace("Method called by client")
+
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return ApiErrorIsLocked()
+	}
+
+	item := service.GetItemByPath(itemPath)
+
+	if item == nil {
+		log.Warnf("Item doesn't exist: %v", itemPath)
+		return ApiErrorNoSuchObject()
+	}
+
+	if item.Parent.Locked && item.Parent.IsProtected() {
+		log.Warnf("Collection is locked with master password: %v", item.Parent.ObjectPath)
+		return ApiErrorIsLocked()
+	}
+
+	if created == 0 || modified < created {
+		log.Warnf("Invalid item times: created %d, modified %d", created, modified)
+		return DbusErrorInvalidArgs("'created' must be non-zero and not after 'modified'")
+	}
+
+	item.DataMutex.Lock()
+	item.Created = created
+	item.Modified = modified
+	item.DataMutex.Unlock()
+
+	item.DbusProperties.SetMust("org.freedesktop.Secret.Item", "Created", created)
+	item.DbusProperties.SetMust("org.freedesktop.Secret.Item", "Modified", modified)
+	item.SignalItemChanged()
+	item.SaveData()
+
+	return nil
+}
+
+/* <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< SetItemTimes <<<<<<<<<<<<<<<<<<<<<<<<<<<<<< */
@@ -2,8 +2,11 @@ package service_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/godbus/dbus/v5"
 	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/crypto"
 )
 
 ////////////////////////////// CreateSession //////////////////////////////
@@ -28,3 +31,151 @@ func Test_SecretServiceCommand(t *testing.T) {
 	})
 
 }
+
+////////////////////////////// ServiceLock //////////////////////////////
+
+// waitSignal waits for given signal or fails after a timeout
+func waitSignal(t *testing.T, signals chan *dbus.Signal, name string) {
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Name == name {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("Expected '%s' signal", name)
+			return
+		}
+	}
+}
+
+func Test_ServiceLock(t *testing.T) {
+
+	const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+	ssClient, _ := client.New()
+	session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+
+	collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+	lockedCollection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+	ssClient.Lock([]dbus.ObjectPath{lockedCollection.ObjectPath})
+
+	iv, cipherData, _ := crypto.AesCBCEncrypt([]byte("Victoria"), session.SymmetricKey)
+	secretApi := client.NewSecretApi()
+	secretApi.Session = session.ObjectPath
+	secretApi.Parameters = iv
+	secretApi.Value = cipherData
+	item, _, _ := collection.CreateItem(map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("service lock"),
+	}, secretApi, true)
+
+	connection, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatalf("Cannot connect to session bus. Error: %v", err)
+	}
+	defer connection.Close()
+
+	connection.AddMatchSignal(dbus.WithMatchInterface("ir.remisa.SecretService"))
+	signals := make(chan *dbus.Signal, 10)
+	connection.Signal(signals)
+
+	t.Run("no master password", func(t *testing.T) {
+
+		t.Setenv("MASTERPASSWORD", "")
+
+		if err := ssClient.ServiceLock(); err == nil {
+			t.Error("Expected locking service without master password to fail")
+		}
+
+		if Service.IsLocked() {
+			t.Error("Expected service to be unlocked")
+		}
+	})
+
+	t.Setenv("MASTERPASSWORD", masterPassword)
+
+	t.Run("lock", func(t *testing.T) {
+
+		if err := ssClient.ServiceLock(); err != nil {
+			t.Fatalf("ServiceLock failed. Error: %v", err)
+		}
+
+		waitSignal(t, signals, "ir.remisa.SecretService.ServiceLocked")
+
+		if !Service.IsLocked() {
+			t.Error("Expected service to be locked")
+		}
+
+		serviceItem := Service.GetItemByPath(item.ObjectPath)
+
+		if !serviceItem.Parent.Locked {
+			t.Error("Expected collection to be locked")
+		}
+
+		if serviceItem.Secret.PlainSecret != "" {
+			t.Error("Expected decrypted secret to be wiped")
+		}
+
+		if _, err := item.GetSecret(session.ObjectPath); err == nil {
+			t.Error("Expected GetSecret to be refused while service is locked")
+		}
+
+		if err := item.SetSecret(secretApi); err == nil {
+			t.Error("Expected SetSecret to be refused while service is locked")
+		}
+
+		if err := item.PropertySetLabel("changed while locked"); err == nil {
+			t.Error("Expected changing label to be refused while service is locked")
+		}
+
+		response, _ := ssClient.SecretServiceCommand("export database", "")
+
+		if response != "locked" && response != "verboten" {
+			t.Errorf("Expected database export to be refused, got: %s", response)
+		}
+	})
+
+	t.Run("wrong master password", func(t *testing.T) {
+
+		if err := ssClient.ServiceUnlock(session, "wrong"); err == nil {
+			t.Error("Expected ServiceUnlock with wrong password to fail")
+		}
+
+		if !Service.IsLocked() {
+			t.Error("Expected service to remain locked")
+		}
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+
+		if err := ssClient.ServiceUnlock(session, masterPassword); err != nil {
+			t.Fatalf("ServiceUnlock failed. Error: %v", err)
+		}
+
+		waitSignal(t, signals, "ir.remisa.SecretService.ServiceUnlocked")
+
+		if Service.IsLocked() {
+			t.Error("Expected service to be unlocked")
+		}
+
+		if Service.GetCollectionByPath(collection.ObjectPath).Locked {
+			t.Error("Expected collection to be unlocked")
+		}
+
+		if !Service.GetCollectionByPath(lockedCollection.ObjectPath).Locked {
+			t.Error("Expected collection locked before service lock to remain locked")
+		}
+
+		secret, err := item.GetSecret(session.ObjectPath)
+
+		if err != nil {
+			t.Fatalf("GetSecret failed. Error: %v", err)
+		}
+
+		plain, _ := crypto.AesCBCDecrypt(secret.Parameters, secret.Value, session.SymmetricKey)
+
+		if string(plain) != "Victoria" {
+			t.Errorf("Expected secret 'Victoria', got: '%s'", plain)
+		}
+	})
+}
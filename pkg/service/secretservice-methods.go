@@ -1,8 +1,38 @@
 package service
 
+import (
+	log "github.com/sirupsen/logrus"
+)
+
 func NewSecretService(parent *Service) *SecretService {
 	secretservice := &SecretService{}
 	secretservice.Session = &SecretServiceCLiSession{}
 	secretservice.Parent = parent
 	return secretservice
 }
+
+////////////////////////////// Signals //////////////////////////////
+
+/*
+	ServiceLocked;
+*/
+
+// SignalServiceLocked emits a signal that service is locked
+func (s *Service) SignalServiceLocked() {
+
+	s.Connection.Emit("/secretservice", "ir.remisa.SecretService.ServiceLocked")
+
+	log.Info("Emitted 'ServiceLocked' signal")
+}
+
+/*
+	ServiceUnlocked;
+*/
+
+// SignalServiceUnlocked emits a signal that service is unlocked
+func (s *Service) SignalServiceUnlocked() {
+
+	s.Connection.Emit("/secretservice", "ir.remisa.SecretService.ServiceUnlocked")
+
+	log.Info("Emitted 'ServiceUnlocked' signal")
+}
@@ -204,10 +204,9 @@ func (service *Service) CreateCollection(properties map[string]dbus.Variant,
 		collectionLabel := uuid[len(uuid)/2:] // use the last half of UUID
 		// Use org.freedesktop.Secret.Collection.Label if available
 		if collection.Label != "" {
-			// Make sure path doesn't exist
-			if service.GetCollectionByPath(
-				dbus.ObjectPath("/org/freedesktop/secrets/collection/"+collection.Label),
-			) == nil {
+			labelPath := dbus.ObjectPath("/org/freedesktop/secrets/collection/" + collection.Label)
+			// Make sure path is valid (i.e. no spaces) and doesn't exist
+			if labelPath.IsValid() && service.GetCollectionByPath(labelPath) == nil {
 				collectionLabel = collection.Label // use label in collection path (override uuid)
 			}
 		}
@@ -216,7 +215,10 @@ func (service *Service) CreateCollection(properties map[string]dbus.Variant,
 		collection.ObjectPath = dbus.ObjectPath(path)
 
 		epoch := Epoch()
-		service.AddCollection(collection, false, epoch, epoch, true)
+		if err := service.AddCollection(collection, false, epoch, epoch, true); err != nil {
+			log.Warn("A backup is being restored")
+			return "/", "/", dbusErrorRestoring()
+		}
 		collection.SignalCollectionCreated()
 
 		if collection.Alias == "" {
@@ -262,8 +264,14 @@ func (service *Service) SearchItems(
 	var lockedItems []dbus.ObjectPath
 	var unlockedItems []dbus.ObjectPath
 
+	// 'xdg:schema' scopes the search to items of that schema (Type)
+	schema, attributes := SplitSchema(attributes)
+
 	for _, collection := range service.Collections {
 		for _, item := range collection.Items {
+			if !item.MatchSchema(schema) {
+				continue
+			}
 			// Single or Full match? FullMatch works
 			if IsMapSubsetFullMatch(item.LookupAttributes,
 				attributes, collection.ItemsMutex) {
@@ -308,10 +316,23 @@ func (service *Service) Unlock(
 		"objects":   objects,
 	}).Trace("Method called by client")
 
+	if service.IsLocked() {
+		log.Warn("Service is locked, use 'ServiceUnlock' first")
+		return []dbus.ObjectPath{}, "/", ApiErrorIsLocked()
+	}
+
 	var unlockedObjects []dbus.ObjectPath
 
 	for _, object := range objects {
 		for _, collection := range service.Collections {
+			// password protected collections need their master password to get unlocked
+			if collection.IsProtected() {
+				if collection.ObjectPath == object {
+					log.Infof("Collection is password protected and cannot be unlocked without master password: %v",
+						collection.ObjectPath)
+				}
+				continue
+			}
 			if collection.ObjectPath == object {
 				if collection.Locked {
 					collection.Unlock()
@@ -421,6 +442,11 @@ func (service *Service) GetSecrets(items []dbus.ObjectPath,
 		"session":   session,
 	}).Trace("Method called by client")
 
+	if service.IsLocked() {
+		log.Warn("Service is locked")
+		return map[dbus.ObjectPath]SecretApi{}, ApiErrorIsLocked()
+	}
+
 	sessionInUse := service.GetSessionByPath(session)
 
 	if sessionInUse == nil {
@@ -431,6 +457,10 @@ func (service *Service) GetSecrets(items []dbus.ObjectPath,
 	result := make(map[dbus.ObjectPath]SecretApi)
 
 	for _, collection := range service.Collections {
+		// secrets of locked password protected collections are not accessible
+		if collection.Locked && collection.IsProtected() {
+			continue
+		}
 		for _, item := range collection.Items {
 			for _, itemPath := range items {
 				if item.ObjectPath == itemPath {
@@ -508,6 +538,24 @@ func (service *Service) SetAlias(name string, collection dbus.ObjectPath) *dbus.
 		return ApiErrorNotSupported()
 	}
 
+	// Documentation: If collection is '/' then the alias is removed
+	if collection == "/" {
+		if name == "default" {
+			log.Warn("Client tried to remove 'default' collection alias")
+			return ApiErrorNotSupported()
+		}
+		if c := service.GetCollectionByAlias(name); c != nil {
+			c.DataMutex.Lock()
+			c.Alias = ""
+			c.DataMutex.Unlock()
+			log.Infof("Removed alias '%v' from collection: %v", name, c.ObjectPath)
+			c.UpdateModified()
+			c.SignalCollectionChanged()
+			service.SaveData()
+		}
+		return nil
+	}
+
 	if c := service.GetCollectionByPath(collection); c != nil {
 		if name == "/" {
 			c.DataMutex.Lock()
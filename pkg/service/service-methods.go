@@ -4,13 +4,19 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
+	"github.com/yousefvand/secret-service/pkg/crypto"
 	"gopkg.in/yaml.v2"
 )
 
@@ -19,7 +25,11 @@ func New() *Service {
 	service := new(Service)
 	service.Connection = nil
 	service.Config = &ServiceConfig{}
-	// service.LockMutex = new(sync.Mutex) // TODO: Remove
+	service.Config.SchemasMutex = new(sync.RWMutex)
+	service.Config.Schemas = make(map[string]*Schema)
+	service.ConfigMutex = new(sync.RWMutex)
+	service.LockMutex = new(sync.RWMutex)
+	service.SaveMutex = new(sync.RWMutex)
 	service.SessionsMutex = new(sync.RWMutex)
 	service.CollectionsMutex = new(sync.RWMutex)
 	service.Sessions = make(map[string]*Session)
@@ -31,6 +41,9 @@ func New() *Service {
 	service.SecretService = &SecretService{}
 	service.SecretService.Session = &SecretServiceCLiSession{}
 	service.SecretService.Parent = service
+	service.Portal = NewPortal(service)
+	service.GnomeKeyring = NewGnomeKeyring(service)
+	service.KWallet = NewKWallet(service)
 	// service.SecretService.Session = &SecretServiceCLiSession{}
 
 	// service.Update callback is set by the user (App)
@@ -65,11 +78,20 @@ func (s *Service) disconnect() {
 // start secretserviced
 func (service *Service) Start(ctx context.Context) {
 
+	service.Started = time.Now()
 	log.Info("===== Secret Service Started =====")
 	log.Info("Secret service dbus address: /org/freedesktop/secrets")
 	log.Debugf("Using total of %v MiB of OS memory", MemUsageOS())
 	log.Debugf("Service is using %d Goroutines", runtime.NumGoroutine())
 
+	// offline tools must not change database while daemon runs
+	dbLock, err := LockDatabase(service.Config.Home)
+	if err != nil {
+		log.Errorf("Cannot lock database at '%s'. Error: %v. Exiting...", service.Config.Home, err)
+		os.Exit(6)
+	}
+	defer dbLock.Close()
+
 	if service.Connection == nil {
 		service.connect()
 	}
@@ -86,6 +108,19 @@ func (service *Service) Start(ctx context.Context) {
 	go RestoreData(service)
 	<-service.DbLoadedChan
 	go PersistData(ctx, service)
+	go ScheduleBackups(ctx, service)
+
+	// create Flatpak secret portal backend on dbus path: '/org/freedesktop/portal/desktop'
+	// after database is loaded so per-application secrets are already restored
+	settings := service.Settings()
+	if settings.Portal {
+		dbusPortal(service)
+	}
+
+	// own 'org.kde.kwalletd5' and 'org.kde.kwalletd6' and create KWallet interface
+	if settings.KWallet {
+		dbusKWallet(service)
+	}
 
 	close(service.ServiceReadyChan) // propagate a signal that means service is ready
 
@@ -95,19 +130,295 @@ func (service *Service) Start(ctx context.Context) {
 	close(service.ServiceShutdownChan)
 }
 
-// locks the service
-// func (s *Service) ServiceLock() {
-// 	s.LockMutex.Lock()
-// 	s.Locked = true
-// 	s.LockMutex.Unlock()
-// }
+// Settings returns a copy of service configurations which is safe to
+// read while configurations are reloaded
+func (service *Service) Settings() ServiceConfig {
+	service.ConfigMutex.RLock()
+	defer service.ConfigMutex.RUnlock()
+	service.Config.SchemasMutex.RLock()
+	defer service.Config.SchemasMutex.RUnlock()
+	return *service.Config
+}
+
+// ApplySettings replaces settings of service configurations (all but home
+// directory and schemas) with the ones of given configurations at once
+func (service *Service) ApplySettings(config ServiceConfig) {
+	service.ConfigMutex.Lock()
+	defer service.ConfigMutex.Unlock()
+	service.Config.EncryptDatabase = config.EncryptDatabase
+	service.Config.AllowDbExport = config.AllowDbExport
+	service.Config.Portal = config.Portal
+	service.Config.KWallet = config.KWallet
+	service.Config.BackupDirectory = config.BackupDirectory
+	service.Config.BackupInterval = config.BackupInterval
+	service.Config.BackupRetention = config.BackupRetention
+}
+
+// GetStatus returns daemon status and statistics
+func (service *Service) GetStatus() Status {
+
+	settings := service.Settings()
+	status := Status{
+		Version:       Version,
+		AllowDbExport: settings.AllowDbExport,
+		Portal:        settings.Portal,
+		KWallet:       settings.KWallet,
+		Locked:        service.IsLocked(),
+		DbPath:        filepath.Join(service.Config.Home, "db.json"),
+		Memory:        MemUsageOS(),
+		Goroutines:    uint32(runtime.NumGoroutine()),
+		Encryption:    "none",
+	}
+
+	if !service.Started.IsZero() {
+		status.Started = uint64(service.Started.Unix())
+		status.Uptime = uint64(time.Since(service.Started).Seconds())
+	}
+
+	if settings.EncryptDatabase {
+		status.Encryption = "AES-CBC-256"
+	}
+
+	service.Config.SchemasMutex.RLock()
+	status.Schemas = uint32(len(service.Config.Schemas))
+	service.Config.SchemasMutex.RUnlock()
+
+	if info, err := os.Stat(status.DbPath); err == nil {
+		status.DbSize = uint64(info.Size())
+	}
+
+	masterPassword := os.Getenv("MASTERPASSWORD")
+
+	switch {
+	case masterPassword == "":
+		status.MasterPassword = "missing"
+	case len(masterPassword) != 32:
+		status.MasterPassword = "invalid"
+	default:
+		status.MasterPassword = "valid"
+	}
+
+	if err := CheckDatabase(status.DbPath, masterPassword); err != nil {
+		status.DbError = err.Error()
+	}
+
+	service.CollectionsMutex.RLock()
+	status.Collections = uint32(len(service.Collections))
+	for _, collection := range service.Collections {
+		collection.ItemsMutex.RLock()
+		status.Items += uint32(len(collection.Items))
+		collection.ItemsMutex.RUnlock()
+	}
+	service.CollectionsMutex.RUnlock()
+
+	service.SessionsMutex.RLock()
+	status.Sessions = uint32(len(service.Sessions))
+	service.SessionsMutex.RUnlock()
+
+	service.SaveMutex.RLock()
+	if !service.LastSave.IsZero() {
+		status.LastSave = uint64(service.LastSave.Unix())
+	}
+	status.LastSaveError = service.LastSaveError
+	service.SaveMutex.RUnlock()
+
+	return status
+}
+
+// setSaveStatus records result of a database save
+func (service *Service) setSaveStatus(err error) {
+
+	service.SaveMutex.Lock()
+	defer service.SaveMutex.Unlock()
+
+	if err != nil {
+		service.LastSaveError = err.Error()
+		return
+	}
+
+	service.LastSave = time.Now()
+	service.LastSaveError = ""
+}
+
+// IsLocked returns true if service is locked otherwise false
+func (s *Service) IsLocked() bool {
+	s.LockMutex.RLock()
+	defer s.LockMutex.RUnlock()
+	return s.Locked
+}
+
+// rLockUnlocked read locks service lock state and returns true if service
+// is unlocked, caller releases it ('LockMutex.RUnlock') after writing so
+// service cannot be locked meanwhile. Nothing is held if service is locked.
+func (s *Service) rLockUnlocked() bool {
+	s.LockMutex.RLock()
+	if s.Locked {
+		s.LockMutex.RUnlock()
+		return false
+	}
+	return true
+}
+
+// LockService locks all collections, seals all secrets with master
+// password and wipes decrypted secrets from memory
+func (s *Service) LockService() error {
+
+	masterPassword := MasterPassword()
+
+	if masterPassword == "" {
+		return errors.New("locking service needs a 32 character MASTERPASSWORD")
+	}
+
+	s.LockMutex.Lock()
+	defer s.LockMutex.Unlock()
+
+	if s.Locked {
+		return nil
+	}
+
+	s.CollectionsMutex.RLock()
+	defer s.CollectionsMutex.RUnlock()
+
+	// seal all secrets first so a failure leaves every secret in place
+	type sealedItem struct {
+		item   *Item
+		plain  string
+		sealed string
+	}
 
-// unlocks the service
-// func (s *Service) ServiceUnlock() {
-// 	s.LockMutex.Lock()
-// 	s.Locked = false
-// 	s.LockMutex.Unlock()
-// }
+	var sealedItems []sealedItem
+
+	for _, collection := range s.Collections {
+
+		collection.ItemsMutex.RLock()
+		for _, item := range collection.Items {
+			item.DataMutex.RLock()
+			plain := item.Secret.PlainSecret
+			item.DataMutex.RUnlock()
+			sealed, err := crypto.EncryptAESCBC256(masterPassword, plain)
+			if err != nil {
+				collection.ItemsMutex.RUnlock()
+				return fmt.Errorf("cannot seal secret of item '%v'. Error: %v", item.ObjectPath, err)
+			}
+			sealedItems = append(sealedItems, sealedItem{item: item, plain: plain, sealed: sealed})
+		}
+		collection.ItemsMutex.RUnlock()
+	}
+
+	for index, sealedItem := range sealedItems {
+		item := sealedItem.item
+		item.DataMutex.Lock()
+		// secret changed after it was sealed
+		if item.Secret.PlainSecret != sealedItem.plain {
+			sealed, err := crypto.EncryptAESCBC256(masterPassword, item.Secret.PlainSecret)
+			if err != nil {
+				item.DataMutex.Unlock()
+				for _, committed := range sealedItems[:index] {
+					committed.item.DataMutex.Lock()
+					committed.item.Secret.PlainSecret = committed.plain
+					committed.item.Secret.SealedSecret = ""
+					committed.item.DataMutex.Unlock()
+				}
+				return fmt.Errorf("cannot seal secret of item '%v'. Error: %v", item.ObjectPath, err)
+			}
+			sealedItems[index].plain = item.Secret.PlainSecret
+			sealedItem.sealed = sealed
+		}
+		item.Secret.SealedSecret = sealedItem.sealed
+		item.Secret.PlainSecret = ""
+		item.DataMutex.Unlock()
+	}
+
+	s.serviceLockedCollections = []dbus.ObjectPath{}
+
+	for _, collection := range s.Collections {
+		if !collection.Locked {
+			collection.Lock()
+			collection.SignalCollectionChanged()
+			s.serviceLockedCollections = append(s.serviceLockedCollections, collection.ObjectPath)
+		}
+	}
+
+	s.Locked = true
+	s.SignalServiceLocked()
+	log.Info("Service locked")
+
+	return nil
+}
+
+// UnlockService unseals all secrets and unlocks collections locked by
+// LockService if given password is the master password
+func (s *Service) UnlockService(password string) error {
+
+	masterPassword := MasterPassword()
+
+	if masterPassword == "" ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(masterPassword)) != 1 {
+		return errors.New("wrong master password")
+	}
+
+	s.LockMutex.Lock()
+
+	if !s.Locked {
+		s.LockMutex.Unlock()
+		return nil
+	}
+
+	// unseal all secrets first so a failure leaves service locked
+	type unsealedItem struct {
+		item  *Item
+		plain string
+	}
+
+	var unsealedItems []unsealedItem
+
+	s.CollectionsMutex.RLock()
+	for _, collection := range s.Collections {
+		collection.ItemsMutex.RLock()
+		for _, item := range collection.Items {
+			item.DataMutex.RLock()
+			sealed := item.Secret.SealedSecret
+			item.DataMutex.RUnlock()
+			if sealed == "" {
+				continue
+			}
+			plain, err := crypto.DecryptAESCBC256(masterPassword, sealed)
+			if err != nil {
+				collection.ItemsMutex.RUnlock()
+				s.CollectionsMutex.RUnlock()
+				s.LockMutex.Unlock()
+				return fmt.Errorf("cannot unseal secret of item '%v'. Error: %v", item.ObjectPath, err)
+			}
+			unsealedItems = append(unsealedItems, unsealedItem{item: item, plain: plain})
+		}
+		collection.ItemsMutex.RUnlock()
+	}
+	s.CollectionsMutex.RUnlock()
+
+	for _, unsealedItem := range unsealedItems {
+		unsealedItem.item.DataMutex.Lock()
+		unsealedItem.item.Secret.PlainSecret = unsealedItem.plain
+		unsealedItem.item.Secret.SealedSecret = ""
+		unsealedItem.item.DataMutex.Unlock()
+	}
+
+	for _, collectionPath := range s.serviceLockedCollections {
+		if collection := s.GetCollectionByPath(collectionPath); collection != nil {
+			collection.Unlock()
+			collection.SignalCollectionChanged()
+		}
+	}
+
+	s.serviceLockedCollections = nil
+	s.Locked = false
+	s.LockMutex.Unlock()
+
+	s.SignalServiceUnlocked()
+	log.Info("Service unlocked")
+	s.SaveData() // changes made while service was locked
+
+	return nil
+}
 
 // add a new session to service's session map
 func (s *Service) AddSession(session *Session) {
@@ -158,11 +469,16 @@ func (service *Service) GetSessionByPath(sessionPath dbus.ObjectPath) *Session {
 	return nil
 }
 
-// add a new collection to service's collection map
+// add a new collection to service's collection map, restoring a backup is
+// checked under the lock which guards swapping collections
 func (s *Service) AddCollection(collection *Collection,
-	locked bool, created uint64, modified uint64, saveData bool) {
+	locked bool, created uint64, modified uint64, saveData bool) error {
 
 	s.CollectionsMutex.Lock()
+	if s.isRestoring() {
+		s.CollectionsMutex.Unlock()
+		return errRestoring
+	}
 	s.Collections[string(collection.ObjectPath)] = collection
 	s.CollectionsMutex.Unlock()
 	dbusAddCollection(collection, locked, created, modified)
@@ -171,22 +487,32 @@ func (s *Service) AddCollection(collection *Collection,
 	if collection.Alias != "default" && saveData {
 		s.SaveData()
 	}
+
+	return nil
 }
 
-// remove a collection from service's collection map
-func (s *Service) RemoveCollection(collection *Collection) {
+// remove a collection from service's collection map, restoring a backup is
+// checked under the lock which guards swapping collections
+func (s *Service) RemoveCollection(collection *Collection) error {
 	s.CollectionsMutex.Lock()
+	if s.isRestoring() {
+		s.CollectionsMutex.Unlock()
+		return errRestoring
+	}
 	_, ok := s.Collections[string(collection.ObjectPath)]
 	if !ok {
+		s.CollectionsMutex.Unlock()
 		log.Errorf("Collection doesn't exist to be removed: %v",
 			collection.ObjectPath)
-		return
+		return nil
 	}
 	delete(s.Collections, string(collection.ObjectPath))
 	s.CollectionsMutex.Unlock()
 	dbusUpdateCollections(s)
 	log.Infof("Collection removed: %v", collection.ObjectPath)
 	s.SaveData()
+
+	return nil
 }
 
 // HasCollection returns true if collection exists otherwise false
@@ -3,9 +3,17 @@ package service_test
 import (
 	"crypto/sha512"
 	"encoding/hex"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/yousefvand/secret-service/pkg/client"
+	"github.com/yousefvand/secret-service/pkg/crypto"
+	"github.com/yousefvand/secret-service/pkg/service"
 )
 
 func Test_ReadPasswordFile(t *testing.T) {
@@ -92,3 +100,136 @@ version: 0.1.0
 # Password hash: sha512(salt+password)
 passwordHash: ''
 `)
+
+func Test_LockServiceWhileSaving(t *testing.T) {
+
+	const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+	t.Setenv("MASTERPASSWORD", masterPassword)
+
+	ssClient, _ := client.New()
+	session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+	collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+	iv, cipherData, _ := crypto.AesCBCEncrypt([]byte("Victoria"), session.SymmetricKey)
+	secretApi := client.NewSecretApi()
+	secretApi.Session = session.ObjectPath
+	secretApi.Parameters = iv
+	secretApi.Value = cipherData
+	item, _, _ := collection.CreateItem(map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("lock while saving"),
+	}, secretApi, true)
+
+	home, _ := ioutil.TempDir("", "secret-service")
+	defer os.RemoveAll(home)
+
+	var wait sync.WaitGroup
+	wait.Add(2)
+
+	go func() {
+		defer wait.Done()
+		for i := 0; i < 20; i++ {
+			if err := Service.LockService(); err != nil {
+				t.Errorf("LockService failed. Error: %v", err)
+			}
+			if err := Service.UnlockService(masterPassword); err != nil {
+				t.Errorf("UnlockService failed. Error: %v", err)
+			}
+		}
+	}()
+
+	go func() {
+		defer wait.Done()
+		for i := 0; i < 20; i++ {
+
+			dbFile := filepath.Join(home, fmt.Sprintf("db-%d.json", i))
+
+			// saving is refused while service is locked
+			if err := service.Marshal(Service, dbFile); err != nil {
+				continue
+			}
+
+			db, err := service.ReadDatabase(dbFile)
+
+			if err != nil {
+				t.Errorf("ReadDatabase failed. Error: %v", err)
+				return
+			}
+
+			if db.Encrypted {
+				if err := db.DecryptSecrets(masterPassword); err != nil {
+					t.Errorf("DecryptSecrets failed. Error: %v", err)
+					return
+				}
+			}
+
+			for _, dbCollection := range db.Collections {
+				for _, dbItem := range dbCollection.Items {
+					if dbItem.ObjectPath == item.ObjectPath && dbItem.Secret.SecretText != "Victoria" {
+						t.Errorf("Expected saved secret 'Victoria', got: '%s'", dbItem.Secret.SecretText)
+					}
+				}
+			}
+		}
+	}()
+
+	wait.Wait()
+
+	if Service.IsLocked() {
+		t.Error("Expected service to be unlocked")
+	}
+
+	if secret := Service.GetItemByPath(item.ObjectPath).Secret.PlainSecret; secret != "Victoria" {
+		t.Errorf("Expected secret 'Victoria' after lock and unlock, got: '%s'", secret)
+	}
+}
+
+func Test_UnlockServiceFailure(t *testing.T) {
+
+	const masterPassword = "abcdefghijklmnopqrstuvwxyz012345"
+
+	t.Setenv("MASTERPASSWORD", masterPassword)
+
+	ssClient, _ := client.New()
+	session, _ := ssClient.OpenSession(client.Dh_ietf1024_sha256_aes128_cbc_pkcs7)
+	collection, _, _ := ssClient.CreateCollection(map[string]dbus.Variant{}, "")
+
+	iv, cipherData, _ := crypto.AesCBCEncrypt([]byte("Victoria"), session.SymmetricKey)
+	secretApi := client.NewSecretApi()
+	secretApi.Session = session.ObjectPath
+	secretApi.Parameters = iv
+	secretApi.Value = cipherData
+	item, _, _ := collection.CreateItem(map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label": dbus.MakeVariant("unlock failure"),
+	}, secretApi, true)
+
+	if err := Service.LockService(); err != nil {
+		t.Fatalf("LockService failed. Error: %v", err)
+	}
+
+	serviceItem := Service.GetItemByPath(item.ObjectPath)
+	sealed := serviceItem.Secret.SealedSecret
+	serviceItem.Secret.SealedSecret = "corrupted"
+
+	if err := Service.UnlockService(masterPassword); err == nil {
+		t.Error("Expected unlocking with a corrupted sealed secret to fail")
+	}
+
+	if !Service.IsLocked() {
+		t.Error("Expected service to remain locked")
+	}
+
+	if serviceItem.Secret.SealedSecret != "corrupted" || serviceItem.Secret.PlainSecret != "" {
+		t.Error("Expected sealed secret to be untouched")
+	}
+
+	serviceItem.Secret.SealedSecret = sealed
+
+	if err := Service.UnlockService(masterPassword); err != nil {
+		t.Fatalf("UnlockService failed. Error: %v", err)
+	}
+
+	if serviceItem.Secret.PlainSecret != "Victoria" {
+		t.Errorf("Expected secret 'Victoria', got: '%s'", serviceItem.Secret.PlainSecret)
+	}
+}
@@ -2,6 +2,10 @@
 // http://standards.freedesktop.org/secret-service
 package service
 
+import (
+	"github.com/yousefvand/secret-service/pkg/crypto"
+)
+
 // create and initialize a new session
 func NewSession(parent *Service) *Session {
 	session := &Session{}
@@ -22,3 +26,11 @@ func (s *Session) CreateMethodFromPath(method string) string {
 	_, child := Path2Name(string(s.ObjectPath), method)
 	return child
 }
+
+// Decrypt returns plain secret of a secret transferred over this session
+func (s *Session) Decrypt(secretApi *SecretApi) ([]byte, error) {
+	if s.EncryptionAlgorithm == Plain {
+		return secretApi.Value, nil
+	}
+	return crypto.AesCBCDecrypt(secretApi.Parameters, secretApi.Value, s.SymmetricKey)
+}
@@ -34,6 +34,8 @@ func TestMain(m *testing.M) {
 
 	Service = service.New()
 	Service.Config.Home, _ = ioutil.TempDir("", "secret-service")
+	Service.Config.Portal = true
+	Service.Config.KWallet = true
 	ctx, cancel := context.WithCancel(context.Background())
 	go Service.Start(ctx) // start secret service
 
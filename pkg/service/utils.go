@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"reflect"
 	"runtime"
@@ -162,3 +163,20 @@ func CommandExists(cmdName string) bool {
 func Epoch() uint64 {
 	return uint64(time.Now().Unix())
 }
+
+// clearMap removes all keys of given map
+func clearMap(m map[string]string) {
+	for k := range m {
+		delete(m, k)
+	}
+}
+
+// MasterPassword returns MASTERPASSWORD environment variable
+// if it is exactly 32 characters, otherwise empty string
+func MasterPassword() string {
+	masterPassword := os.Getenv("MASTERPASSWORD")
+	if len(masterPassword) != 32 {
+		return ""
+	}
+	return masterPassword
+}
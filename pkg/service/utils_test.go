@@ -3,6 +3,7 @@ package service_test
 import (
 	"reflect"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 
@@ -482,3 +483,32 @@ func TestEncryptionAndDecryption(t *testing.T) {
 		})
 	}
 }
+
+func TestHashPassword(t *testing.T) {
+
+	hash, err := crypto.HashPassword("Victoria")
+
+	if err != nil {
+		t.Fatalf("Hashing password failed. Error: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		t.Errorf("Expected argon2id hash, got: %s", hash)
+	}
+
+	ok, err := crypto.VerifyPassword("Victoria", hash)
+
+	if err != nil || !ok {
+		t.Errorf("Expected correct password to be verified. Error: %v", err)
+	}
+
+	ok, err = crypto.VerifyPassword("Victoria2", hash)
+
+	if err != nil || ok {
+		t.Errorf("Expected wrong password to be rejected. Error: %v", err)
+	}
+
+	if _, err := crypto.VerifyPassword("Victoria", "malformed"); err == nil {
+		t.Error("Expected error for malformed hash")
+	}
+}
@@ -1,10 +1,17 @@
 #!/usr/bin/env bash
 
 # Run by: './scripts/build-binaries.sh' from project root
+
+# version of binaries comes from 'VERSION' file
+ldflags="-X github.com/yousefvand/secret-service/pkg/service.Version=$(cat VERSION)"
+
 echo "$(tput setaf 3)""building secretserviced ...""$(tput sgr0)"
-go build -race -o secretserviced cmd/app/secretserviced/main.go
+go build -race -ldflags "${ldflags}" -o secretserviced cmd/app/secretserviced/main.go
 
 echo "$(tput setaf 3)""building secretservice ...""$(tput sgr0)"
-go build -race -o secretservice cmd/app/secretservice/main.go
+go build -race -ldflags "${ldflags}" -o secretservice cmd/app/secretservice/main.go
+
+echo "$(tput setaf 3)""building docker-credential-secretservice ...""$(tput sgr0)"
+go build -race -ldflags "${ldflags}" -o docker-credential-secretservice cmd/app/docker-credential-secretservice/main.go
 
-du -bh secretservice*
\ No newline at end of file
+du -bh secretservice* docker-credential-secretservice
\ No newline at end of file
@@ -9,16 +9,21 @@ Version=1.0.0
 function install () {
 
   echo "Building binaries..."
-  go build -race -o secretserviced cmd/app/secretserviced/main.go
-  go build -race -o secretservice cmd/app/secretservice/main.go
+  # version of binaries comes from 'VERSION' file
+  ldflags="-X github.com/yousefvand/secret-service/pkg/service.Version=$(cat VERSION)"
+  go build -race -ldflags "${ldflags}" -o secretserviced cmd/app/secretserviced/main.go
+  go build -race -ldflags "${ldflags}" -o secretservice cmd/app/secretservice/main.go
+  go build -race -ldflags "${ldflags}" -o docker-credential-secretservice cmd/app/docker-credential-secretservice/main.go
   echo "Copying binaries to /usr/bin"
   # Alternatively: ~/.local/bin
   sudo cp secretserviced /usr/bin
   sudo cp secretservice /usr/bin
+  sudo cp docker-credential-secretservice /usr/bin
   echo "Creating systemd UNIT file at /etc/systemd/user"
   # Alternatively: ~/.config/systemd/user/
   rm secretserviced
   rm secretservice
+  rm docker-credential-secretservice
 
   echo
 
@@ -69,6 +74,7 @@ function uninstall () {
   echo "deleting binaries"
   sudo rm /usr/bin/secretserviced
   sudo rm /usr/bin/secretservice
+  sudo rm /usr/bin/docker-credential-secretservice
 
   if [ -f "/etc/systemd/user/secretserviced.service" ]; then
     read -rep "systemd UNIT file exist at '/etc/systemd/user/secretserviced.service'.
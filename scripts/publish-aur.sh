@@ -2,8 +2,8 @@
 
 # Run by: './scripts/publish-aur.sh' from project root
 
-# set version here
-version="v0.2.3"
+# set version in 'VERSION' file
+version="v$(cat VERSION)"
 
 tempAURDirectory="tmp-aur"
 